@@ -11,8 +11,11 @@ require (
 	github.com/stretchr/testify v1.6.1
 	github.com/urfave/cli v1.22.2
 	k8s.io/api v0.21.2
+	k8s.io/apiextensions-apiserver v0.18.0
 	k8s.io/apimachinery v0.21.2
 	k8s.io/client-go v0.21.2
 	k8s.io/klog v1.0.0
 	k8s.io/utils v0.0.0-20201110183641-67b214c5f920
+	sigs.k8s.io/structured-merge-diff/v4 v4.1.0
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,22 @@
+#!/bin/bash
+# Generates typed apply configurations for the helm.cattle.io types, for use with server-side apply.
+# The generator is not part of wrangler's codegen, so it is run separately after go generate has
+# produced the rest of pkg/generated.
+set -e
+
+cd $(dirname $0)/..
+
+APPLYCONFIGURATION_GEN=${APPLYCONFIGURATION_GEN:-"go run k8s.io/code-generator/cmd/applyconfiguration-gen@v0.26.1"}
+MODULE=github.com/k3s-io/helm-controller
+OUTPUT_BASE=$(mktemp -d)
+trap "rm -rf ${OUTPUT_BASE}" EXIT
+
+${APPLYCONFIGURATION_GEN} \
+  --input-dirs ${MODULE}/pkg/apis/helm.cattle.io/v1 \
+  --output-package ${MODULE}/pkg/generated/applyconfiguration \
+  --output-base ${OUTPUT_BASE} \
+  --external-applyconfigurations k8s.io/api/core/v1.LocalObjectReference:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.HostAlias:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.ConfigMapKeySelector:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.SecretKeySelector:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.EnvFromSource:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.Toleration:k8s.io/client-go/applyconfigurations/core/v1,k8s.io/api/core/v1.EnvVar:k8s.io/client-go/applyconfigurations/core/v1 \
+  --go-header-file hack/boilerplate.go.txt
+
+rm -rf pkg/generated/applyconfiguration
+cp -r ${OUTPUT_BASE}/${MODULE}/pkg/generated/applyconfiguration pkg/generated/applyconfiguration
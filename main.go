@@ -1,23 +1,28 @@
 //go:generate go run pkg/codegen/cleanup/main.go
 //go:generate /bin/rm -rf pkg/generated
 //go:generate go run pkg/codegen/main.go
+//go:generate ./hack/update-applyconfigurations.sh
 
 package main
 
 import (
 	"context"
 	"os"
+	"strconv"
 
-	helmv1 "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io"
+	"github.com/k3s-io/helm-controller/pkg/dashboard"
 	helmcontroller "github.com/k3s-io/helm-controller/pkg/helm"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/k3s-io/helm-controller/pkg/metrics"
+	"github.com/k3s-io/helm-controller/pkg/webhook"
 	"github.com/rancher/wrangler/pkg/apply"
-	batchv1 "github.com/rancher/wrangler/pkg/generated/controllers/batch"
-	corev1 "github.com/rancher/wrangler/pkg/generated/controllers/core"
-	rbacv1 "github.com/rancher/wrangler/pkg/generated/controllers/rbac"
+	"github.com/rancher/wrangler/pkg/kv"
 	"github.com/rancher/wrangler/pkg/signals"
-	"github.com/rancher/wrangler/pkg/start"
 	"github.com/urfave/cli"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
@@ -57,6 +62,285 @@ func main() {
 			Value:  2,
 			Usage:  "Threadiness level to set, defaults to 2.",
 		},
+		cli.StringSliceFlag{
+			Name:   "common-labels",
+			EnvVar: "COMMON_LABELS",
+			Usage:  "Labels to add to all objects generated for charts, in key=value format.",
+		},
+		cli.StringSliceFlag{
+			Name:   "release-secret-labels",
+			EnvVar: "RELEASE_SECRET_LABELS",
+			Usage:  "Labels to add to the Secrets that helm stores releases in, in key=value format, for example to include release history in backups.",
+		},
+		cli.StringSliceFlag{
+			Name:   "release-labels",
+			EnvVar: "RELEASE_LABELS",
+			Usage:  "Labels to add to every resource installed by charts, in key=value format, for example to group them under an app in the Rancher UI, unless overridden by the chart. Runs every chart through the kubectl kustomize post-renderer, which requires kubectl 1.22 or later in the job image, and re-runs the jobs of all charts when set or changed.",
+		},
+		cli.StringSliceFlag{
+			Name:   "release-annotations",
+			EnvVar: "RELEASE_ANNOTATIONS",
+			Usage:  "Annotations to add to every resource installed by charts, in key=value format, unless overridden by the chart. Runs every chart through the kubectl kustomize post-renderer, which requires kubectl 1.22 or later in the job image, and re-runs the jobs of all charts when set or changed.",
+		},
+		cli.BoolFlag{
+			Name:   "disable-sidecars",
+			EnvVar: "DISABLE_SIDECARS",
+			Usage:  "Annotate job pods to prevent service mesh sidecar injection, unless overridden by the chart.",
+		},
+		cli.StringFlag{
+			Name:   "job-safe-to-evict",
+			EnvVar: "JOB_SAFE_TO_EVICT",
+			Usage:  "Set the cluster-autoscaler.kubernetes.io/safe-to-evict annotation on job pods to true or false, unless overridden by the chart. If empty, the annotation is not set.",
+		},
+		cli.BoolFlag{
+			Name:   "upgrade-job-image",
+			EnvVar: "UPGRADE_JOB_IMAGE",
+			Usage:  "Re-run charts that do not set their own job image when the default job image changes, such as when the controller is upgraded. By default, charts keep the image of their existing job until they are changed.",
+		},
+		cli.BoolFlag{
+			Name:   "disable-job-security-context",
+			EnvVar: "DISABLE_JOB_SECURITY_CONTEXT",
+			Usage:  "Run jobs as the image's own user, with a writable root filesystem and default capabilities, instead of as an unprivileged user with a read-only root filesystem, unless overridden by the chart. Needed for job images that must run as root.",
+		},
+		cli.StringSliceFlag{
+			Name:   "repo-mirror",
+			EnvVar: "REPO_MIRRORS",
+			Usage:  "Rewrite chart repo URLs starting with an upstream prefix to a mirror, in upstream=mirror format.",
+		},
+		cli.StringFlag{
+			Name:   "repo-mirror-auth-secret",
+			EnvVar: "REPO_MIRROR_AUTH_SECRET",
+			Usage:  "Name of a Secret in each chart's namespace with credentials for the repo mirror, used for mirrored charts that do not set an authSecret.",
+		},
+		cli.StringFlag{
+			Name:   "job-name-template",
+			EnvVar: "JOB_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.Job,
+			Usage:  "Template for the names of chart jobs, using the chart's {{.Namespace}} and {{.Name}}, and the job's {{.Action}}.",
+		},
+		cli.StringFlag{
+			Name:   "service-account-name-template",
+			EnvVar: "SERVICE_ACCOUNT_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.ServiceAccount,
+			Usage:  "Template for the names of chart job ServiceAccounts, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "cluster-role-binding-name-template",
+			EnvVar: "CLUSTER_ROLE_BINDING_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.ClusterRoleBinding,
+			Usage:  "Template for the names of chart job ClusterRoleBindings, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "values-config-map-name-template",
+			EnvVar: "VALUES_CONFIG_MAP_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.ValuesConfigMap,
+			Usage:  "Template for the names of chart values ConfigMaps, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "content-config-map-name-template",
+			EnvVar: "CONTENT_CONFIG_MAP_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.ContentConfigMap,
+			Usage:  "Template for the names of chart content ConfigMaps, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "provenance-config-map-name-template",
+			EnvVar: "PROVENANCE_CONFIG_MAP_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.ProvenanceConfigMap,
+			Usage:  "Template for the names of chart provenance ConfigMaps, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "network-policy-name-template",
+			EnvVar: "NETWORK_POLICY_NAME_TEMPLATE",
+			Value:  render.DefaultNameTemplates.NetworkPolicy,
+			Usage:  "Template for the names of chart job NetworkPolicies, using the chart's {{.Namespace}} and {{.Name}}.",
+		},
+		cli.StringFlag{
+			Name:   "ca-bundle-config-map",
+			EnvVar: "CA_BUNDLE_CONFIG_MAP",
+			Usage:  "Name of a ConfigMap in each chart's namespace holding a CA bundle that replaces the job image's CA bundle, for charts that do not set a caBundle.",
+		},
+		cli.StringFlag{
+			Name:   "ca-bundle-config-map-key",
+			EnvVar: "CA_BUNDLE_CONFIG_MAP_KEY",
+			Value:  render.DefaultCABundleKey,
+			Usage:  "Key of the CA bundle ConfigMap that holds the CA bundle.",
+		},
+		cli.StringFlag{
+			Name:   "terminating-namespace-policy",
+			EnvVar: "TERMINATING_NAMESPACE_POLICY",
+			Value:  helmcontroller.TerminatingNamespacePolicyWait,
+			Usage:  "Whether charts in terminating namespaces wait for their delete job (wait), or are removed without uninstalling their release (skip) so that the namespace can be deleted.",
+		},
+		cli.BoolFlag{
+			Name:   "tolerate-unschedulable",
+			EnvVar: "TOLERATE_UNSCHEDULABLE",
+			Usage:  "Allow jobs to run on cordoned nodes, so that charts can still be reconciled while the only node in a cluster is cordoned for an upgrade.",
+		},
+		cli.DurationFlag{
+			Name:   "max-timeout",
+			EnvVar: "MAX_TIMEOUT",
+			Usage:  "Longest timeout that charts may set, so that charts cannot effectively disable failure handling. Zero means unlimited.",
+		},
+		cli.Float64Flag{
+			Name:   "slow-job-factor",
+			EnvVar: "SLOW_JOB_FACTOR",
+			Value:  helmcontroller.SlowJobFactor,
+			Usage:  "Multiple of a chart's timeout that its job may run for before the chart's Progressing condition is set to False with reason Slow, unless the chart sets spec.expectedDuration. Zero disables slow job detection.",
+		},
+		cli.DurationFlag{
+			Name:   "drift-grace-period",
+			EnvVar: "DRIFT_GRACE_PERIOD",
+			Value:  helmcontroller.DriftGracePeriod,
+			Usage:  "How long the desired configuration of a chart may differ from its last successfully installed configuration before the chart's Drifted condition is set and the helm_controller_chart_config_drift metric is raised. Zero disables drift detection.",
+		},
+		cli.IntFlag{
+			Name:   "transient-error-budget",
+			EnvVar: "TRANSIENT_ERROR_BUDGET",
+			Value:  helmcontroller.TransientErrorBudget,
+			Usage:  "Number of consecutive transient apiserver errors, such as timeouts, throttling or unavailable webhooks, that are retried quietly with jitter for each chart before the error is logged and an APIUnavailable event is recorded. Zero reports every error.",
+		},
+		cli.BoolFlag{
+			Name:   "check-repo-reachability",
+			EnvVar: "CHECK_REPO_REACHABILITY",
+			Usage:  "Check that the repo of each chart can be reached from the controller, through the chart's proxy and with its CA certificates, before creating a job for it, and report the result in the chart's RepoReachable condition.",
+		},
+		cli.IntFlag{
+			Name:   "status-history-limit",
+			EnvVar: "STATUS_HISTORY_LIMIT",
+			Value:  helmcontroller.StatusHistoryLimit,
+			Usage:  "Number of reconcile milestones, such as JobCreated, JobSucceeded, JobFailed and ValuesChanged, kept in the status.history of each chart. Zero disables the history.",
+		},
+		cli.DurationFlag{
+			Name:   "transient-retry-interval",
+			EnvVar: "TRANSIENT_RETRY_INTERVAL",
+			Value:  helmcontroller.TransientRetryInterval,
+			Usage:  "Initial interval between retries of charts that failed with a transient apiserver error, doubled on each retry.",
+		},
+		cli.DurationFlag{
+			Name:   "delete-job-ttl",
+			EnvVar: "DELETE_JOB_TTL",
+			Value:  helmcontroller.DeleteJobTTL,
+			Usage:  "How long finished delete jobs are kept before they are removed by the TTL controller, in case a chart is removed without the controller, which otherwise removes them once they succeed. Zero disables the TTL.",
+		},
+		cli.DurationFlag{
+			Name:   "release-verify-interval",
+			EnvVar: "RELEASE_VERIFY_INTERVAL",
+			Value:  helmcontroller.ReleaseVerifyInterval,
+			Usage:  "How often the releases of installed charts are checked for having been uninstalled or changed outside of the controller. Zero disables the check.",
+		},
+		cli.StringSliceFlag{
+			Name:   "job-node-selector",
+			EnvVar: "JOB_NODE_SELECTOR",
+			Usage:  "Node labels, in key=value format, that all jobs are confined to, in addition to any set by the chart. Not applied to bootstrap charts.",
+		},
+		cli.StringSliceFlag{
+			Name:   "job-toleration",
+			EnvVar: "JOB_TOLERATIONS",
+			Usage:  "Tolerations, in key[=value][:effect] format, added to all jobs in addition to any set by the chart. Not applied to bootstrap charts.",
+		},
+		cli.BoolFlag{
+			Name:   "dry-run-all",
+			EnvVar: "DRY_RUN_ALL",
+			Usage:  "Record the jobs and other objects that would be applied for each chart as events, without creating or deleting anything. Useful for validating a restored cluster before letting the controller act.",
+		},
+		cli.BoolFlag{
+			Name:   "spread-jobs",
+			EnvVar: "SPREAD_JOBS",
+			Usage:  "Prefer scheduling jobs on nodes that are not already running other jobs, so that install load is spread across the cluster when many charts are reconciled at once.",
+		},
+		cli.BoolFlag{
+			Name:   "reinstall-on-namespace-recreate",
+			EnvVar: "REINSTALL_ON_NAMESPACE_RECREATE",
+			Usage:  "Re-run the install job for charts whose target namespace is deleted and later re-created.",
+		},
+		cli.IntFlag{
+			Name:   "max-concurrent-jobs",
+			EnvVar: "MAX_CONCURRENT_JOBS",
+			Usage:  "Maximum number of jobs to run at once in each namespace; may be overridden by the helmcharts.helm.cattle.io/maxConcurrentJobs namespace annotation. 0 means unlimited.",
+		},
+		cli.StringFlag{
+			Name:   "webhook-listen-address",
+			EnvVar: "WEBHOOK_LISTEN_ADDRESS",
+			Value:  "",
+			Usage:  "Address to serve the admission webhook on, e.g. :9443. Empty disables the webhook.",
+		},
+		cli.StringFlag{
+			Name:   "webhook-cert-file",
+			EnvVar: "WEBHOOK_CERT_FILE",
+			Value:  "",
+			Usage:  "TLS certificate file used by the admission webhook.",
+		},
+		cli.StringFlag{
+			Name:   "webhook-key-file",
+			EnvVar: "WEBHOOK_KEY_FILE",
+			Value:  "",
+			Usage:  "TLS private key file used by the admission webhook.",
+		},
+		cli.StringFlag{
+			Name:   "metrics-listen-address",
+			EnvVar: "METRICS_LISTEN_ADDRESS",
+			Value:  "",
+			Usage:  "Address to serve Prometheus metrics on, e.g. :8080. Empty disables metrics.",
+		},
+		cli.BoolFlag{
+			Name:   "log-apply-plan",
+			EnvVar: "LOG_APPLY_PLAN",
+			Usage:  "Log and record an event listing the objects that will be created, updated or deleted for each chart before they are applied, to help debug unexpected job recreation.",
+		},
+		cli.BoolFlag{
+			Name:   "projected-service-account-token",
+			EnvVar: "PROJECTED_SERVICE_ACCOUNT_TOKEN",
+			Usage:  "Mount a projected ServiceAccount token, CA bundle and namespace into jobs at the usual path, instead of relying on the token being automounted, for clusters that do not create token Secrets for ServiceAccounts.",
+		},
+		cli.StringFlag{
+			Name:   "target-context-selector",
+			EnvVar: "TARGET_CONTEXT_SELECTOR",
+			Usage:  "Label selector for Secrets holding kubeconfigs of virtual clusters, such as the vc-<name> Secrets created by vcluster, that charts in the same namespace can be installed into by setting spec.targetContext. Empty disables target contexts.",
+		},
+		cli.BoolFlag{
+			Name:   "record-provenance",
+			EnvVar: "RECORD_PROVENANCE",
+			Usage:  "Record the chart name, version and app version, and the container images, of each chart's deployed release in a ConfigMap labeled " + render.ProvenanceLabel + "=true in the chart's namespace, for vulnerability scanning.",
+		},
+		cli.BoolFlag{
+			Name:   "stream-job-logs",
+			EnvVar: "STREAM_JOB_LOGS",
+			Usage:  "Mirror the logs of job pods into the controller log, each line prefixed with the namespace and name of its chart, so that helm failures can be found without reading the logs of completed pods.",
+		},
+		cli.BoolFlag{
+			Name:   "audit-log",
+			EnvVar: "AUDIT_LOG",
+			Usage:  "Record a SpecChanged event, annotated with the manager and the changed fields, each time the spec of a chart changes. Secret-like values are redacted.",
+		},
+		cli.StringFlag{
+			Name:   "audit-log-file",
+			EnvVar: "AUDIT_LOG_FILE",
+			Value:  "",
+			Usage:  "File to append a JSON line to each time the spec of a chart changes. Secret-like values are redacted. Empty disables the file.",
+		},
+		cli.StringSliceFlag{
+			Name:   "values-transformer",
+			EnvVar: "VALUES_TRANSFORMERS",
+			Usage:  "Executable that is passed the merged values of each chart and its HelmChartConfig as YAML on stdin, and writes the values to install the chart with to stdout. May be repeated; transformers are run in order.",
+		},
+		cli.StringFlag{
+			Name:   "dashboard-listen-address",
+			EnvVar: "DASHBOARD_LISTEN_ADDRESS",
+			Value:  "",
+			Usage:  "Address to serve a JSON summary of chart states on, e.g. :8443. Requests must carry a bearer token for a user allowed to list HelmCharts. Empty disables the dashboard.",
+		},
+		cli.StringFlag{
+			Name:   "dashboard-cert-file",
+			EnvVar: "DASHBOARD_CERT_FILE",
+			Value:  "",
+			Usage:  "TLS certificate file used by the dashboard. Must be set along with --dashboard-key-file. If both are unset, the dashboard is served without TLS.",
+		},
+		cli.StringFlag{
+			Name:   "dashboard-key-file",
+			EnvVar: "DASHBOARD_KEY_FILE",
+			Value:  "",
+			Usage:  "TLS private key file used by the dashboard. Must be set along with --dashboard-cert-file.",
+		},
 	}
 	app.Action = run
 
@@ -70,6 +354,113 @@ func run(c *cli.Context) error {
 	kubeconfig := c.String("kubeconfig")
 	namespace := c.String("namespace")
 	threadiness := c.Int("threads")
+	webhookAddress := c.String("webhook-listen-address")
+	metricsAddress := c.String("metrics-listen-address")
+	dashboardAddress := c.String("dashboard-listen-address")
+
+	helmcontroller.DisableSidecars = c.Bool("disable-sidecars")
+	if safeToEvict := c.String("job-safe-to-evict"); safeToEvict != "" {
+		safe, err := strconv.ParseBool(safeToEvict)
+		if err != nil {
+			klog.Fatalf("Invalid job safe-to-evict policy %q: must be true or false", safeToEvict)
+		}
+		helmcontroller.JobSafeToEvict = &safe
+	}
+	helmcontroller.DisableJobSecurityContext = c.Bool("disable-job-security-context")
+	helmcontroller.ProjectedServiceAccountToken = c.Bool("projected-service-account-token")
+	helmcontroller.UpgradeJobImage = c.Bool("upgrade-job-image")
+	helmcontroller.TolerateUnschedulable = c.Bool("tolerate-unschedulable")
+	helmcontroller.SpreadJobs = c.Bool("spread-jobs")
+	helmcontroller.DryRun = c.Bool("dry-run-all")
+	switch policy := c.String("terminating-namespace-policy"); policy {
+	case helmcontroller.TerminatingNamespacePolicyWait, helmcontroller.TerminatingNamespacePolicySkip:
+		helmcontroller.TerminatingNamespacePolicy = policy
+	default:
+		klog.Fatalf("Invalid terminating namespace policy %q: must be %s or %s", policy, helmcontroller.TerminatingNamespacePolicyWait, helmcontroller.TerminatingNamespacePolicySkip)
+	}
+	helmcontroller.ReinstallOnNamespaceRecreate = c.Bool("reinstall-on-namespace-recreate")
+	helmcontroller.MaxConcurrentJobs = c.Int("max-concurrent-jobs")
+	helmcontroller.MaxTimeout = c.Duration("max-timeout")
+	if helmcontroller.MaxTimeout != 0 && helmcontroller.MaxTimeout < helmcontroller.DefaultTimeout {
+		klog.Fatalf("Invalid max timeout %s: must not be less than the default timeout of %s", helmcontroller.MaxTimeout, helmcontroller.DefaultTimeout)
+	}
+	helmcontroller.SlowJobFactor = c.Float64("slow-job-factor")
+	helmcontroller.DriftGracePeriod = c.Duration("drift-grace-period")
+	helmcontroller.TransientErrorBudget = c.Int("transient-error-budget")
+	helmcontroller.TransientRetryInterval = c.Duration("transient-retry-interval")
+	helmcontroller.StatusHistoryLimit = c.Int("status-history-limit")
+	helmcontroller.CheckRepoReachability = c.Bool("check-repo-reachability")
+	helmcontroller.LogApplyPlan = c.Bool("log-apply-plan")
+	helmcontroller.StreamJobLogs = c.Bool("stream-job-logs")
+	helmcontroller.RecordProvenance = c.Bool("record-provenance")
+	if selector := c.String("target-context-selector"); selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			klog.Fatalf("Invalid target context selector %q: %v", selector, err)
+		}
+		helmcontroller.TargetContextSelector = parsed
+	}
+	helmcontroller.AuditLog = c.Bool("audit-log")
+	if auditLogFile := c.String("audit-log-file"); auditLogFile != "" {
+		sink, err := helmcontroller.NewAuditFileSink(auditLogFile)
+		if err != nil {
+			klog.Fatalf("Error opening audit log file: %s", err.Error())
+		}
+		helmcontroller.AuditSinks = append(helmcontroller.AuditSinks, sink)
+	}
+	for _, path := range c.StringSlice("values-transformer") {
+		helmcontroller.ValuesTransformers = append(helmcontroller.ValuesTransformers, render.NewExecValuesTransformer(path))
+	}
+	helmcontroller.ReleaseVerifyInterval = c.Duration("release-verify-interval")
+	helmcontroller.DeleteJobTTL = c.Duration("delete-job-ttl")
+	if commonLabels := c.StringSlice("common-labels"); len(commonLabels) > 0 {
+		helmcontroller.CommonLabels = kv.SplitMapFromSlice(commonLabels)
+	}
+	if releaseSecretLabels := c.StringSlice("release-secret-labels"); len(releaseSecretLabels) > 0 {
+		helmcontroller.ReleaseSecretLabels = kv.SplitMapFromSlice(releaseSecretLabels)
+	}
+	if releaseLabels := c.StringSlice("release-labels"); len(releaseLabels) > 0 {
+		helmcontroller.ReleaseLabels = kv.SplitMapFromSlice(releaseLabels)
+	}
+	if releaseAnnotations := c.StringSlice("release-annotations"); len(releaseAnnotations) > 0 {
+		helmcontroller.ReleaseAnnotations = kv.SplitMapFromSlice(releaseAnnotations)
+	}
+	if err := render.ValidateReleaseMetadata(helmcontroller.ReleaseLabels, helmcontroller.ReleaseAnnotations); err != nil {
+		klog.Fatalf("Invalid release metadata: %v", err)
+	}
+
+	if repoMirrors := c.StringSlice("repo-mirror"); len(repoMirrors) > 0 {
+		helmcontroller.RepoMirrors = kv.SplitMapFromSlice(repoMirrors)
+	}
+	helmcontroller.RepoMirrorAuthSecret = c.String("repo-mirror-auth-secret")
+	if nodeSelector := c.StringSlice("job-node-selector"); len(nodeSelector) > 0 {
+		helmcontroller.JobNodeSelector = kv.SplitMapFromSlice(nodeSelector)
+	}
+	for _, t := range c.StringSlice("job-toleration") {
+		toleration, err := render.ParseToleration(t)
+		if err != nil {
+			klog.Fatalf("Error parsing job toleration: %s", err.Error())
+		}
+		helmcontroller.JobTolerations = append(helmcontroller.JobTolerations, toleration)
+	}
+	if caBundle := c.String("ca-bundle-config-map"); caBundle != "" {
+		helmcontroller.CABundle = &core.ConfigMapKeySelector{
+			LocalObjectReference: core.LocalObjectReference{Name: caBundle},
+			Key:                  c.String("ca-bundle-config-map-key"),
+		}
+	}
+
+	if err := render.SetNameTemplates(render.NameTemplates{
+		Job:                 c.String("job-name-template"),
+		ServiceAccount:      c.String("service-account-name-template"),
+		ClusterRoleBinding:  c.String("cluster-role-binding-name-template"),
+		ValuesConfigMap:     c.String("values-config-map-name-template"),
+		ContentConfigMap:    c.String("content-config-map-name-template"),
+		ProvenanceConfigMap: c.String("provenance-config-map-name-template"),
+		NetworkPolicy:       c.String("network-policy-name-template"),
+	}); err != nil {
+		klog.Fatalf("Error setting name templates: %s", err.Error())
+	}
 
 	if threadiness <= 0 {
 		klog.Infof("Can not start with thread count of %d, please pass a proper thread count.", threadiness)
@@ -91,50 +482,56 @@ func run(c *cli.Context) error {
 		klog.Fatalf("Error building config from flags: %s", err.Error())
 	}
 
-	helms, err := helmv1.NewFactoryFromConfigWithNamespace(cfg, namespace)
+	factories, err := helmcontroller.NewFactories(cfg, namespace, nil)
 	if err != nil {
 		klog.Fatalf("Error building sample controllers: %s", err.Error())
 	}
 
-	batches, err := batchv1.NewFactoryFromConfigWithNamespace(cfg, namespace)
+	k8sClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
-		klog.Fatalf("Error building sample controllers: %s", err.Error())
+		klog.Fatalf("Error building kubernetes client: %s", err.Error())
 	}
 
-	rbacs, err := rbacv1.NewFactoryFromConfigWithNamespace(cfg, namespace)
+	discoverClient, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
-		klog.Fatalf("Error building sample controllers: %s", err.Error())
+		klog.Fatalf("Error building discovery client: %s", err.Error())
 	}
 
-	cores, err := corev1.NewFactoryFromConfigWithNamespace(cfg, namespace)
+	dynamicClient, err := dynamic.NewForConfig(cfg)
 	if err != nil {
-		klog.Fatalf("Error building sample controllers: %s", err.Error())
+		klog.Fatalf("Error building dynamic client: %s", err.Error())
 	}
 
-	k8sClient, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		klog.Fatalf("Error building kubernetes client: %s", err.Error())
+	objectSetApply := apply.New(discoverClient, apply.NewClientFactory(cfg))
+
+	helmcontroller.RegisterFactories(ctx, k8sClient, dynamicClient, objectSetApply, factories)
+
+	if err := factories.Start(ctx, threadiness); err != nil {
+		klog.Fatalf("Error starting: %s", err.Error())
 	}
 
-	discoverClient, err := discovery.NewDiscoveryClientForConfig(cfg)
-	if err != nil {
-		klog.Fatalf("Error building discovery client: %s", err.Error())
+	if webhookAddress != "" {
+		go func() {
+			if err := webhook.ListenAndServe(ctx, k8sClient, webhookAddress, c.String("webhook-cert-file"), c.String("webhook-key-file")); err != nil {
+				klog.Fatalf("Error running webhook: %s", err.Error())
+			}
+		}()
 	}
 
-	objectSetApply := apply.New(discoverClient, apply.NewClientFactory(cfg))
+	if metricsAddress != "" {
+		go func() {
+			if err := metrics.ListenAndServe(ctx, metricsAddress); err != nil {
+				klog.Fatalf("Error running metrics server: %s", err.Error())
+			}
+		}()
+	}
 
-	helmcontroller.Register(ctx,
-		k8sClient,
-		objectSetApply,
-		helms.Helm().V1().HelmChart(),
-		helms.Helm().V1().HelmChartConfig(),
-		batches.Batch().V1().Job(),
-		rbacs.Rbac().V1().ClusterRoleBinding(),
-		cores.Core().V1().ServiceAccount(),
-		cores.Core().V1().ConfigMap())
-
-	if err := start.All(ctx, threadiness, helms, batches, rbacs, cores); err != nil {
-		klog.Fatalf("Error starting: %s", err.Error())
+	if dashboardAddress != "" {
+		go func() {
+			if err := dashboard.ListenAndServe(ctx, k8sClient, factories.Helm.Helm().V1().HelmChart().Cache(), dashboardAddress, c.String("dashboard-cert-file"), c.String("dashboard-key-file")); err != nil {
+				klog.Fatalf("Error running dashboard: %s", err.Error())
+			}
+		}()
 	}
 
 	<-ctx.Done()
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -17,23 +18,126 @@ type HelmChart struct {
 }
 
 type HelmChartSpec struct {
-	TargetNamespace string                        `json:"targetNamespace,omitempty"`
-	Chart           string                        `json:"chart,omitempty"`
-	Version         string                        `json:"version,omitempty"`
-	Repo            string                        `json:"repo,omitempty"`
-	RepoCA          string                        `json:"repoCA,omitempty"`
-	Set             map[string]intstr.IntOrString `json:"set,omitempty"`
-	ValuesContent   string                        `json:"valuesContent,omitempty"`
-	HelmVersion     string                        `json:"helmVersion,omitempty"`
-	Bootstrap       bool                          `json:"bootstrap,omitempty"`
-	ChartContent    string                        `json:"chartContent,omitempty"`
-	JobImage        string                        `json:"jobImage,omitempty"`
-	Timeout         *metav1.Duration              `json:"timeout,omitempty"`
-	FailurePolicy   string                        `json:"failurePolicy,omitempty"`
+	TargetNamespace          string                        `json:"targetNamespace,omitempty"`
+	Chart                    string                        `json:"chart,omitempty"`
+	Version                  string                        `json:"version,omitempty"`
+	Repo                     string                        `json:"repo,omitempty"`
+	RepoCA                   string                        `json:"repoCA,omitempty"`
+	Set                      map[string]intstr.IntOrString `json:"set,omitempty"`
+	ValuesContent            string                        `json:"valuesContent,omitempty"`
+	HelmVersion              string                        `json:"helmVersion,omitempty"`
+	Bootstrap                bool                          `json:"bootstrap,omitempty"`
+	ChartContent             string                        `json:"chartContent,omitempty"`
+	JobImage                 string                        `json:"jobImage,omitempty"`
+	Timeout                  *metav1.Duration              `json:"timeout,omitempty"`
+	FailurePolicy            string                        `json:"failurePolicy,omitempty"`
+	AuthSecret               *corev1.LocalObjectReference  `json:"authSecret,omitempty"`
+	CredentialsMountMode     string                        `json:"credentialsMountMode,omitempty"`
+	GeneratedLabels          map[string]string             `json:"generatedLabels,omitempty"`
+	GeneratedAnnotations     map[string]string             `json:"generatedAnnotations,omitempty"`
+	ChartContentSecret       *corev1.LocalObjectReference  `json:"chartContentSecret,omitempty"`
+	DisableSidecars          *bool                         `json:"disableSidecars,omitempty"`
+	GenerateNetworkPolicy    bool                          `json:"generateNetworkPolicy,omitempty"`
+	ProxySecret              *corev1.LocalObjectReference  `json:"proxySecret,omitempty"`
+	TolerateUnschedulable    *bool                         `json:"tolerateUnschedulable,omitempty"`
+	FailurePolicyRetries     *int32                        `json:"failurePolicyRetries,omitempty" wrangler:"min=0"`
+	HostAliases              []corev1.HostAlias            `json:"hostAliases,omitempty"`
+	TargetNamespacePolicy    string                        `json:"targetNamespacePolicy,omitempty"`
+	ReleaseName              string                        `json:"releaseName,omitempty"`
+	KeepResourcesOnRelocate  bool                          `json:"keepResourcesOnRelocate,omitempty"`
+	HelmChartConfigRef       *HelmChartConfigReference     `json:"helmChartConfigRef,omitempty"`
+	SpreadJobs               *bool                         `json:"spreadJobs,omitempty"`
+	SetJSON                  map[string]string             `json:"setJSON,omitempty"`
+	SetFiles                 []HelmChartSetFile            `json:"setFiles,omitempty"`
+	OrphanPolicy             string                        `json:"orphanPolicy,omitempty"`
+	JobEnvFrom               []corev1.EnvFromSource        `json:"jobEnvFrom,omitempty"`
+	CABundle                 *corev1.ConfigMapKeySelector  `json:"caBundle,omitempty"`
+	JobNodeSelector          map[string]string             `json:"jobNodeSelector,omitempty"`
+	JobTolerations           []corev1.Toleration           `json:"jobTolerations,omitempty"`
+	RepoServiceAccountAuth   bool                          `json:"repoServiceAccountAuth,omitempty"`
+	ExpectedDuration         *metav1.Duration              `json:"expectedDuration,omitempty"`
+	Devel                    bool                          `json:"devel,omitempty"`
+	HelmPlugins              []HelmPlugin                  `json:"helmPlugins,omitempty"`
+	RepoStorageSecret        *corev1.LocalObjectReference  `json:"repoStorageSecret,omitempty"`
+	ChartChecksum            string                        `json:"chartChecksum,omitempty"`
+	ChartFetcher             *HelmChartFetcher             `json:"chartFetcher,omitempty"`
+	CreateRBAC               *bool                         `json:"createRBAC,omitempty"`
+	ServiceAccountName       string                        `json:"serviceAccountName,omitempty"`
+	ReleaseSecretLabels      map[string]string             `json:"releaseSecretLabels,omitempty"`
+	ReleaseSecretAnnotations map[string]string             `json:"releaseSecretAnnotations,omitempty"`
+	EventPolicy              string                        `json:"eventPolicy,omitempty"`
+	DisableSecurityContext   *bool                         `json:"disableSecurityContext,omitempty"`
+	Description              string                        `json:"description,omitempty"`
+	DisableHooks             bool                          `json:"disableHooks,omitempty"`
+	HelmTimeout              *metav1.Duration              `json:"helmTimeout,omitempty"`
+	RestartPolicy            string                        `json:"restartPolicy,omitempty"`
+	PodFailurePolicy         *HelmChartPodFailurePolicy    `json:"podFailurePolicy,omitempty"`
+	SetFieldRefs             map[string]string             `json:"setFieldRefs,omitempty"`
+	UninstallWait            bool                          `json:"uninstallWait,omitempty"`
+	CreateNamespace          bool                          `json:"createNamespace,omitempty"`
+	NodeName                 string                        `json:"nodeName,omitempty"`
+	Force                    bool                          `json:"force,omitempty"`
+	TargetContext            string                        `json:"targetContext,omitempty"`
+	SetFrom                  []HelmChartSetFrom            `json:"setFrom,omitempty"`
+	CommonLabels             map[string]string             `json:"commonLabels,omitempty"`
+	CommonAnnotations        map[string]string             `json:"commonAnnotations,omitempty"`
+	JobSuspend               bool                          `json:"jobSuspend,omitempty"`
+	SafeToEvict              *bool                         `json:"safeToEvict,omitempty"`
+	UninstallTimeout         *metav1.Duration              `json:"uninstallTimeout,omitempty"`
+	BootstrapWeight          int                           `json:"bootstrapWeight,omitempty"`
 }
 
 type HelmChartStatus struct {
-	JobName string `json:"jobName,omitempty"`
+	JobName           string                  `json:"jobName,omitempty"`
+	JobImage          string                  `json:"jobImage,omitempty"`
+	Conditions        []HelmChartCondition    `json:"conditions,omitempty"`
+	TargetNamespace   string                  `json:"targetNamespace,omitempty"`
+	ReleaseName       string                  `json:"releaseName,omitempty"`
+	OrphanedResources []string                `json:"orphanedResources,omitempty"`
+	ChartVersion      string                  `json:"chartVersion,omitempty"`
+	ReleaseRevision   int                     `json:"releaseRevision,omitempty"`
+	Source            *HelmChartSource        `json:"source,omitempty"`
+	History           []HelmChartHistoryEntry `json:"history,omitempty"`
+}
+
+type HelmChartHistoryEntry struct {
+	Type    string      `json:"type"`
+	Time    metav1.Time `json:"time"`
+	Message string      `json:"message,omitempty"`
+}
+
+type HelmChartSource struct {
+	Repo          string `json:"repo,omitempty"`
+	Chart         string `json:"chart,omitempty"`
+	Version       string `json:"version,omitempty"`
+	ArchiveDigest string `json:"archiveDigest,omitempty"`
+	ChartDigest   string `json:"chartDigest,omitempty"`
+}
+
+type HelmChartConditionType string
+
+const (
+	HelmChartReady           HelmChartConditionType = "Ready"
+	HelmChartUpgradesFrozen  HelmChartConditionType = "UpgradesFrozen"
+	HelmChartFailed          HelmChartConditionType = "Failed"
+	HelmChartRelocating      HelmChartConditionType = "Relocating"
+	HelmChartProgressing     HelmChartConditionType = "Progressing"
+	HelmChartRolledBack      HelmChartConditionType = "RolledBack"
+	HelmChartReleaseVerified HelmChartConditionType = "ReleaseVerified"
+	HelmChartPaused          HelmChartConditionType = "Paused"
+	HelmChartSourceConflict  HelmChartConditionType = "SourceConflict"
+	HelmChartDrifted         HelmChartConditionType = "Drifted"
+	HelmChartRepoReachable   HelmChartConditionType = "RepoReachable"
+
+	HelmChartConfigValuesValid HelmChartConditionType = "ValuesValid"
+)
+
+type HelmChartCondition struct {
+	Type           HelmChartConditionType `json:"type"`
+	Status         corev1.ConditionStatus `json:"status"`
+	LastUpdateTime string                 `json:"lastUpdateTime,omitempty"`
+	Reason         string                 `json:"reason,omitempty"`
+	Message        string                 `json:"message,omitempty"`
 }
 
 // +genclient
@@ -43,10 +147,105 @@ type HelmChartConfig struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec HelmChartConfigSpec `json:"spec,omitempty"`
+	Spec   HelmChartConfigSpec   `json:"spec,omitempty"`
+	Status HelmChartConfigStatus `json:"status,omitempty"`
+}
+
+type HelmChartSetFile struct {
+	Key             string                       `json:"key"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+type HelmChartSetFrom struct {
+	Key       string                  `json:"key"`
+	ValueFrom HelmChartSetValueSource `json:"valueFrom"`
+}
+
+type HelmChartSetValueSource struct {
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+type HelmChartFetcher struct {
+	Image   string          `json:"image"`
+	Command []string        `json:"command,omitempty"`
+	Args    []string        `json:"args,omitempty"`
+	Env     []corev1.EnvVar `json:"env,omitempty"`
+}
+
+type HelmChartPodFailurePolicy struct {
+	Rules []HelmChartPodFailurePolicyRule `json:"rules"`
+}
+
+type HelmChartPodFailurePolicyRule struct {
+	Action          string                                    `json:"action"`
+	OnExitCodes     *HelmChartPodFailurePolicyOnExitCodes     `json:"onExitCodes,omitempty"`
+	OnPodConditions []HelmChartPodFailurePolicyOnPodCondition `json:"onPodConditions,omitempty"`
+}
+
+type HelmChartPodFailurePolicyOnExitCodes struct {
+	ContainerName *string `json:"containerName,omitempty"`
+	Operator      string  `json:"operator"`
+	Values        []int32 `json:"values"`
+}
+
+type HelmChartPodFailurePolicyOnPodCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type HelmPlugin struct {
+	URL      string `json:"url"`
+	Checksum string `json:"checksum"`
+}
+
+type HelmChartConfigReference struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
 }
 
 type HelmChartConfigSpec struct {
 	ValuesContent string `json:"valuesContent,omitempty"`
 	FailurePolicy string `json:"failurePolicy,omitempty"`
 }
+
+type HelmChartConfigStatus struct {
+	ObservedGeneration int64                `json:"observedGeneration,omitempty"`
+	TargetChartFound   bool                 `json:"targetChartFound"`
+	Applied            bool                 `json:"applied"`
+	LastAppliedHash    string               `json:"lastAppliedHash,omitempty"`
+	Conditions         []HelmChartCondition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type HelmChartSummary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status HelmChartSummaryStatus `json:"status,omitempty"`
+}
+
+type HelmChartSummaryStatus struct {
+	Charts     int                         `json:"charts"`
+	Ready      int                         `json:"ready"`
+	Failed     int                         `json:"failed"`
+	Namespaces []HelmChartNamespaceSummary `json:"namespaces,omitempty"`
+}
+
+type HelmChartNamespaceSummary struct {
+	Namespace    string             `json:"namespace"`
+	Charts       int                `json:"charts"`
+	Ready        int                `json:"ready"`
+	Failed       int                `json:"failed"`
+	LastFailures []HelmChartFailure `json:"lastFailures,omitempty"`
+}
+
+type HelmChartFailure struct {
+	Name           string `json:"name"`
+	Reason         string `json:"reason,omitempty"`
+	Message        string `json:"message,omitempty"`
+	LastUpdateTime string `json:"lastUpdateTime,omitempty"`
+}
@@ -22,6 +22,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
@@ -33,7 +34,7 @@ func (in *HelmChart) DeepCopyInto(out *HelmChart) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -55,12 +56,29 @@ func (in *HelmChart) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartCondition) DeepCopyInto(out *HelmChartCondition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartCondition.
+func (in *HelmChartCondition) DeepCopy() *HelmChartCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartConfig) DeepCopyInto(out *HelmChartConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -115,6 +133,22 @@ func (in *HelmChartConfigList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartConfigReference) DeepCopyInto(out *HelmChartConfigReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartConfigReference.
+func (in *HelmChartConfigReference) DeepCopy() *HelmChartConfigReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartConfigReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartConfigSpec) DeepCopyInto(out *HelmChartConfigSpec) {
 	*out = *in
@@ -131,6 +165,93 @@ func (in *HelmChartConfigSpec) DeepCopy() *HelmChartConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartConfigStatus) DeepCopyInto(out *HelmChartConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]HelmChartCondition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartConfigStatus.
+func (in *HelmChartConfigStatus) DeepCopy() *HelmChartConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartFailure) DeepCopyInto(out *HelmChartFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartFailure.
+func (in *HelmChartFailure) DeepCopy() *HelmChartFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartFetcher) DeepCopyInto(out *HelmChartFetcher) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartFetcher.
+func (in *HelmChartFetcher) DeepCopy() *HelmChartFetcher {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartFetcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartHistoryEntry) DeepCopyInto(out *HelmChartHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartHistoryEntry.
+func (in *HelmChartHistoryEntry) DeepCopy() *HelmChartHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartList) DeepCopyInto(out *HelmChartList) {
 	*out = *in
@@ -164,6 +285,198 @@ func (in *HelmChartList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartNamespaceSummary) DeepCopyInto(out *HelmChartNamespaceSummary) {
+	*out = *in
+	if in.LastFailures != nil {
+		in, out := &in.LastFailures, &out.LastFailures
+		*out = make([]HelmChartFailure, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartNamespaceSummary.
+func (in *HelmChartNamespaceSummary) DeepCopy() *HelmChartNamespaceSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartNamespaceSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartPodFailurePolicy) DeepCopyInto(out *HelmChartPodFailurePolicy) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]HelmChartPodFailurePolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartPodFailurePolicy.
+func (in *HelmChartPodFailurePolicy) DeepCopy() *HelmChartPodFailurePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartPodFailurePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartPodFailurePolicyOnExitCodes) DeepCopyInto(out *HelmChartPodFailurePolicyOnExitCodes) {
+	*out = *in
+	if in.ContainerName != nil {
+		in, out := &in.ContainerName, &out.ContainerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartPodFailurePolicyOnExitCodes.
+func (in *HelmChartPodFailurePolicyOnExitCodes) DeepCopy() *HelmChartPodFailurePolicyOnExitCodes {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartPodFailurePolicyOnExitCodes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartPodFailurePolicyOnPodCondition) DeepCopyInto(out *HelmChartPodFailurePolicyOnPodCondition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartPodFailurePolicyOnPodCondition.
+func (in *HelmChartPodFailurePolicyOnPodCondition) DeepCopy() *HelmChartPodFailurePolicyOnPodCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartPodFailurePolicyOnPodCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartPodFailurePolicyRule) DeepCopyInto(out *HelmChartPodFailurePolicyRule) {
+	*out = *in
+	if in.OnExitCodes != nil {
+		in, out := &in.OnExitCodes, &out.OnExitCodes
+		*out = new(HelmChartPodFailurePolicyOnExitCodes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnPodConditions != nil {
+		in, out := &in.OnPodConditions, &out.OnPodConditions
+		*out = make([]HelmChartPodFailurePolicyOnPodCondition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartPodFailurePolicyRule.
+func (in *HelmChartPodFailurePolicyRule) DeepCopy() *HelmChartPodFailurePolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartPodFailurePolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSetFile) DeepCopyInto(out *HelmChartSetFile) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSetFile.
+func (in *HelmChartSetFile) DeepCopy() *HelmChartSetFile {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSetFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSetFrom) DeepCopyInto(out *HelmChartSetFrom) {
+	*out = *in
+	in.ValueFrom.DeepCopyInto(&out.ValueFrom)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSetFrom.
+func (in *HelmChartSetFrom) DeepCopy() *HelmChartSetFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSetFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSetValueSource) DeepCopyInto(out *HelmChartSetValueSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSetValueSource.
+func (in *HelmChartSetValueSource) DeepCopy() *HelmChartSetValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSetValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSource) DeepCopyInto(out *HelmChartSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSource.
+func (in *HelmChartSource) DeepCopy() *HelmChartSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartSpec) DeepCopyInto(out *HelmChartSpec) {
 	*out = *in
@@ -179,6 +492,199 @@ func (in *HelmChartSpec) DeepCopyInto(out *HelmChartSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.AuthSecret != nil {
+		in, out := &in.AuthSecret, &out.AuthSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.GeneratedLabels != nil {
+		in, out := &in.GeneratedLabels, &out.GeneratedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GeneratedAnnotations != nil {
+		in, out := &in.GeneratedAnnotations, &out.GeneratedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ChartContentSecret != nil {
+		in, out := &in.ChartContentSecret, &out.ChartContentSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DisableSidecars != nil {
+		in, out := &in.DisableSidecars, &out.DisableSidecars
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxySecret != nil {
+		in, out := &in.ProxySecret, &out.ProxySecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.TolerateUnschedulable != nil {
+		in, out := &in.TolerateUnschedulable, &out.TolerateUnschedulable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailurePolicyRetries != nil {
+		in, out := &in.FailurePolicyRetries, &out.FailurePolicyRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]corev1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HelmChartConfigRef != nil {
+		in, out := &in.HelmChartConfigRef, &out.HelmChartConfigRef
+		*out = new(HelmChartConfigReference)
+		**out = **in
+	}
+	if in.SpreadJobs != nil {
+		in, out := &in.SpreadJobs, &out.SpreadJobs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SetJSON != nil {
+		in, out := &in.SetJSON, &out.SetJSON
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SetFiles != nil {
+		in, out := &in.SetFiles, &out.SetFiles
+		*out = make([]HelmChartSetFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JobEnvFrom != nil {
+		in, out := &in.JobEnvFrom, &out.JobEnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobNodeSelector != nil {
+		in, out := &in.JobNodeSelector, &out.JobNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.JobTolerations != nil {
+		in, out := &in.JobTolerations, &out.JobTolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExpectedDuration != nil {
+		in, out := &in.ExpectedDuration, &out.ExpectedDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.HelmPlugins != nil {
+		in, out := &in.HelmPlugins, &out.HelmPlugins
+		*out = make([]HelmPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.RepoStorageSecret != nil {
+		in, out := &in.RepoStorageSecret, &out.RepoStorageSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ChartFetcher != nil {
+		in, out := &in.ChartFetcher, &out.ChartFetcher
+		*out = new(HelmChartFetcher)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateRBAC != nil {
+		in, out := &in.CreateRBAC, &out.CreateRBAC
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReleaseSecretLabels != nil {
+		in, out := &in.ReleaseSecretLabels, &out.ReleaseSecretLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReleaseSecretAnnotations != nil {
+		in, out := &in.ReleaseSecretAnnotations, &out.ReleaseSecretAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DisableSecurityContext != nil {
+		in, out := &in.DisableSecurityContext, &out.DisableSecurityContext
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HelmTimeout != nil {
+		in, out := &in.HelmTimeout, &out.HelmTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PodFailurePolicy != nil {
+		in, out := &in.PodFailurePolicy, &out.PodFailurePolicy
+		*out = new(HelmChartPodFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SetFieldRefs != nil {
+		in, out := &in.SetFieldRefs, &out.SetFieldRefs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SetFrom != nil {
+		in, out := &in.SetFrom, &out.SetFrom
+		*out = make([]HelmChartSetFrom, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SafeToEvict != nil {
+		in, out := &in.SafeToEvict, &out.SafeToEvict
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UninstallTimeout != nil {
+		in, out := &in.UninstallTimeout, &out.UninstallTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -195,6 +701,28 @@ func (in *HelmChartSpec) DeepCopy() *HelmChartSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartStatus) DeepCopyInto(out *HelmChartStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]HelmChartCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(HelmChartSource)
+		**out = **in
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]HelmChartHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -207,3 +735,102 @@ func (in *HelmChartStatus) DeepCopy() *HelmChartStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSummary) DeepCopyInto(out *HelmChartSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSummary.
+func (in *HelmChartSummary) DeepCopy() *HelmChartSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmChartSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSummaryList) DeepCopyInto(out *HelmChartSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HelmChartSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSummaryList.
+func (in *HelmChartSummaryList) DeepCopy() *HelmChartSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmChartSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSummaryStatus) DeepCopyInto(out *HelmChartSummaryStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]HelmChartNamespaceSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSummaryStatus.
+func (in *HelmChartSummaryStatus) DeepCopy() *HelmChartSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmPlugin) DeepCopyInto(out *HelmPlugin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmPlugin.
+func (in *HelmPlugin) DeepCopy() *HelmPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -57,3 +57,20 @@ func NewHelmChartConfig(namespace, name string, obj HelmChartConfig) *HelmChartC
 	obj.Namespace = namespace
 	return &obj
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HelmChartSummaryList is a list of HelmChartSummary resources
+type HelmChartSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HelmChartSummary `json:"items"`
+}
+
+func NewHelmChartSummary(namespace, name string, obj HelmChartSummary) *HelmChartSummary {
+	obj.APIVersion, obj.Kind = SchemeGroupVersion.WithKind("HelmChartSummary").ToAPIVersionAndKind()
+	obj.Name = name
+	obj.Namespace = namespace
+	return &obj
+}
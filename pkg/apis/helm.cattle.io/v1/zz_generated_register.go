@@ -28,8 +28,9 @@ import (
 )
 
 var (
-	HelmChartResourceName       = "helmcharts"
-	HelmChartConfigResourceName = "helmchartconfigs"
+	HelmChartResourceName        = "helmcharts"
+	HelmChartConfigResourceName  = "helmchartconfigs"
+	HelmChartSummaryResourceName = "helmchartsummaries"
 )
 
 // SchemeGroupVersion is group version used to register these objects
@@ -57,6 +58,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&HelmChartList{},
 		&HelmChartConfig{},
 		&HelmChartConfigList{},
+		&HelmChartSummary{},
+		&HelmChartSummaryList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -15,6 +15,7 @@ func main() {
 				Types: []interface{}{
 					v1.HelmChart{},
 					v1.HelmChartConfig{},
+					v1.HelmChartSummary{},
 				},
 				GenerateTypes:   true,
 				GenerateClients: true,
@@ -0,0 +1,104 @@
+//go:generate sh -c "cd ../.. && go run hack/crdgen.go > pkg/crds/crds.yaml"
+
+// Package crds provides the CustomResourceDefinitions for the helm-controller API, so that they can be
+// installed programmatically by projects that embed the controller, matching the linked controller version.
+package crds
+
+import (
+	"bytes"
+	_ "embed" // embed the generated CRD manifest
+	"errors"
+	"io"
+	"runtime/debug"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/crd"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// VersionAnnotation is set on each CRD returned by List to the version of the controller it was built with.
+	VersionAnnotation = "helm.cattle.io/version"
+
+	modulePath = "github.com/k3s-io/helm-controller"
+)
+
+var (
+	// Version is the version stamped on the CRDs. It is set at build time, and if unset is
+	// taken from the version of the helm-controller module linked into the binary.
+	Version = ""
+
+	//go:embed crds.yaml
+	manifest []byte
+)
+
+// CRDs returns the definitions that the embedded manifest is generated from.
+func CRDs() []crd.CRD {
+	chart := crd.NamespacedType("HelmChart.helm.cattle.io/v1").
+		WithSchemaFromStruct(v1.HelmChart{}).
+		WithColumn("Job", ".status.jobName").
+		WithColumn("Chart", ".spec.chart").
+		WithColumn("TargetNamespace", ".spec.targetNamespace").
+		WithColumn("Version", ".spec.version").
+		WithColumn("Repo", ".spec.repo").
+		WithColumn("HelmVersion", ".spec.helmVersion").
+		WithColumn("Bootstrap", ".spec.bootstrap")
+	config := crd.NamespacedType("HelmChartConfig.helm.cattle.io/v1").
+		WithSchemaFromStruct(v1.HelmChartConfig{}).
+		WithStatus().
+		WithColumn("Found", ".status.targetChartFound").
+		WithColumn("Applied", ".status.applied")
+	summary := crd.NonNamespacedType("HelmChartSummary.helm.cattle.io/v1").
+		WithSchemaFromStruct(v1.HelmChartSummary{}).
+		WithStatus().
+		WithColumn("Charts", ".status.charts").
+		WithColumn("Ready", ".status.ready").
+		WithColumn("Failed", ".status.failed")
+	return []crd.CRD{chart, config, summary}
+}
+
+// Manifest returns the embedded CRD manifest, as multi-document YAML.
+func Manifest() []byte {
+	return manifest
+}
+
+// List decodes the embedded manifest, annotating each CRD with the controller version.
+func List() ([]*apiextv1.CustomResourceDefinition, error) {
+	var result []*apiextv1.CustomResourceDefinition
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		crd := &apiextv1.CustomResourceDefinition{}
+		if err := decoder.Decode(crd); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if crd.Name == "" {
+			continue
+		}
+		if crd.Annotations == nil {
+			crd.Annotations = map[string]string{}
+		}
+		crd.Annotations[VersionAnnotation] = version()
+		result = append(result, crd)
+	}
+	return result, nil
+}
+
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath && info.Main.Version != "" {
+			return info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				return dep.Version
+			}
+		}
+	}
+	return "dev"
+}
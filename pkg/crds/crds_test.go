@@ -0,0 +1,33 @@
+package crds
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rancher/wrangler/pkg/crd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestUpToDate(t *testing.T) {
+	assert := assert.New(t)
+	buf := &bytes.Buffer{}
+	assert.NoError(crd.Print(buf, CRDs()))
+	assert.Equal(buf.String(), string(Manifest()), "embedded CRD manifest is out of date; run go generate ./pkg/crds")
+}
+
+func TestList(t *testing.T) {
+	assert := assert.New(t)
+	defer func(version string) { Version = version }(Version)
+	Version = "v1.2.3"
+
+	crds, err := List()
+	assert.NoError(err)
+	assert.Len(crds, 3)
+
+	var names []string
+	for _, crd := range crds {
+		names = append(names, crd.Name)
+		assert.Equal("v1.2.3", crd.Annotations[VersionAnnotation])
+	}
+	assert.Equal([]string{"helmcharts.helm.cattle.io", "helmchartconfigs.helm.cattle.io", "helmchartsummaries.helm.cattle.io"}, names)
+}
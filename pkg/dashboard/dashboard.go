@@ -0,0 +1,202 @@
+// Package dashboard serves a read-only JSON summary of the state of all charts, for lightweight UIs and scripts
+// that do not have access to the Kubernetes API.
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	helmcontroller "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io/v1"
+	"github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	authenticationclient "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+const Path = "/v1/charts"
+
+// ChartLister lists the charts in a namespace, or in all namespaces if the namespace is empty.
+type ChartLister func(namespace string) ([]*helmv1.HelmChart, error)
+
+// ChartState is the summary of a chart served by the dashboard.
+type ChartState struct {
+	Namespace  string                      `json:"namespace"`
+	Name       string                      `json:"name"`
+	Chart      string                      `json:"chart,omitempty"`
+	Version    string                      `json:"version,omitempty"`
+	JobName    string                      `json:"jobName,omitempty"`
+	Conditions []helmv1.HelmChartCondition `json:"conditions,omitempty"`
+	LastError  string                      `json:"lastError,omitempty"`
+}
+
+// ListenAndServe serves the dashboard on the given address until the context is cancelled, over TLS if a
+// certificate and key are given. An error is returned if only one of them is given, instead of falling back to
+// serving bearer tokens without TLS.
+func ListenAndServe(ctx context.Context, k8s kubernetes.Interface, charts helmcontroller.HelmChartCache, address, certFile, keyFile string) error {
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("both a certificate file and a key file must be given to serve the dashboard over TLS")
+	}
+	lister := func(namespace string) ([]*helmv1.HelmChart, error) {
+		return charts.List(namespace, labels.Everything())
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler(k8s.AuthenticationV1().TokenReviews(), k8s.AuthorizationV1().SubjectAccessReviews(), lister))
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			logrus.Errorf("Failed to shut down dashboard server: %v", err)
+		}
+	}()
+
+	logrus.Infof("Starting helm-controller dashboard on %s", address)
+	var err error
+	if certFile != "" && keyFile != "" {
+		err = server.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Handler serves the state of the charts in the namespace given by the namespace query parameter, or in all
+// namespaces. Requests must carry a bearer token for a user that is allowed to list HelmCharts in the namespace.
+func Handler(tokenReviews authenticationclient.TokenReviewInterface, accessReviews authorizationclient.SubjectAccessReviewInterface, charts ChartLister) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		namespace := req.URL.Query().Get("namespace")
+		user, err := authenticate(req, tokenReviews)
+		if err != nil {
+			logrus.Errorf("Failed to authenticate dashboard request: %v", err)
+			http.Error(rw, "failed to authenticate request", http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if allowed, err := authorize(req.Context(), accessReviews, user, namespace); err != nil {
+			logrus.Errorf("Failed to authorize dashboard request: %v", err)
+			http.Error(rw, "failed to authorize request", http.StatusInternalServerError)
+			return
+		} else if !allowed {
+			http.Error(rw, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		list, err := charts(namespace)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(states(list)); err != nil {
+			logrus.Debugf("Failed to write dashboard response: %v", err)
+		}
+	})
+}
+
+// authenticate returns the user that the request's bearer token belongs to, or nil if the request has no valid token.
+func authenticate(req *http.Request, tokenReviews authenticationclient.TokenReviewInterface) (*authenticationv1.UserInfo, error) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		return nil, nil
+	}
+	review, err := tokenReviews.Create(req.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, meta.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, nil
+	}
+	return &review.Status.User, nil
+}
+
+// authorize checks that the user is allowed to list HelmCharts in the namespace, or in all namespaces.
+func authorize(ctx context.Context, accessReviews authorizationclient.SubjectAccessReviewInterface, user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := accessReviews.Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     helmv1.SchemeGroupVersion.Group,
+				Resource:  "helmcharts",
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	}, meta.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// states summarizes the charts, sorted by namespace and name.
+func states(charts []*helmv1.HelmChart) []ChartState {
+	states := make([]ChartState, 0, len(charts))
+	for _, chart := range charts {
+		states = append(states, ChartState{
+			Namespace:  chart.Namespace,
+			Name:       chart.Name,
+			Chart:      chart.Spec.Chart,
+			Version:    chart.Spec.Version,
+			JobName:    chart.Status.JobName,
+			Conditions: chart.Status.Conditions,
+			LastError:  lastError(chart),
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Namespace != states[j].Namespace {
+			return states[i].Namespace < states[j].Namespace
+		}
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// lastError returns the message of the chart's Failed condition, or of its Ready condition if the chart's spec
+// is invalid.
+func lastError(chart *helmv1.HelmChart) string {
+	for _, cond := range chart.Status.Conditions {
+		if cond.Type == helmv1.HelmChartFailed && cond.Status == core.ConditionTrue {
+			return cond.Message
+		}
+	}
+	for _, cond := range chart.Status.Conditions {
+		if cond.Type == helmv1.HelmChartReady && cond.Status == core.ConditionFalse && cond.Reason == "InvalidSpec" {
+			return cond.Message
+		}
+	}
+	return ""
+}
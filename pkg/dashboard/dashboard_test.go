@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type tokenReviews struct {
+	token string
+}
+
+func (t *tokenReviews) Create(_ context.Context, review *authenticationv1.TokenReview, _ meta.CreateOptions) (*authenticationv1.TokenReview, error) {
+	review = review.DeepCopy()
+	review.Status.Authenticated = review.Spec.Token == t.token
+	review.Status.User.Username = "viewer"
+	return review, nil
+}
+
+type accessReviews struct {
+	allowed bool
+	reviews []*authorizationv1.SubjectAccessReview
+}
+
+func (a *accessReviews) Create(_ context.Context, review *authorizationv1.SubjectAccessReview, _ meta.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	a.reviews = append(a.reviews, review)
+	review = review.DeepCopy()
+	review.Status.Allowed = a.allowed
+	return review, nil
+}
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+	failed := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	failed.Status.Conditions = []v1.HelmChartCondition{{Type: v1.HelmChartFailed, Status: core.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	installed := v1.NewHelmChart("default", "nginx", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "nginx"}})
+	charts := func(namespace string) ([]*v1.HelmChart, error) {
+		return []*v1.HelmChart{failed, installed}, nil
+	}
+
+	access := &accessReviews{allowed: true}
+	handler := Handler(&tokenReviews{token: "secret"}, access, charts)
+	serve := func(token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, Path+"?namespace=kube-system", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	assert.Equal(http.StatusUnauthorized, serve("").Code)
+	assert.Equal(http.StatusUnauthorized, serve("wrong").Code)
+
+	recorder := serve("secret")
+	assert.Equal(http.StatusOK, recorder.Code)
+	assert.Equal("kube-system", access.reviews[0].Spec.ResourceAttributes.Namespace)
+	assert.Equal("viewer", access.reviews[0].Spec.User)
+	var states []ChartState
+	assert.NoError(json.Unmarshal(recorder.Body.Bytes(), &states))
+	assert.Len(states, 2)
+	assert.Equal("nginx", states[0].Name)
+	assert.Equal("BackoffLimitExceeded", states[1].LastError)
+
+	access.allowed = false
+	assert.Equal(http.StatusForbidden, serve("secret").Code)
+}
+
+func TestListenAndServeTLSFiles(t *testing.T) {
+	assert := assert.New(t)
+	for _, files := range [][2]string{{"tls.crt", ""}, {"", "tls.key"}} {
+		err := ListenAndServe(context.Background(), nil, nil, "127.0.0.1:0", files[0], files[1])
+		assert.EqualError(err, "both a certificate file and a key file must be given to serve the dashboard over TLS")
+	}
+}
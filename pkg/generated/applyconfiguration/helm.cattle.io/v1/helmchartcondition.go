@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HelmChartConditionApplyConfiguration represents an declarative configuration of the HelmChartCondition type for use
+// with apply.
+type HelmChartConditionApplyConfiguration struct {
+	Type           *v1.HelmChartConditionType `json:"type,omitempty"`
+	Status         *corev1.ConditionStatus    `json:"status,omitempty"`
+	LastUpdateTime *string                    `json:"lastUpdateTime,omitempty"`
+	Reason         *string                    `json:"reason,omitempty"`
+	Message        *string                    `json:"message,omitempty"`
+}
+
+// HelmChartConditionApplyConfiguration constructs an declarative configuration of the HelmChartCondition type for use with
+// apply.
+func HelmChartCondition() *HelmChartConditionApplyConfiguration {
+	return &HelmChartConditionApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *HelmChartConditionApplyConfiguration) WithType(value v1.HelmChartConditionType) *HelmChartConditionApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *HelmChartConditionApplyConfiguration) WithStatus(value corev1.ConditionStatus) *HelmChartConditionApplyConfiguration {
+	b.Status = &value
+	return b
+}
+
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *HelmChartConditionApplyConfiguration) WithLastUpdateTime(value string) *HelmChartConditionApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *HelmChartConditionApplyConfiguration) WithReason(value string) *HelmChartConditionApplyConfiguration {
+	b.Reason = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *HelmChartConditionApplyConfiguration) WithMessage(value string) *HelmChartConditionApplyConfiguration {
+	b.Message = &value
+	return b
+}
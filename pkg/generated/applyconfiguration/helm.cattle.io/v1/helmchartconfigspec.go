@@ -0,0 +1,48 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartConfigSpecApplyConfiguration represents an declarative configuration of the HelmChartConfigSpec type for use
+// with apply.
+type HelmChartConfigSpecApplyConfiguration struct {
+	ValuesContent *string `json:"valuesContent,omitempty"`
+	FailurePolicy *string `json:"failurePolicy,omitempty"`
+}
+
+// HelmChartConfigSpecApplyConfiguration constructs an declarative configuration of the HelmChartConfigSpec type for use with
+// apply.
+func HelmChartConfigSpec() *HelmChartConfigSpecApplyConfiguration {
+	return &HelmChartConfigSpecApplyConfiguration{}
+}
+
+// WithValuesContent sets the ValuesContent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ValuesContent field is set to the value of the last call.
+func (b *HelmChartConfigSpecApplyConfiguration) WithValuesContent(value string) *HelmChartConfigSpecApplyConfiguration {
+	b.ValuesContent = &value
+	return b
+}
+
+// WithFailurePolicy sets the FailurePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailurePolicy field is set to the value of the last call.
+func (b *HelmChartConfigSpecApplyConfiguration) WithFailurePolicy(value string) *HelmChartConfigSpecApplyConfiguration {
+	b.FailurePolicy = &value
+	return b
+}
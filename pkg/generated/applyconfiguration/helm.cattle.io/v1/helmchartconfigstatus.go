@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartConfigStatusApplyConfiguration represents an declarative configuration of the HelmChartConfigStatus type for use
+// with apply.
+type HelmChartConfigStatusApplyConfiguration struct {
+	ObservedGeneration *int64                                 `json:"observedGeneration,omitempty"`
+	TargetChartFound   *bool                                  `json:"targetChartFound,omitempty"`
+	Applied            *bool                                  `json:"applied,omitempty"`
+	LastAppliedHash    *string                                `json:"lastAppliedHash,omitempty"`
+	Conditions         []HelmChartConditionApplyConfiguration `json:"conditions,omitempty"`
+}
+
+// HelmChartConfigStatusApplyConfiguration constructs an declarative configuration of the HelmChartConfigStatus type for use with
+// apply.
+func HelmChartConfigStatus() *HelmChartConfigStatusApplyConfiguration {
+	return &HelmChartConfigStatusApplyConfiguration{}
+}
+
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *HelmChartConfigStatusApplyConfiguration) WithObservedGeneration(value int64) *HelmChartConfigStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
+// WithTargetChartFound sets the TargetChartFound field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetChartFound field is set to the value of the last call.
+func (b *HelmChartConfigStatusApplyConfiguration) WithTargetChartFound(value bool) *HelmChartConfigStatusApplyConfiguration {
+	b.TargetChartFound = &value
+	return b
+}
+
+// WithApplied sets the Applied field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Applied field is set to the value of the last call.
+func (b *HelmChartConfigStatusApplyConfiguration) WithApplied(value bool) *HelmChartConfigStatusApplyConfiguration {
+	b.Applied = &value
+	return b
+}
+
+// WithLastAppliedHash sets the LastAppliedHash field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastAppliedHash field is set to the value of the last call.
+func (b *HelmChartConfigStatusApplyConfiguration) WithLastAppliedHash(value string) *HelmChartConfigStatusApplyConfiguration {
+	b.LastAppliedHash = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *HelmChartConfigStatusApplyConfiguration) WithConditions(values ...*HelmChartConditionApplyConfiguration) *HelmChartConfigStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
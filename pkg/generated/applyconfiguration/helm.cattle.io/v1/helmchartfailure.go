@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartFailureApplyConfiguration represents an declarative configuration of the HelmChartFailure type for use
+// with apply.
+type HelmChartFailureApplyConfiguration struct {
+	Name           *string `json:"name,omitempty"`
+	Reason         *string `json:"reason,omitempty"`
+	Message        *string `json:"message,omitempty"`
+	LastUpdateTime *string `json:"lastUpdateTime,omitempty"`
+}
+
+// HelmChartFailureApplyConfiguration constructs an declarative configuration of the HelmChartFailure type for use with
+// apply.
+func HelmChartFailure() *HelmChartFailureApplyConfiguration {
+	return &HelmChartFailureApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *HelmChartFailureApplyConfiguration) WithName(value string) *HelmChartFailureApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *HelmChartFailureApplyConfiguration) WithReason(value string) *HelmChartFailureApplyConfiguration {
+	b.Reason = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *HelmChartFailureApplyConfiguration) WithMessage(value string) *HelmChartFailureApplyConfiguration {
+	b.Message = &value
+	return b
+}
+
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *HelmChartFailureApplyConfiguration) WithLastUpdateTime(value string) *HelmChartFailureApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
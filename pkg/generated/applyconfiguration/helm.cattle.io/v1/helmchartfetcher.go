@@ -0,0 +1,79 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// HelmChartFetcherApplyConfiguration represents an declarative configuration of the HelmChartFetcher type for use
+// with apply.
+type HelmChartFetcherApplyConfiguration struct {
+	Image   *string                       `json:"image,omitempty"`
+	Command []string                      `json:"command,omitempty"`
+	Args    []string                      `json:"args,omitempty"`
+	Env     []v1.EnvVarApplyConfiguration `json:"env,omitempty"`
+}
+
+// HelmChartFetcherApplyConfiguration constructs an declarative configuration of the HelmChartFetcher type for use with
+// apply.
+func HelmChartFetcher() *HelmChartFetcherApplyConfiguration {
+	return &HelmChartFetcherApplyConfiguration{}
+}
+
+// WithImage sets the Image field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Image field is set to the value of the last call.
+func (b *HelmChartFetcherApplyConfiguration) WithImage(value string) *HelmChartFetcherApplyConfiguration {
+	b.Image = &value
+	return b
+}
+
+// WithCommand adds the given value to the Command field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Command field.
+func (b *HelmChartFetcherApplyConfiguration) WithCommand(values ...string) *HelmChartFetcherApplyConfiguration {
+	for i := range values {
+		b.Command = append(b.Command, values[i])
+	}
+	return b
+}
+
+// WithArgs adds the given value to the Args field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Args field.
+func (b *HelmChartFetcherApplyConfiguration) WithArgs(values ...string) *HelmChartFetcherApplyConfiguration {
+	for i := range values {
+		b.Args = append(b.Args, values[i])
+	}
+	return b
+}
+
+// WithEnv adds the given value to the Env field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Env field.
+func (b *HelmChartFetcherApplyConfiguration) WithEnv(values ...*v1.EnvVarApplyConfiguration) *HelmChartFetcherApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithEnv")
+		}
+		b.Env = append(b.Env, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartNamespaceSummaryApplyConfiguration represents an declarative configuration of the HelmChartNamespaceSummary type for use
+// with apply.
+type HelmChartNamespaceSummaryApplyConfiguration struct {
+	Namespace    *string                              `json:"namespace,omitempty"`
+	Charts       *int                                 `json:"charts,omitempty"`
+	Ready        *int                                 `json:"ready,omitempty"`
+	Failed       *int                                 `json:"failed,omitempty"`
+	LastFailures []HelmChartFailureApplyConfiguration `json:"lastFailures,omitempty"`
+}
+
+// HelmChartNamespaceSummaryApplyConfiguration constructs an declarative configuration of the HelmChartNamespaceSummary type for use with
+// apply.
+func HelmChartNamespaceSummary() *HelmChartNamespaceSummaryApplyConfiguration {
+	return &HelmChartNamespaceSummaryApplyConfiguration{}
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *HelmChartNamespaceSummaryApplyConfiguration) WithNamespace(value string) *HelmChartNamespaceSummaryApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithCharts sets the Charts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Charts field is set to the value of the last call.
+func (b *HelmChartNamespaceSummaryApplyConfiguration) WithCharts(value int) *HelmChartNamespaceSummaryApplyConfiguration {
+	b.Charts = &value
+	return b
+}
+
+// WithReady sets the Ready field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ready field is set to the value of the last call.
+func (b *HelmChartNamespaceSummaryApplyConfiguration) WithReady(value int) *HelmChartNamespaceSummaryApplyConfiguration {
+	b.Ready = &value
+	return b
+}
+
+// WithFailed sets the Failed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Failed field is set to the value of the last call.
+func (b *HelmChartNamespaceSummaryApplyConfiguration) WithFailed(value int) *HelmChartNamespaceSummaryApplyConfiguration {
+	b.Failed = &value
+	return b
+}
+
+// WithLastFailures adds the given value to the LastFailures field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the LastFailures field.
+func (b *HelmChartNamespaceSummaryApplyConfiguration) WithLastFailures(values ...*HelmChartFailureApplyConfiguration) *HelmChartNamespaceSummaryApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithLastFailures")
+		}
+		b.LastFailures = append(b.LastFailures, *values[i])
+	}
+	return b
+}
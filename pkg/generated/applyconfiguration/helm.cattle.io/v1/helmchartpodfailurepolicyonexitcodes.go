@@ -0,0 +1,59 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartPodFailurePolicyOnExitCodesApplyConfiguration represents an declarative configuration of the HelmChartPodFailurePolicyOnExitCodes type for use
+// with apply.
+type HelmChartPodFailurePolicyOnExitCodesApplyConfiguration struct {
+	ContainerName *string `json:"containerName,omitempty"`
+	Operator      *string `json:"operator,omitempty"`
+	Values        []int32 `json:"values,omitempty"`
+}
+
+// HelmChartPodFailurePolicyOnExitCodesApplyConfiguration constructs an declarative configuration of the HelmChartPodFailurePolicyOnExitCodes type for use with
+// apply.
+func HelmChartPodFailurePolicyOnExitCodes() *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration {
+	return &HelmChartPodFailurePolicyOnExitCodesApplyConfiguration{}
+}
+
+// WithContainerName sets the ContainerName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ContainerName field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration) WithContainerName(value string) *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration {
+	b.ContainerName = &value
+	return b
+}
+
+// WithOperator sets the Operator field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Operator field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration) WithOperator(value string) *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration {
+	b.Operator = &value
+	return b
+}
+
+// WithValues adds the given value to the Values field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Values field.
+func (b *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration) WithValues(values ...int32) *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration {
+	for i := range values {
+		b.Values = append(b.Values, values[i])
+	}
+	return b
+}
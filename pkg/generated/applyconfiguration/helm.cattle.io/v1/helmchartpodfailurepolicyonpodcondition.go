@@ -0,0 +1,48 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartPodFailurePolicyOnPodConditionApplyConfiguration represents an declarative configuration of the HelmChartPodFailurePolicyOnPodCondition type for use
+// with apply.
+type HelmChartPodFailurePolicyOnPodConditionApplyConfiguration struct {
+	Type   *string `json:"type,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+// HelmChartPodFailurePolicyOnPodConditionApplyConfiguration constructs an declarative configuration of the HelmChartPodFailurePolicyOnPodCondition type for use with
+// apply.
+func HelmChartPodFailurePolicyOnPodCondition() *HelmChartPodFailurePolicyOnPodConditionApplyConfiguration {
+	return &HelmChartPodFailurePolicyOnPodConditionApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyOnPodConditionApplyConfiguration) WithType(value string) *HelmChartPodFailurePolicyOnPodConditionApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithStatus sets the Status field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Status field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyOnPodConditionApplyConfiguration) WithStatus(value string) *HelmChartPodFailurePolicyOnPodConditionApplyConfiguration {
+	b.Status = &value
+	return b
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartPodFailurePolicyRuleApplyConfiguration represents an declarative configuration of the HelmChartPodFailurePolicyRule type for use
+// with apply.
+type HelmChartPodFailurePolicyRuleApplyConfiguration struct {
+	Action          *string                                                     `json:"action,omitempty"`
+	OnExitCodes     *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration     `json:"onExitCodes,omitempty"`
+	OnPodConditions []HelmChartPodFailurePolicyOnPodConditionApplyConfiguration `json:"onPodConditions,omitempty"`
+}
+
+// HelmChartPodFailurePolicyRuleApplyConfiguration constructs an declarative configuration of the HelmChartPodFailurePolicyRule type for use with
+// apply.
+func HelmChartPodFailurePolicyRule() *HelmChartPodFailurePolicyRuleApplyConfiguration {
+	return &HelmChartPodFailurePolicyRuleApplyConfiguration{}
+}
+
+// WithAction sets the Action field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Action field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyRuleApplyConfiguration) WithAction(value string) *HelmChartPodFailurePolicyRuleApplyConfiguration {
+	b.Action = &value
+	return b
+}
+
+// WithOnExitCodes sets the OnExitCodes field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the OnExitCodes field is set to the value of the last call.
+func (b *HelmChartPodFailurePolicyRuleApplyConfiguration) WithOnExitCodes(value *HelmChartPodFailurePolicyOnExitCodesApplyConfiguration) *HelmChartPodFailurePolicyRuleApplyConfiguration {
+	b.OnExitCodes = value
+	return b
+}
+
+// WithOnPodConditions adds the given value to the OnPodConditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the OnPodConditions field.
+func (b *HelmChartPodFailurePolicyRuleApplyConfiguration) WithOnPodConditions(values ...*HelmChartPodFailurePolicyOnPodConditionApplyConfiguration) *HelmChartPodFailurePolicyRuleApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithOnPodConditions")
+		}
+		b.OnPodConditions = append(b.OnPodConditions, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// HelmChartSetFileApplyConfiguration represents an declarative configuration of the HelmChartSetFile type for use
+// with apply.
+type HelmChartSetFileApplyConfiguration struct {
+	Key             *string                                    `json:"key,omitempty"`
+	ConfigMapKeyRef *v1.ConfigMapKeySelectorApplyConfiguration `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *v1.SecretKeySelectorApplyConfiguration    `json:"secretKeyRef,omitempty"`
+}
+
+// HelmChartSetFileApplyConfiguration constructs an declarative configuration of the HelmChartSetFile type for use with
+// apply.
+func HelmChartSetFile() *HelmChartSetFileApplyConfiguration {
+	return &HelmChartSetFileApplyConfiguration{}
+}
+
+// WithKey sets the Key field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Key field is set to the value of the last call.
+func (b *HelmChartSetFileApplyConfiguration) WithKey(value string) *HelmChartSetFileApplyConfiguration {
+	b.Key = &value
+	return b
+}
+
+// WithConfigMapKeyRef sets the ConfigMapKeyRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConfigMapKeyRef field is set to the value of the last call.
+func (b *HelmChartSetFileApplyConfiguration) WithConfigMapKeyRef(value *v1.ConfigMapKeySelectorApplyConfiguration) *HelmChartSetFileApplyConfiguration {
+	b.ConfigMapKeyRef = value
+	return b
+}
+
+// WithSecretKeyRef sets the SecretKeyRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SecretKeyRef field is set to the value of the last call.
+func (b *HelmChartSetFileApplyConfiguration) WithSecretKeyRef(value *v1.SecretKeySelectorApplyConfiguration) *HelmChartSetFileApplyConfiguration {
+	b.SecretKeyRef = value
+	return b
+}
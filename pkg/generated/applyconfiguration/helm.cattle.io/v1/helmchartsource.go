@@ -0,0 +1,75 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartSourceApplyConfiguration represents an declarative configuration of the HelmChartSource type for use
+// with apply.
+type HelmChartSourceApplyConfiguration struct {
+	Repo          *string `json:"repo,omitempty"`
+	Chart         *string `json:"chart,omitempty"`
+	Version       *string `json:"version,omitempty"`
+	ArchiveDigest *string `json:"archiveDigest,omitempty"`
+	ChartDigest   *string `json:"chartDigest,omitempty"`
+}
+
+// HelmChartSourceApplyConfiguration constructs an declarative configuration of the HelmChartSource type for use with
+// apply.
+func HelmChartSource() *HelmChartSourceApplyConfiguration {
+	return &HelmChartSourceApplyConfiguration{}
+}
+
+// WithRepo sets the Repo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Repo field is set to the value of the last call.
+func (b *HelmChartSourceApplyConfiguration) WithRepo(value string) *HelmChartSourceApplyConfiguration {
+	b.Repo = &value
+	return b
+}
+
+// WithChart sets the Chart field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Chart field is set to the value of the last call.
+func (b *HelmChartSourceApplyConfiguration) WithChart(value string) *HelmChartSourceApplyConfiguration {
+	b.Chart = &value
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *HelmChartSourceApplyConfiguration) WithVersion(value string) *HelmChartSourceApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithArchiveDigest sets the ArchiveDigest field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ArchiveDigest field is set to the value of the last call.
+func (b *HelmChartSourceApplyConfiguration) WithArchiveDigest(value string) *HelmChartSourceApplyConfiguration {
+	b.ArchiveDigest = &value
+	return b
+}
+
+// WithChartDigest sets the ChartDigest field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartDigest field is set to the value of the last call.
+func (b *HelmChartSourceApplyConfiguration) WithChartDigest(value string) *HelmChartSourceApplyConfiguration {
+	b.ChartDigest = &value
+	return b
+}
@@ -0,0 +1,729 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// HelmChartSpecApplyConfiguration represents an declarative configuration of the HelmChartSpec type for use
+// with apply.
+type HelmChartSpecApplyConfiguration struct {
+	TargetNamespace          *string                                        `json:"targetNamespace,omitempty"`
+	Chart                    *string                                        `json:"chart,omitempty"`
+	Version                  *string                                        `json:"version,omitempty"`
+	Repo                     *string                                        `json:"repo,omitempty"`
+	RepoCA                   *string                                        `json:"repoCA,omitempty"`
+	Set                      map[string]intstr.IntOrString                  `json:"set,omitempty"`
+	ValuesContent            *string                                        `json:"valuesContent,omitempty"`
+	HelmVersion              *string                                        `json:"helmVersion,omitempty"`
+	Bootstrap                *bool                                          `json:"bootstrap,omitempty"`
+	ChartContent             *string                                        `json:"chartContent,omitempty"`
+	JobImage                 *string                                        `json:"jobImage,omitempty"`
+	Timeout                  *v1.Duration                                   `json:"timeout,omitempty"`
+	FailurePolicy            *string                                        `json:"failurePolicy,omitempty"`
+	AuthSecret               *corev1.LocalObjectReferenceApplyConfiguration `json:"authSecret,omitempty"`
+	CredentialsMountMode     *string                                        `json:"credentialsMountMode,omitempty"`
+	GeneratedLabels          map[string]string                              `json:"generatedLabels,omitempty"`
+	GeneratedAnnotations     map[string]string                              `json:"generatedAnnotations,omitempty"`
+	ChartContentSecret       *corev1.LocalObjectReferenceApplyConfiguration `json:"chartContentSecret,omitempty"`
+	DisableSidecars          *bool                                          `json:"disableSidecars,omitempty"`
+	GenerateNetworkPolicy    *bool                                          `json:"generateNetworkPolicy,omitempty"`
+	ProxySecret              *corev1.LocalObjectReferenceApplyConfiguration `json:"proxySecret,omitempty"`
+	TolerateUnschedulable    *bool                                          `json:"tolerateUnschedulable,omitempty"`
+	FailurePolicyRetries     *int32                                         `json:"failurePolicyRetries,omitempty"`
+	HostAliases              []corev1.HostAliasApplyConfiguration           `json:"hostAliases,omitempty"`
+	TargetNamespacePolicy    *string                                        `json:"targetNamespacePolicy,omitempty"`
+	ReleaseName              *string                                        `json:"releaseName,omitempty"`
+	KeepResourcesOnRelocate  *bool                                          `json:"keepResourcesOnRelocate,omitempty"`
+	HelmChartConfigRef       *HelmChartConfigReferenceApplyConfiguration    `json:"helmChartConfigRef,omitempty"`
+	SpreadJobs               *bool                                          `json:"spreadJobs,omitempty"`
+	SetJSON                  map[string]string                              `json:"setJSON,omitempty"`
+	SetFiles                 []HelmChartSetFileApplyConfiguration           `json:"setFiles,omitempty"`
+	OrphanPolicy             *string                                        `json:"orphanPolicy,omitempty"`
+	JobEnvFrom               []corev1.EnvFromSourceApplyConfiguration       `json:"jobEnvFrom,omitempty"`
+	CABundle                 *corev1.ConfigMapKeySelectorApplyConfiguration `json:"caBundle,omitempty"`
+	JobNodeSelector          map[string]string                              `json:"jobNodeSelector,omitempty"`
+	JobTolerations           []corev1.TolerationApplyConfiguration          `json:"jobTolerations,omitempty"`
+	RepoServiceAccountAuth   *bool                                          `json:"repoServiceAccountAuth,omitempty"`
+	ExpectedDuration         *v1.Duration                                   `json:"expectedDuration,omitempty"`
+	Devel                    *bool                                          `json:"devel,omitempty"`
+	HelmPlugins              []HelmPluginApplyConfiguration                 `json:"helmPlugins,omitempty"`
+	RepoStorageSecret        *corev1.LocalObjectReferenceApplyConfiguration `json:"repoStorageSecret,omitempty"`
+	ChartChecksum            *string                                        `json:"chartChecksum,omitempty"`
+	ChartFetcher             *HelmChartFetcherApplyConfiguration            `json:"chartFetcher,omitempty"`
+	CreateRBAC               *bool                                          `json:"createRBAC,omitempty"`
+	ServiceAccountName       *string                                        `json:"serviceAccountName,omitempty"`
+	ReleaseSecretLabels      map[string]string                              `json:"releaseSecretLabels,omitempty"`
+	ReleaseSecretAnnotations map[string]string                              `json:"releaseSecretAnnotations,omitempty"`
+	EventPolicy              *string                                        `json:"eventPolicy,omitempty"`
+	DisableSecurityContext   *bool                                          `json:"disableSecurityContext,omitempty"`
+	Description              *string                                        `json:"description,omitempty"`
+	DisableHooks             *bool                                          `json:"disableHooks,omitempty"`
+	HelmTimeout              *v1.Duration                                   `json:"helmTimeout,omitempty"`
+	RestartPolicy            *string                                        `json:"restartPolicy,omitempty"`
+	PodFailurePolicy         *HelmChartPodFailurePolicyApplyConfiguration   `json:"podFailurePolicy,omitempty"`
+	SetFieldRefs             map[string]string                              `json:"setFieldRefs,omitempty"`
+	UninstallWait            *bool                                          `json:"uninstallWait,omitempty"`
+	CreateNamespace          *bool                                          `json:"createNamespace,omitempty"`
+	NodeName                 *string                                        `json:"nodeName,omitempty"`
+	Force                    *bool                                          `json:"force,omitempty"`
+	TargetContext            *string                                        `json:"targetContext,omitempty"`
+	SetFrom                  []HelmChartSetFromApplyConfiguration           `json:"setFrom,omitempty"`
+	CommonLabels             map[string]string                              `json:"commonLabels,omitempty"`
+	CommonAnnotations        map[string]string                              `json:"commonAnnotations,omitempty"`
+	JobSuspend               *bool                                          `json:"jobSuspend,omitempty"`
+	SafeToEvict              *bool                                          `json:"safeToEvict,omitempty"`
+	UninstallTimeout         *v1.Duration                                   `json:"uninstallTimeout,omitempty"`
+	BootstrapWeight          *int                                           `json:"bootstrapWeight,omitempty"`
+}
+
+// HelmChartSpecApplyConfiguration constructs an declarative configuration of the HelmChartSpec type for use with
+// apply.
+func HelmChartSpec() *HelmChartSpecApplyConfiguration {
+	return &HelmChartSpecApplyConfiguration{}
+}
+
+// WithTargetNamespace sets the TargetNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetNamespace field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithTargetNamespace(value string) *HelmChartSpecApplyConfiguration {
+	b.TargetNamespace = &value
+	return b
+}
+
+// WithChart sets the Chart field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Chart field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithChart(value string) *HelmChartSpecApplyConfiguration {
+	b.Chart = &value
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithVersion(value string) *HelmChartSpecApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithRepo sets the Repo field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Repo field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithRepo(value string) *HelmChartSpecApplyConfiguration {
+	b.Repo = &value
+	return b
+}
+
+// WithRepoCA sets the RepoCA field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RepoCA field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithRepoCA(value string) *HelmChartSpecApplyConfiguration {
+	b.RepoCA = &value
+	return b
+}
+
+// WithSet puts the entries into the Set field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Set field,
+// overwriting an existing map entries in Set field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithSet(entries map[string]intstr.IntOrString) *HelmChartSpecApplyConfiguration {
+	if b.Set == nil && len(entries) > 0 {
+		b.Set = make(map[string]intstr.IntOrString, len(entries))
+	}
+	for k, v := range entries {
+		b.Set[k] = v
+	}
+	return b
+}
+
+// WithValuesContent sets the ValuesContent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ValuesContent field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithValuesContent(value string) *HelmChartSpecApplyConfiguration {
+	b.ValuesContent = &value
+	return b
+}
+
+// WithHelmVersion sets the HelmVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HelmVersion field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithHelmVersion(value string) *HelmChartSpecApplyConfiguration {
+	b.HelmVersion = &value
+	return b
+}
+
+// WithBootstrap sets the Bootstrap field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bootstrap field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithBootstrap(value bool) *HelmChartSpecApplyConfiguration {
+	b.Bootstrap = &value
+	return b
+}
+
+// WithChartContent sets the ChartContent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartContent field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithChartContent(value string) *HelmChartSpecApplyConfiguration {
+	b.ChartContent = &value
+	return b
+}
+
+// WithJobImage sets the JobImage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JobImage field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithJobImage(value string) *HelmChartSpecApplyConfiguration {
+	b.JobImage = &value
+	return b
+}
+
+// WithTimeout sets the Timeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Timeout field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithTimeout(value v1.Duration) *HelmChartSpecApplyConfiguration {
+	b.Timeout = &value
+	return b
+}
+
+// WithFailurePolicy sets the FailurePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailurePolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithFailurePolicy(value string) *HelmChartSpecApplyConfiguration {
+	b.FailurePolicy = &value
+	return b
+}
+
+// WithAuthSecret sets the AuthSecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AuthSecret field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithAuthSecret(value *corev1.LocalObjectReferenceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.AuthSecret = value
+	return b
+}
+
+// WithCredentialsMountMode sets the CredentialsMountMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CredentialsMountMode field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithCredentialsMountMode(value string) *HelmChartSpecApplyConfiguration {
+	b.CredentialsMountMode = &value
+	return b
+}
+
+// WithGeneratedLabels puts the entries into the GeneratedLabels field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the GeneratedLabels field,
+// overwriting an existing map entries in GeneratedLabels field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithGeneratedLabels(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.GeneratedLabels == nil && len(entries) > 0 {
+		b.GeneratedLabels = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.GeneratedLabels[k] = v
+	}
+	return b
+}
+
+// WithGeneratedAnnotations puts the entries into the GeneratedAnnotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the GeneratedAnnotations field,
+// overwriting an existing map entries in GeneratedAnnotations field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithGeneratedAnnotations(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.GeneratedAnnotations == nil && len(entries) > 0 {
+		b.GeneratedAnnotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.GeneratedAnnotations[k] = v
+	}
+	return b
+}
+
+// WithChartContentSecret sets the ChartContentSecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartContentSecret field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithChartContentSecret(value *corev1.LocalObjectReferenceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.ChartContentSecret = value
+	return b
+}
+
+// WithDisableSidecars sets the DisableSidecars field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DisableSidecars field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithDisableSidecars(value bool) *HelmChartSpecApplyConfiguration {
+	b.DisableSidecars = &value
+	return b
+}
+
+// WithGenerateNetworkPolicy sets the GenerateNetworkPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GenerateNetworkPolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithGenerateNetworkPolicy(value bool) *HelmChartSpecApplyConfiguration {
+	b.GenerateNetworkPolicy = &value
+	return b
+}
+
+// WithProxySecret sets the ProxySecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ProxySecret field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithProxySecret(value *corev1.LocalObjectReferenceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.ProxySecret = value
+	return b
+}
+
+// WithTolerateUnschedulable sets the TolerateUnschedulable field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TolerateUnschedulable field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithTolerateUnschedulable(value bool) *HelmChartSpecApplyConfiguration {
+	b.TolerateUnschedulable = &value
+	return b
+}
+
+// WithFailurePolicyRetries sets the FailurePolicyRetries field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailurePolicyRetries field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithFailurePolicyRetries(value int32) *HelmChartSpecApplyConfiguration {
+	b.FailurePolicyRetries = &value
+	return b
+}
+
+// WithHostAliases adds the given value to the HostAliases field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the HostAliases field.
+func (b *HelmChartSpecApplyConfiguration) WithHostAliases(values ...*corev1.HostAliasApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithHostAliases")
+		}
+		b.HostAliases = append(b.HostAliases, *values[i])
+	}
+	return b
+}
+
+// WithTargetNamespacePolicy sets the TargetNamespacePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetNamespacePolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithTargetNamespacePolicy(value string) *HelmChartSpecApplyConfiguration {
+	b.TargetNamespacePolicy = &value
+	return b
+}
+
+// WithReleaseName sets the ReleaseName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReleaseName field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithReleaseName(value string) *HelmChartSpecApplyConfiguration {
+	b.ReleaseName = &value
+	return b
+}
+
+// WithKeepResourcesOnRelocate sets the KeepResourcesOnRelocate field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the KeepResourcesOnRelocate field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithKeepResourcesOnRelocate(value bool) *HelmChartSpecApplyConfiguration {
+	b.KeepResourcesOnRelocate = &value
+	return b
+}
+
+// WithHelmChartConfigRef sets the HelmChartConfigRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HelmChartConfigRef field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithHelmChartConfigRef(value *HelmChartConfigReferenceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.HelmChartConfigRef = value
+	return b
+}
+
+// WithSpreadJobs sets the SpreadJobs field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SpreadJobs field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithSpreadJobs(value bool) *HelmChartSpecApplyConfiguration {
+	b.SpreadJobs = &value
+	return b
+}
+
+// WithSetJSON puts the entries into the SetJSON field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the SetJSON field,
+// overwriting an existing map entries in SetJSON field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithSetJSON(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.SetJSON == nil && len(entries) > 0 {
+		b.SetJSON = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.SetJSON[k] = v
+	}
+	return b
+}
+
+// WithSetFiles adds the given value to the SetFiles field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the SetFiles field.
+func (b *HelmChartSpecApplyConfiguration) WithSetFiles(values ...*HelmChartSetFileApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSetFiles")
+		}
+		b.SetFiles = append(b.SetFiles, *values[i])
+	}
+	return b
+}
+
+// WithOrphanPolicy sets the OrphanPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the OrphanPolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithOrphanPolicy(value string) *HelmChartSpecApplyConfiguration {
+	b.OrphanPolicy = &value
+	return b
+}
+
+// WithJobEnvFrom adds the given value to the JobEnvFrom field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the JobEnvFrom field.
+func (b *HelmChartSpecApplyConfiguration) WithJobEnvFrom(values ...*corev1.EnvFromSourceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithJobEnvFrom")
+		}
+		b.JobEnvFrom = append(b.JobEnvFrom, *values[i])
+	}
+	return b
+}
+
+// WithCABundle sets the CABundle field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CABundle field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithCABundle(value *corev1.ConfigMapKeySelectorApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.CABundle = value
+	return b
+}
+
+// WithJobNodeSelector puts the entries into the JobNodeSelector field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the JobNodeSelector field,
+// overwriting an existing map entries in JobNodeSelector field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithJobNodeSelector(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.JobNodeSelector == nil && len(entries) > 0 {
+		b.JobNodeSelector = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.JobNodeSelector[k] = v
+	}
+	return b
+}
+
+// WithJobTolerations adds the given value to the JobTolerations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the JobTolerations field.
+func (b *HelmChartSpecApplyConfiguration) WithJobTolerations(values ...*corev1.TolerationApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithJobTolerations")
+		}
+		b.JobTolerations = append(b.JobTolerations, *values[i])
+	}
+	return b
+}
+
+// WithRepoServiceAccountAuth sets the RepoServiceAccountAuth field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RepoServiceAccountAuth field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithRepoServiceAccountAuth(value bool) *HelmChartSpecApplyConfiguration {
+	b.RepoServiceAccountAuth = &value
+	return b
+}
+
+// WithExpectedDuration sets the ExpectedDuration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExpectedDuration field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithExpectedDuration(value v1.Duration) *HelmChartSpecApplyConfiguration {
+	b.ExpectedDuration = &value
+	return b
+}
+
+// WithDevel sets the Devel field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Devel field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithDevel(value bool) *HelmChartSpecApplyConfiguration {
+	b.Devel = &value
+	return b
+}
+
+// WithHelmPlugins adds the given value to the HelmPlugins field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the HelmPlugins field.
+func (b *HelmChartSpecApplyConfiguration) WithHelmPlugins(values ...*HelmPluginApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithHelmPlugins")
+		}
+		b.HelmPlugins = append(b.HelmPlugins, *values[i])
+	}
+	return b
+}
+
+// WithRepoStorageSecret sets the RepoStorageSecret field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RepoStorageSecret field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithRepoStorageSecret(value *corev1.LocalObjectReferenceApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.RepoStorageSecret = value
+	return b
+}
+
+// WithChartChecksum sets the ChartChecksum field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartChecksum field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithChartChecksum(value string) *HelmChartSpecApplyConfiguration {
+	b.ChartChecksum = &value
+	return b
+}
+
+// WithChartFetcher sets the ChartFetcher field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartFetcher field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithChartFetcher(value *HelmChartFetcherApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.ChartFetcher = value
+	return b
+}
+
+// WithCreateRBAC sets the CreateRBAC field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CreateRBAC field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithCreateRBAC(value bool) *HelmChartSpecApplyConfiguration {
+	b.CreateRBAC = &value
+	return b
+}
+
+// WithServiceAccountName sets the ServiceAccountName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ServiceAccountName field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithServiceAccountName(value string) *HelmChartSpecApplyConfiguration {
+	b.ServiceAccountName = &value
+	return b
+}
+
+// WithReleaseSecretLabels puts the entries into the ReleaseSecretLabels field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ReleaseSecretLabels field,
+// overwriting an existing map entries in ReleaseSecretLabels field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithReleaseSecretLabels(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.ReleaseSecretLabels == nil && len(entries) > 0 {
+		b.ReleaseSecretLabels = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.ReleaseSecretLabels[k] = v
+	}
+	return b
+}
+
+// WithReleaseSecretAnnotations puts the entries into the ReleaseSecretAnnotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the ReleaseSecretAnnotations field,
+// overwriting an existing map entries in ReleaseSecretAnnotations field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithReleaseSecretAnnotations(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.ReleaseSecretAnnotations == nil && len(entries) > 0 {
+		b.ReleaseSecretAnnotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.ReleaseSecretAnnotations[k] = v
+	}
+	return b
+}
+
+// WithEventPolicy sets the EventPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EventPolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithEventPolicy(value string) *HelmChartSpecApplyConfiguration {
+	b.EventPolicy = &value
+	return b
+}
+
+// WithDisableSecurityContext sets the DisableSecurityContext field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DisableSecurityContext field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithDisableSecurityContext(value bool) *HelmChartSpecApplyConfiguration {
+	b.DisableSecurityContext = &value
+	return b
+}
+
+// WithDescription sets the Description field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Description field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithDescription(value string) *HelmChartSpecApplyConfiguration {
+	b.Description = &value
+	return b
+}
+
+// WithDisableHooks sets the DisableHooks field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DisableHooks field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithDisableHooks(value bool) *HelmChartSpecApplyConfiguration {
+	b.DisableHooks = &value
+	return b
+}
+
+// WithHelmTimeout sets the HelmTimeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HelmTimeout field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithHelmTimeout(value v1.Duration) *HelmChartSpecApplyConfiguration {
+	b.HelmTimeout = &value
+	return b
+}
+
+// WithRestartPolicy sets the RestartPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RestartPolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithRestartPolicy(value string) *HelmChartSpecApplyConfiguration {
+	b.RestartPolicy = &value
+	return b
+}
+
+// WithPodFailurePolicy sets the PodFailurePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodFailurePolicy field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithPodFailurePolicy(value *HelmChartPodFailurePolicyApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	b.PodFailurePolicy = value
+	return b
+}
+
+// WithSetFieldRefs puts the entries into the SetFieldRefs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the SetFieldRefs field,
+// overwriting an existing map entries in SetFieldRefs field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithSetFieldRefs(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.SetFieldRefs == nil && len(entries) > 0 {
+		b.SetFieldRefs = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.SetFieldRefs[k] = v
+	}
+	return b
+}
+
+// WithUninstallWait sets the UninstallWait field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UninstallWait field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithUninstallWait(value bool) *HelmChartSpecApplyConfiguration {
+	b.UninstallWait = &value
+	return b
+}
+
+// WithCreateNamespace sets the CreateNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CreateNamespace field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithCreateNamespace(value bool) *HelmChartSpecApplyConfiguration {
+	b.CreateNamespace = &value
+	return b
+}
+
+// WithNodeName sets the NodeName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NodeName field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithNodeName(value string) *HelmChartSpecApplyConfiguration {
+	b.NodeName = &value
+	return b
+}
+
+// WithForce sets the Force field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Force field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithForce(value bool) *HelmChartSpecApplyConfiguration {
+	b.Force = &value
+	return b
+}
+
+// WithTargetContext sets the TargetContext field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetContext field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithTargetContext(value string) *HelmChartSpecApplyConfiguration {
+	b.TargetContext = &value
+	return b
+}
+
+// WithSetFrom adds the given value to the SetFrom field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the SetFrom field.
+func (b *HelmChartSpecApplyConfiguration) WithSetFrom(values ...*HelmChartSetFromApplyConfiguration) *HelmChartSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSetFrom")
+		}
+		b.SetFrom = append(b.SetFrom, *values[i])
+	}
+	return b
+}
+
+// WithCommonLabels puts the entries into the CommonLabels field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the CommonLabels field,
+// overwriting an existing map entries in CommonLabels field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithCommonLabels(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.CommonLabels == nil && len(entries) > 0 {
+		b.CommonLabels = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.CommonLabels[k] = v
+	}
+	return b
+}
+
+// WithCommonAnnotations puts the entries into the CommonAnnotations field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the CommonAnnotations field,
+// overwriting an existing map entries in CommonAnnotations field with the same key.
+func (b *HelmChartSpecApplyConfiguration) WithCommonAnnotations(entries map[string]string) *HelmChartSpecApplyConfiguration {
+	if b.CommonAnnotations == nil && len(entries) > 0 {
+		b.CommonAnnotations = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.CommonAnnotations[k] = v
+	}
+	return b
+}
+
+// WithJobSuspend sets the JobSuspend field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JobSuspend field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithJobSuspend(value bool) *HelmChartSpecApplyConfiguration {
+	b.JobSuspend = &value
+	return b
+}
+
+// WithSafeToEvict sets the SafeToEvict field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SafeToEvict field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithSafeToEvict(value bool) *HelmChartSpecApplyConfiguration {
+	b.SafeToEvict = &value
+	return b
+}
+
+// WithUninstallTimeout sets the UninstallTimeout field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UninstallTimeout field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithUninstallTimeout(value v1.Duration) *HelmChartSpecApplyConfiguration {
+	b.UninstallTimeout = &value
+	return b
+}
+
+// WithBootstrapWeight sets the BootstrapWeight field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BootstrapWeight field is set to the value of the last call.
+func (b *HelmChartSpecApplyConfiguration) WithBootstrapWeight(value int) *HelmChartSpecApplyConfiguration {
+	b.BootstrapWeight = &value
+	return b
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartStatusApplyConfiguration represents an declarative configuration of the HelmChartStatus type for use
+// with apply.
+type HelmChartStatusApplyConfiguration struct {
+	JobName           *string                                   `json:"jobName,omitempty"`
+	JobImage          *string                                   `json:"jobImage,omitempty"`
+	Conditions        []HelmChartConditionApplyConfiguration    `json:"conditions,omitempty"`
+	TargetNamespace   *string                                   `json:"targetNamespace,omitempty"`
+	ReleaseName       *string                                   `json:"releaseName,omitempty"`
+	OrphanedResources []string                                  `json:"orphanedResources,omitempty"`
+	ChartVersion      *string                                   `json:"chartVersion,omitempty"`
+	ReleaseRevision   *int                                      `json:"releaseRevision,omitempty"`
+	Source            *HelmChartSourceApplyConfiguration        `json:"source,omitempty"`
+	History           []HelmChartHistoryEntryApplyConfiguration `json:"history,omitempty"`
+}
+
+// HelmChartStatusApplyConfiguration constructs an declarative configuration of the HelmChartStatus type for use with
+// apply.
+func HelmChartStatus() *HelmChartStatusApplyConfiguration {
+	return &HelmChartStatusApplyConfiguration{}
+}
+
+// WithJobName sets the JobName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JobName field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithJobName(value string) *HelmChartStatusApplyConfiguration {
+	b.JobName = &value
+	return b
+}
+
+// WithJobImage sets the JobImage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the JobImage field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithJobImage(value string) *HelmChartStatusApplyConfiguration {
+	b.JobImage = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *HelmChartStatusApplyConfiguration) WithConditions(values ...*HelmChartConditionApplyConfiguration) *HelmChartStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
+
+// WithTargetNamespace sets the TargetNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TargetNamespace field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithTargetNamespace(value string) *HelmChartStatusApplyConfiguration {
+	b.TargetNamespace = &value
+	return b
+}
+
+// WithReleaseName sets the ReleaseName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReleaseName field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithReleaseName(value string) *HelmChartStatusApplyConfiguration {
+	b.ReleaseName = &value
+	return b
+}
+
+// WithOrphanedResources adds the given value to the OrphanedResources field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the OrphanedResources field.
+func (b *HelmChartStatusApplyConfiguration) WithOrphanedResources(values ...string) *HelmChartStatusApplyConfiguration {
+	for i := range values {
+		b.OrphanedResources = append(b.OrphanedResources, values[i])
+	}
+	return b
+}
+
+// WithChartVersion sets the ChartVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ChartVersion field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithChartVersion(value string) *HelmChartStatusApplyConfiguration {
+	b.ChartVersion = &value
+	return b
+}
+
+// WithReleaseRevision sets the ReleaseRevision field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReleaseRevision field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithReleaseRevision(value int) *HelmChartStatusApplyConfiguration {
+	b.ReleaseRevision = &value
+	return b
+}
+
+// WithSource sets the Source field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Source field is set to the value of the last call.
+func (b *HelmChartStatusApplyConfiguration) WithSource(value *HelmChartSourceApplyConfiguration) *HelmChartStatusApplyConfiguration {
+	b.Source = value
+	return b
+}
+
+// WithHistory adds the given value to the History field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the History field.
+func (b *HelmChartStatusApplyConfiguration) WithHistory(values ...*HelmChartHistoryEntryApplyConfiguration) *HelmChartStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithHistory")
+		}
+		b.History = append(b.History, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,71 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// HelmChartSummaryStatusApplyConfiguration represents an declarative configuration of the HelmChartSummaryStatus type for use
+// with apply.
+type HelmChartSummaryStatusApplyConfiguration struct {
+	Charts     *int                                          `json:"charts,omitempty"`
+	Ready      *int                                          `json:"ready,omitempty"`
+	Failed     *int                                          `json:"failed,omitempty"`
+	Namespaces []HelmChartNamespaceSummaryApplyConfiguration `json:"namespaces,omitempty"`
+}
+
+// HelmChartSummaryStatusApplyConfiguration constructs an declarative configuration of the HelmChartSummaryStatus type for use with
+// apply.
+func HelmChartSummaryStatus() *HelmChartSummaryStatusApplyConfiguration {
+	return &HelmChartSummaryStatusApplyConfiguration{}
+}
+
+// WithCharts sets the Charts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Charts field is set to the value of the last call.
+func (b *HelmChartSummaryStatusApplyConfiguration) WithCharts(value int) *HelmChartSummaryStatusApplyConfiguration {
+	b.Charts = &value
+	return b
+}
+
+// WithReady sets the Ready field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ready field is set to the value of the last call.
+func (b *HelmChartSummaryStatusApplyConfiguration) WithReady(value int) *HelmChartSummaryStatusApplyConfiguration {
+	b.Ready = &value
+	return b
+}
+
+// WithFailed sets the Failed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Failed field is set to the value of the last call.
+func (b *HelmChartSummaryStatusApplyConfiguration) WithFailed(value int) *HelmChartSummaryStatusApplyConfiguration {
+	b.Failed = &value
+	return b
+}
+
+// WithNamespaces adds the given value to the Namespaces field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Namespaces field.
+func (b *HelmChartSummaryStatusApplyConfiguration) WithNamespaces(values ...*HelmChartNamespaceSummaryApplyConfiguration) *HelmChartSummaryStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithNamespaces")
+		}
+		b.Namespaces = append(b.Namespaces, *values[i])
+	}
+	return b
+}
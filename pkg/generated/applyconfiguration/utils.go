@@ -0,0 +1,81 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	helmcattleiov1 "github.com/k3s-io/helm-controller/pkg/generated/applyconfiguration/helm.cattle.io/v1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=helm.cattle.io, Version=v1
+	case v1.SchemeGroupVersion.WithKind("HelmChart"):
+		return &helmcattleiov1.HelmChartApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartCondition"):
+		return &helmcattleiov1.HelmChartConditionApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartConfig"):
+		return &helmcattleiov1.HelmChartConfigApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartConfigReference"):
+		return &helmcattleiov1.HelmChartConfigReferenceApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartConfigSpec"):
+		return &helmcattleiov1.HelmChartConfigSpecApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartConfigStatus"):
+		return &helmcattleiov1.HelmChartConfigStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartFailure"):
+		return &helmcattleiov1.HelmChartFailureApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartFetcher"):
+		return &helmcattleiov1.HelmChartFetcherApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartHistoryEntry"):
+		return &helmcattleiov1.HelmChartHistoryEntryApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartNamespaceSummary"):
+		return &helmcattleiov1.HelmChartNamespaceSummaryApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartPodFailurePolicy"):
+		return &helmcattleiov1.HelmChartPodFailurePolicyApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartPodFailurePolicyOnExitCodes"):
+		return &helmcattleiov1.HelmChartPodFailurePolicyOnExitCodesApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartPodFailurePolicyOnPodCondition"):
+		return &helmcattleiov1.HelmChartPodFailurePolicyOnPodConditionApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartPodFailurePolicyRule"):
+		return &helmcattleiov1.HelmChartPodFailurePolicyRuleApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSetFile"):
+		return &helmcattleiov1.HelmChartSetFileApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSetFrom"):
+		return &helmcattleiov1.HelmChartSetFromApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSetValueSource"):
+		return &helmcattleiov1.HelmChartSetValueSourceApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSource"):
+		return &helmcattleiov1.HelmChartSourceApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSpec"):
+		return &helmcattleiov1.HelmChartSpecApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartStatus"):
+		return &helmcattleiov1.HelmChartStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSummary"):
+		return &helmcattleiov1.HelmChartSummaryApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmChartSummaryStatus"):
+		return &helmcattleiov1.HelmChartSummaryStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HelmPlugin"):
+		return &helmcattleiov1.HelmPluginApplyConfiguration{}
+
+	}
+	return nil
+}
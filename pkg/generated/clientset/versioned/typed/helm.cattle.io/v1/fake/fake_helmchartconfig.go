@@ -102,6 +102,18 @@ func (c *FakeHelmChartConfigs) Update(ctx context.Context, helmChartConfig *helm
 	return obj.(*helmcattleiov1.HelmChartConfig), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeHelmChartConfigs) UpdateStatus(ctx context.Context, helmChartConfig *helmcattleiov1.HelmChartConfig, opts v1.UpdateOptions) (*helmcattleiov1.HelmChartConfig, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(helmchartconfigsResource, "status", c.ns, helmChartConfig), &helmcattleiov1.HelmChartConfig{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartConfig), err
+}
+
 // Delete takes name of the helmChartConfig and deletes it. Returns an error if one occurs.
 func (c *FakeHelmChartConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
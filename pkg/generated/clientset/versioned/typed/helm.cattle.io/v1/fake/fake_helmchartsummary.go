@@ -0,0 +1,133 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	helmcattleiov1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeHelmChartSummaries implements HelmChartSummaryInterface
+type FakeHelmChartSummaries struct {
+	Fake *FakeHelmV1
+}
+
+var helmchartsummariesResource = schema.GroupVersionResource{Group: "helm.cattle.io", Version: "v1", Resource: "helmchartsummaries"}
+
+var helmchartsummariesKind = schema.GroupVersionKind{Group: "helm.cattle.io", Version: "v1", Kind: "HelmChartSummary"}
+
+// Get takes name of the helmChartSummary, and returns the corresponding helmChartSummary object, and an error if there is any.
+func (c *FakeHelmChartSummaries) Get(ctx context.Context, name string, options v1.GetOptions) (result *helmcattleiov1.HelmChartSummary, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(helmchartsummariesResource, name), &helmcattleiov1.HelmChartSummary{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartSummary), err
+}
+
+// List takes label and field selectors, and returns the list of HelmChartSummaries that match those selectors.
+func (c *FakeHelmChartSummaries) List(ctx context.Context, opts v1.ListOptions) (result *helmcattleiov1.HelmChartSummaryList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(helmchartsummariesResource, helmchartsummariesKind, opts), &helmcattleiov1.HelmChartSummaryList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &helmcattleiov1.HelmChartSummaryList{ListMeta: obj.(*helmcattleiov1.HelmChartSummaryList).ListMeta}
+	for _, item := range obj.(*helmcattleiov1.HelmChartSummaryList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested helmChartSummaries.
+func (c *FakeHelmChartSummaries) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(helmchartsummariesResource, opts))
+}
+
+// Create takes the representation of a helmChartSummary and creates it.  Returns the server's representation of the helmChartSummary, and an error, if there is any.
+func (c *FakeHelmChartSummaries) Create(ctx context.Context, helmChartSummary *helmcattleiov1.HelmChartSummary, opts v1.CreateOptions) (result *helmcattleiov1.HelmChartSummary, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(helmchartsummariesResource, helmChartSummary), &helmcattleiov1.HelmChartSummary{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartSummary), err
+}
+
+// Update takes the representation of a helmChartSummary and updates it. Returns the server's representation of the helmChartSummary, and an error, if there is any.
+func (c *FakeHelmChartSummaries) Update(ctx context.Context, helmChartSummary *helmcattleiov1.HelmChartSummary, opts v1.UpdateOptions) (result *helmcattleiov1.HelmChartSummary, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(helmchartsummariesResource, helmChartSummary), &helmcattleiov1.HelmChartSummary{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartSummary), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeHelmChartSummaries) UpdateStatus(ctx context.Context, helmChartSummary *helmcattleiov1.HelmChartSummary, opts v1.UpdateOptions) (*helmcattleiov1.HelmChartSummary, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(helmchartsummariesResource, "status", helmChartSummary), &helmcattleiov1.HelmChartSummary{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartSummary), err
+}
+
+// Delete takes name of the helmChartSummary and deletes it. Returns an error if one occurs.
+func (c *FakeHelmChartSummaries) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(helmchartsummariesResource, name), &helmcattleiov1.HelmChartSummary{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeHelmChartSummaries) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(helmchartsummariesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &helmcattleiov1.HelmChartSummaryList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched helmChartSummary.
+func (c *FakeHelmChartSummaries) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *helmcattleiov1.HelmChartSummary, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(helmchartsummariesResource, name, pt, data, subresources...), &helmcattleiov1.HelmChartSummary{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*helmcattleiov1.HelmChartSummary), err
+}
@@ -28,6 +28,7 @@ type HelmV1Interface interface {
 	RESTClient() rest.Interface
 	HelmChartsGetter
 	HelmChartConfigsGetter
+	HelmChartSummariesGetter
 }
 
 // HelmV1Client is used to interact with features provided by the helm.cattle.io group.
@@ -43,6 +44,10 @@ func (c *HelmV1Client) HelmChartConfigs(namespace string) HelmChartConfigInterfa
 	return newHelmChartConfigs(c, namespace)
 }
 
+func (c *HelmV1Client) HelmChartSummaries() HelmChartSummaryInterface {
+	return newHelmChartSummaries(c)
+}
+
 // NewForConfig creates a new HelmV1Client for the given config.
 func NewForConfig(c *rest.Config) (*HelmV1Client, error) {
 	config := *c
@@ -40,6 +40,7 @@ type HelmChartConfigsGetter interface {
 type HelmChartConfigInterface interface {
 	Create(ctx context.Context, helmChartConfig *v1.HelmChartConfig, opts metav1.CreateOptions) (*v1.HelmChartConfig, error)
 	Update(ctx context.Context, helmChartConfig *v1.HelmChartConfig, opts metav1.UpdateOptions) (*v1.HelmChartConfig, error)
+	UpdateStatus(ctx context.Context, helmChartConfig *v1.HelmChartConfig, opts metav1.UpdateOptions) (*v1.HelmChartConfig, error)
 	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
 	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
 	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.HelmChartConfig, error)
@@ -135,6 +136,22 @@ func (c *helmChartConfigs) Update(ctx context.Context, helmChartConfig *v1.HelmC
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *helmChartConfigs) UpdateStatus(ctx context.Context, helmChartConfig *v1.HelmChartConfig, opts metav1.UpdateOptions) (result *v1.HelmChartConfig, err error) {
+	result = &v1.HelmChartConfig{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("helmchartconfigs").
+		Name(helmChartConfig.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(helmChartConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the helmChartConfig and deletes it. Returns an error if one occurs.
 func (c *helmChartConfigs) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
 	return c.client.Delete().
@@ -0,0 +1,184 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	scheme "github.com/k3s-io/helm-controller/pkg/generated/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// HelmChartSummariesGetter has a method to return a HelmChartSummaryInterface.
+// A group's client should implement this interface.
+type HelmChartSummariesGetter interface {
+	HelmChartSummaries() HelmChartSummaryInterface
+}
+
+// HelmChartSummaryInterface has methods to work with HelmChartSummary resources.
+type HelmChartSummaryInterface interface {
+	Create(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.CreateOptions) (*v1.HelmChartSummary, error)
+	Update(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.UpdateOptions) (*v1.HelmChartSummary, error)
+	UpdateStatus(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.UpdateOptions) (*v1.HelmChartSummary, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.HelmChartSummary, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.HelmChartSummaryList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.HelmChartSummary, err error)
+	HelmChartSummaryExpansion
+}
+
+// helmChartSummaries implements HelmChartSummaryInterface
+type helmChartSummaries struct {
+	client rest.Interface
+}
+
+// newHelmChartSummaries returns a HelmChartSummaries
+func newHelmChartSummaries(c *HelmV1Client) *helmChartSummaries {
+	return &helmChartSummaries{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the helmChartSummary, and returns the corresponding helmChartSummary object, and an error if there is any.
+func (c *helmChartSummaries) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.HelmChartSummary, err error) {
+	result = &v1.HelmChartSummary{}
+	err = c.client.Get().
+		Resource("helmchartsummaries").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of HelmChartSummaries that match those selectors.
+func (c *helmChartSummaries) List(ctx context.Context, opts metav1.ListOptions) (result *v1.HelmChartSummaryList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.HelmChartSummaryList{}
+	err = c.client.Get().
+		Resource("helmchartsummaries").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested helmChartSummaries.
+func (c *helmChartSummaries) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("helmchartsummaries").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a helmChartSummary and creates it.  Returns the server's representation of the helmChartSummary, and an error, if there is any.
+func (c *helmChartSummaries) Create(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.CreateOptions) (result *v1.HelmChartSummary, err error) {
+	result = &v1.HelmChartSummary{}
+	err = c.client.Post().
+		Resource("helmchartsummaries").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(helmChartSummary).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a helmChartSummary and updates it. Returns the server's representation of the helmChartSummary, and an error, if there is any.
+func (c *helmChartSummaries) Update(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.UpdateOptions) (result *v1.HelmChartSummary, err error) {
+	result = &v1.HelmChartSummary{}
+	err = c.client.Put().
+		Resource("helmchartsummaries").
+		Name(helmChartSummary.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(helmChartSummary).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *helmChartSummaries) UpdateStatus(ctx context.Context, helmChartSummary *v1.HelmChartSummary, opts metav1.UpdateOptions) (result *v1.HelmChartSummary, err error) {
+	result = &v1.HelmChartSummary{}
+	err = c.client.Put().
+		Resource("helmchartsummaries").
+		Name(helmChartSummary.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(helmChartSummary).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the helmChartSummary and deletes it. Returns an error if one occurs.
+func (c *helmChartSummaries) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("helmchartsummaries").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *helmChartSummaries) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("helmchartsummaries").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched helmChartSummary.
+func (c *helmChartSummaries) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.HelmChartSummary, err error) {
+	result = &v1.HelmChartSummary{}
+	err = c.client.Patch(pt).
+		Resource("helmchartsummaries").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
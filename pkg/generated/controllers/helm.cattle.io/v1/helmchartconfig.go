@@ -25,7 +25,10 @@ import (
 	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
 	"github.com/rancher/lasso/pkg/client"
 	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/condition"
 	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/rancher/wrangler/pkg/kv"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -55,7 +58,7 @@ type HelmChartConfigController interface {
 type HelmChartConfigClient interface {
 	Create(*v1.HelmChartConfig) (*v1.HelmChartConfig, error)
 	Update(*v1.HelmChartConfig) (*v1.HelmChartConfig, error)
-
+	UpdateStatus(*v1.HelmChartConfig) (*v1.HelmChartConfig, error)
 	Delete(namespace, name string, options *metav1.DeleteOptions) error
 	Get(namespace, name string, options metav1.GetOptions) (*v1.HelmChartConfig, error)
 	List(namespace string, opts metav1.ListOptions) (*v1.HelmChartConfigList, error)
@@ -184,6 +187,11 @@ func (c *helmChartConfigController) Update(obj *v1.HelmChartConfig) (*v1.HelmCha
 	return result, c.client.Update(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
 }
 
+func (c *helmChartConfigController) UpdateStatus(obj *v1.HelmChartConfig) (*v1.HelmChartConfig, error) {
+	result := &v1.HelmChartConfig{}
+	return result, c.client.UpdateStatus(context.TODO(), obj.Namespace, obj, result, metav1.UpdateOptions{})
+}
+
 func (c *helmChartConfigController) Delete(namespace, name string, options *metav1.DeleteOptions) error {
 	if options == nil {
 		options = &metav1.DeleteOptions{}
@@ -254,3 +262,115 @@ func (c *helmChartConfigCache) GetByIndex(indexName, key string) (result []*v1.H
 	}
 	return result, nil
 }
+
+type HelmChartConfigStatusHandler func(obj *v1.HelmChartConfig, status v1.HelmChartConfigStatus) (v1.HelmChartConfigStatus, error)
+
+type HelmChartConfigGeneratingHandler func(obj *v1.HelmChartConfig, status v1.HelmChartConfigStatus) ([]runtime.Object, v1.HelmChartConfigStatus, error)
+
+func RegisterHelmChartConfigStatusHandler(ctx context.Context, controller HelmChartConfigController, condition condition.Cond, name string, handler HelmChartConfigStatusHandler) {
+	statusHandler := &helmChartConfigStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, FromHelmChartConfigHandlerToHandler(statusHandler.sync))
+}
+
+func RegisterHelmChartConfigGeneratingHandler(ctx context.Context, controller HelmChartConfigController, apply apply.Apply,
+	condition condition.Cond, name string, handler HelmChartConfigGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &helmChartConfigGeneratingHandler{
+		HelmChartConfigGeneratingHandler: handler,
+		apply:                            apply,
+		name:                             name,
+		gvk:                              controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterHelmChartConfigStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type helmChartConfigStatusHandler struct {
+	client    HelmChartConfigClient
+	condition condition.Cond
+	handler   HelmChartConfigStatusHandler
+}
+
+func (a *helmChartConfigStatusHandler) sync(key string, obj *v1.HelmChartConfig) (*v1.HelmChartConfig, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type helmChartConfigGeneratingHandler struct {
+	HelmChartConfigGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+}
+
+func (a *helmChartConfigGeneratingHandler) Remove(key string, obj *v1.HelmChartConfig) (*v1.HelmChartConfig, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1.HelmChartConfig{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+func (a *helmChartConfigGeneratingHandler) Handle(obj *v1.HelmChartConfig, status v1.HelmChartConfigStatus) (v1.HelmChartConfigStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.HelmChartConfigGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+
+	return newStatus, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+}
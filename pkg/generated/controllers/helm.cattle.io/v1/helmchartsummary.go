@@ -0,0 +1,376 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by main. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/lasso/pkg/client"
+	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/rancher/wrangler/pkg/kv"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+type HelmChartSummaryHandler func(string, *v1.HelmChartSummary) (*v1.HelmChartSummary, error)
+
+type HelmChartSummaryController interface {
+	generic.ControllerMeta
+	HelmChartSummaryClient
+
+	OnChange(ctx context.Context, name string, sync HelmChartSummaryHandler)
+	OnRemove(ctx context.Context, name string, sync HelmChartSummaryHandler)
+	Enqueue(name string)
+	EnqueueAfter(name string, duration time.Duration)
+
+	Cache() HelmChartSummaryCache
+}
+
+type HelmChartSummaryClient interface {
+	Create(*v1.HelmChartSummary) (*v1.HelmChartSummary, error)
+	Update(*v1.HelmChartSummary) (*v1.HelmChartSummary, error)
+	UpdateStatus(*v1.HelmChartSummary) (*v1.HelmChartSummary, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+	Get(name string, options metav1.GetOptions) (*v1.HelmChartSummary, error)
+	List(opts metav1.ListOptions) (*v1.HelmChartSummaryList, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1.HelmChartSummary, err error)
+}
+
+type HelmChartSummaryCache interface {
+	Get(name string) (*v1.HelmChartSummary, error)
+	List(selector labels.Selector) ([]*v1.HelmChartSummary, error)
+
+	AddIndexer(indexName string, indexer HelmChartSummaryIndexer)
+	GetByIndex(indexName, key string) ([]*v1.HelmChartSummary, error)
+}
+
+type HelmChartSummaryIndexer func(obj *v1.HelmChartSummary) ([]string, error)
+
+type helmChartSummaryController struct {
+	controller    controller.SharedController
+	client        *client.Client
+	gvk           schema.GroupVersionKind
+	groupResource schema.GroupResource
+}
+
+func NewHelmChartSummaryController(gvk schema.GroupVersionKind, resource string, namespaced bool, controller controller.SharedControllerFactory) HelmChartSummaryController {
+	c := controller.ForResourceKind(gvk.GroupVersion().WithResource(resource), gvk.Kind, namespaced)
+	return &helmChartSummaryController{
+		controller: c,
+		client:     c.Client(),
+		gvk:        gvk,
+		groupResource: schema.GroupResource{
+			Group:    gvk.Group,
+			Resource: resource,
+		},
+	}
+}
+
+func FromHelmChartSummaryHandlerToHandler(sync HelmChartSummaryHandler) generic.Handler {
+	return func(key string, obj runtime.Object) (ret runtime.Object, err error) {
+		var v *v1.HelmChartSummary
+		if obj == nil {
+			v, err = sync(key, nil)
+		} else {
+			v, err = sync(key, obj.(*v1.HelmChartSummary))
+		}
+		if v == nil {
+			return nil, err
+		}
+		return v, err
+	}
+}
+
+func (c *helmChartSummaryController) Updater() generic.Updater {
+	return func(obj runtime.Object) (runtime.Object, error) {
+		newObj, err := c.Update(obj.(*v1.HelmChartSummary))
+		if newObj == nil {
+			return nil, err
+		}
+		return newObj, err
+	}
+}
+
+func UpdateHelmChartSummaryDeepCopyOnChange(client HelmChartSummaryClient, obj *v1.HelmChartSummary, handler func(obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error)) (*v1.HelmChartSummary, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	copyObj := obj.DeepCopy()
+	newObj, err := handler(copyObj)
+	if newObj != nil {
+		copyObj = newObj
+	}
+	if obj.ResourceVersion == copyObj.ResourceVersion && !equality.Semantic.DeepEqual(obj, copyObj) {
+		return client.Update(copyObj)
+	}
+
+	return copyObj, err
+}
+
+func (c *helmChartSummaryController) AddGenericHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.controller.RegisterHandler(ctx, name, controller.SharedControllerHandlerFunc(handler))
+}
+
+func (c *helmChartSummaryController) AddGenericRemoveHandler(ctx context.Context, name string, handler generic.Handler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), handler))
+}
+
+func (c *helmChartSummaryController) OnChange(ctx context.Context, name string, sync HelmChartSummaryHandler) {
+	c.AddGenericHandler(ctx, name, FromHelmChartSummaryHandlerToHandler(sync))
+}
+
+func (c *helmChartSummaryController) OnRemove(ctx context.Context, name string, sync HelmChartSummaryHandler) {
+	c.AddGenericHandler(ctx, name, generic.NewRemoveHandler(name, c.Updater(), FromHelmChartSummaryHandlerToHandler(sync)))
+}
+
+func (c *helmChartSummaryController) Enqueue(name string) {
+	c.controller.Enqueue("", name)
+}
+
+func (c *helmChartSummaryController) EnqueueAfter(name string, duration time.Duration) {
+	c.controller.EnqueueAfter("", name, duration)
+}
+
+func (c *helmChartSummaryController) Informer() cache.SharedIndexInformer {
+	return c.controller.Informer()
+}
+
+func (c *helmChartSummaryController) GroupVersionKind() schema.GroupVersionKind {
+	return c.gvk
+}
+
+func (c *helmChartSummaryController) Cache() HelmChartSummaryCache {
+	return &helmChartSummaryCache{
+		indexer:  c.Informer().GetIndexer(),
+		resource: c.groupResource,
+	}
+}
+
+func (c *helmChartSummaryController) Create(obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error) {
+	result := &v1.HelmChartSummary{}
+	return result, c.client.Create(context.TODO(), "", obj, result, metav1.CreateOptions{})
+}
+
+func (c *helmChartSummaryController) Update(obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error) {
+	result := &v1.HelmChartSummary{}
+	return result, c.client.Update(context.TODO(), "", obj, result, metav1.UpdateOptions{})
+}
+
+func (c *helmChartSummaryController) UpdateStatus(obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error) {
+	result := &v1.HelmChartSummary{}
+	return result, c.client.UpdateStatus(context.TODO(), "", obj, result, metav1.UpdateOptions{})
+}
+
+func (c *helmChartSummaryController) Delete(name string, options *metav1.DeleteOptions) error {
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+	return c.client.Delete(context.TODO(), "", name, *options)
+}
+
+func (c *helmChartSummaryController) Get(name string, options metav1.GetOptions) (*v1.HelmChartSummary, error) {
+	result := &v1.HelmChartSummary{}
+	return result, c.client.Get(context.TODO(), "", name, result, options)
+}
+
+func (c *helmChartSummaryController) List(opts metav1.ListOptions) (*v1.HelmChartSummaryList, error) {
+	result := &v1.HelmChartSummaryList{}
+	return result, c.client.List(context.TODO(), "", result, opts)
+}
+
+func (c *helmChartSummaryController) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.client.Watch(context.TODO(), "", opts)
+}
+
+func (c *helmChartSummaryController) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*v1.HelmChartSummary, error) {
+	result := &v1.HelmChartSummary{}
+	return result, c.client.Patch(context.TODO(), "", name, pt, data, result, metav1.PatchOptions{}, subresources...)
+}
+
+type helmChartSummaryCache struct {
+	indexer  cache.Indexer
+	resource schema.GroupResource
+}
+
+func (c *helmChartSummaryCache) Get(name string) (*v1.HelmChartSummary, error) {
+	obj, exists, err := c.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(c.resource, name)
+	}
+	return obj.(*v1.HelmChartSummary), nil
+}
+
+func (c *helmChartSummaryCache) List(selector labels.Selector) (ret []*v1.HelmChartSummary, err error) {
+
+	err = cache.ListAll(c.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.HelmChartSummary))
+	})
+
+	return ret, err
+}
+
+func (c *helmChartSummaryCache) AddIndexer(indexName string, indexer HelmChartSummaryIndexer) {
+	utilruntime.Must(c.indexer.AddIndexers(map[string]cache.IndexFunc{
+		indexName: func(obj interface{}) (strings []string, e error) {
+			return indexer(obj.(*v1.HelmChartSummary))
+		},
+	}))
+}
+
+func (c *helmChartSummaryCache) GetByIndex(indexName, key string) (result []*v1.HelmChartSummary, err error) {
+	objs, err := c.indexer.ByIndex(indexName, key)
+	if err != nil {
+		return nil, err
+	}
+	result = make([]*v1.HelmChartSummary, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, obj.(*v1.HelmChartSummary))
+	}
+	return result, nil
+}
+
+type HelmChartSummaryStatusHandler func(obj *v1.HelmChartSummary, status v1.HelmChartSummaryStatus) (v1.HelmChartSummaryStatus, error)
+
+type HelmChartSummaryGeneratingHandler func(obj *v1.HelmChartSummary, status v1.HelmChartSummaryStatus) ([]runtime.Object, v1.HelmChartSummaryStatus, error)
+
+func RegisterHelmChartSummaryStatusHandler(ctx context.Context, controller HelmChartSummaryController, condition condition.Cond, name string, handler HelmChartSummaryStatusHandler) {
+	statusHandler := &helmChartSummaryStatusHandler{
+		client:    controller,
+		condition: condition,
+		handler:   handler,
+	}
+	controller.AddGenericHandler(ctx, name, FromHelmChartSummaryHandlerToHandler(statusHandler.sync))
+}
+
+func RegisterHelmChartSummaryGeneratingHandler(ctx context.Context, controller HelmChartSummaryController, apply apply.Apply,
+	condition condition.Cond, name string, handler HelmChartSummaryGeneratingHandler, opts *generic.GeneratingHandlerOptions) {
+	statusHandler := &helmChartSummaryGeneratingHandler{
+		HelmChartSummaryGeneratingHandler: handler,
+		apply:                             apply,
+		name:                              name,
+		gvk:                               controller.GroupVersionKind(),
+	}
+	if opts != nil {
+		statusHandler.opts = *opts
+	}
+	controller.OnChange(ctx, name, statusHandler.Remove)
+	RegisterHelmChartSummaryStatusHandler(ctx, controller, condition, name, statusHandler.Handle)
+}
+
+type helmChartSummaryStatusHandler struct {
+	client    HelmChartSummaryClient
+	condition condition.Cond
+	handler   HelmChartSummaryStatusHandler
+}
+
+func (a *helmChartSummaryStatusHandler) sync(key string, obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error) {
+	if obj == nil {
+		return obj, nil
+	}
+
+	origStatus := obj.Status.DeepCopy()
+	obj = obj.DeepCopy()
+	newStatus, err := a.handler(obj, obj.Status)
+	if err != nil {
+		// Revert to old status on error
+		newStatus = *origStatus.DeepCopy()
+	}
+
+	if a.condition != "" {
+		if errors.IsConflict(err) {
+			a.condition.SetError(&newStatus, "", nil)
+		} else {
+			a.condition.SetError(&newStatus, "", err)
+		}
+	}
+	if !equality.Semantic.DeepEqual(origStatus, &newStatus) {
+		if a.condition != "" {
+			// Since status has changed, update the lastUpdatedTime
+			a.condition.LastUpdated(&newStatus, time.Now().UTC().Format(time.RFC3339))
+		}
+
+		var newErr error
+		obj.Status = newStatus
+		newObj, newErr := a.client.UpdateStatus(obj)
+		if err == nil {
+			err = newErr
+		}
+		if newErr == nil {
+			obj = newObj
+		}
+	}
+	return obj, err
+}
+
+type helmChartSummaryGeneratingHandler struct {
+	HelmChartSummaryGeneratingHandler
+	apply apply.Apply
+	opts  generic.GeneratingHandlerOptions
+	gvk   schema.GroupVersionKind
+	name  string
+}
+
+func (a *helmChartSummaryGeneratingHandler) Remove(key string, obj *v1.HelmChartSummary) (*v1.HelmChartSummary, error) {
+	if obj != nil {
+		return obj, nil
+	}
+
+	obj = &v1.HelmChartSummary{}
+	obj.Namespace, obj.Name = kv.RSplit(key, "/")
+	obj.SetGroupVersionKind(a.gvk)
+
+	return nil, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects()
+}
+
+func (a *helmChartSummaryGeneratingHandler) Handle(obj *v1.HelmChartSummary, status v1.HelmChartSummaryStatus) (v1.HelmChartSummaryStatus, error) {
+	if !obj.DeletionTimestamp.IsZero() {
+		return status, nil
+	}
+
+	objs, newStatus, err := a.HelmChartSummaryGeneratingHandler(obj, status)
+	if err != nil {
+		return newStatus, err
+	}
+
+	return newStatus, generic.ConfigureApplyForObject(a.apply, obj, &a.opts).
+		WithOwner(obj).
+		WithSetID(a.name).
+		ApplyObjects(objs...)
+}
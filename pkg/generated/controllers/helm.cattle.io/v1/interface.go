@@ -32,6 +32,7 @@ func init() {
 type Interface interface {
 	HelmChart() HelmChartController
 	HelmChartConfig() HelmChartConfigController
+	HelmChartSummary() HelmChartSummaryController
 }
 
 func New(controllerFactory controller.SharedControllerFactory) Interface {
@@ -50,3 +51,6 @@ func (c *version) HelmChart() HelmChartController {
 func (c *version) HelmChartConfig() HelmChartConfigController {
 	return NewHelmChartConfigController(schema.GroupVersionKind{Group: "helm.cattle.io", Version: "v1", Kind: "HelmChartConfig"}, "helmchartconfigs", true, c.controllerFactory)
 }
+func (c *version) HelmChartSummary() HelmChartSummaryController {
+	return NewHelmChartSummaryController(schema.GroupVersionKind{Group: "helm.cattle.io", Version: "v1", Kind: "HelmChartSummary"}, "helmchartsummaries", false, c.controllerFactory)
+}
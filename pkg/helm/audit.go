@@ -0,0 +1,296 @@
+package helm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// AuditManagerAnnotation is set on SpecChanged events to the field manager that last changed the chart's spec
+	AuditManagerAnnotation = "helm.cattle.io/audit-manager"
+	// AuditChangesAnnotation is set on SpecChanged events to the JSON encoded list of changed spec fields
+	AuditChangesAnnotation = "helm.cattle.io/audit-changes"
+	// Redacted replaces the values of secret-like fields in the audit log
+	Redacted = "<redacted>"
+)
+
+var (
+	auditLock       sync.Mutex
+	auditSpecsByKey = map[string]*auditSpec{}
+
+	// secretField matches spec and values field names whose values are redacted from the audit log
+	secretField = regexp.MustCompile(`(?i)(pass|pwd|auth|token|secret|key|credential|cert|private|session|cookie|bearer|dsn)`)
+)
+
+// AuditEntry records a change to the spec of a chart.
+type AuditEntry struct {
+	Time       time.Time     `json:"time"`
+	Namespace  string        `json:"namespace"`
+	Name       string        `json:"name"`
+	Generation int64         `json:"generation"`
+	Manager    string        `json:"manager,omitempty"`
+	Operation  string        `json:"operation,omitempty"`
+	Changes    []AuditChange `json:"changes"`
+}
+
+// AuditChange is a single changed field, identified by its dotted path within the spec. Values inside
+// valuesContent are listed individually under the valuesContent path.
+type AuditChange struct {
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// AuditSink persists audit entries to an append-only store, in addition to the SpecChanged events recorded when
+// AuditLog is enabled.
+type AuditSink interface {
+	// WriteAudit is called once for each change to the spec of a chart. Errors are returned from the reconcile,
+	// so that the chart is requeued and the entry written again.
+	WriteAudit(entry AuditEntry) error
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface.
+type AuditSinkFunc func(entry AuditEntry) error
+
+func (f AuditSinkFunc) WriteAudit(entry AuditEntry) error {
+	return f(entry)
+}
+
+// NewAuditFileSink returns an AuditSink that appends entries to the file at path, one JSON object per line.
+func NewAuditFileSink(path string) (AuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	var lock sync.Mutex
+	encoder := json.NewEncoder(file)
+	return AuditSinkFunc(func(entry AuditEntry) error {
+		lock.Lock()
+		defer lock.Unlock()
+		return encoder.Encode(entry)
+	}), nil
+}
+
+type auditSpec struct {
+	generation int64
+	fields     map[string]auditField
+}
+
+// auditField is the value of a spec field. Redacted fields hold a hash of their value, so that changes to them
+// can be detected without keeping the value.
+type auditField struct {
+	value    string
+	redacted bool
+}
+
+func (f auditField) String() string {
+	if f.redacted {
+		return Redacted
+	}
+	return f.value
+}
+
+func redactedField(value string) auditField {
+	sum := sha256.Sum256([]byte(value))
+	return auditField{value: hex.EncodeToString(sum[:]), redacted: true}
+}
+
+// auditSpecChange records the changes made to the chart's spec since it was last seen, when AuditLog is enabled
+// or AuditSinks are configured. The spec of each chart is remembered from the first time it is seen after the
+// controller starts, so changes made while the controller is not running are not recorded.
+func (c *Controller) auditSpecChange(chart *helmv1.HelmChart) error {
+	if !AuditLog && len(AuditSinks) == 0 {
+		return nil
+	}
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	key := chart.Namespace + "/" + chart.Name
+	previous := auditSpecsByKey[key]
+	if previous != nil && chart.Generation <= previous.generation {
+		return nil
+	}
+	fields, err := flattenSpec(chart.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to audit spec of HelmChart %s: %w", key, err)
+	}
+	current := &auditSpec{generation: chart.Generation, fields: fields}
+	if previous == nil {
+		auditSpecsByKey[key] = current
+		return nil
+	}
+
+	changes := auditChanges(previous.fields, current.fields)
+	if len(changes) == 0 {
+		auditSpecsByKey[key] = current
+		return nil
+	}
+	entry := AuditEntry{
+		Time:       time.Now().UTC(),
+		Namespace:  chart.Namespace,
+		Name:       chart.Name,
+		Generation: chart.Generation,
+		Changes:    changes,
+	}
+	if managed := specManager(chart); managed != nil {
+		entry.Manager = managed.Manager
+		entry.Operation = string(managed.Operation)
+		if managed.Time != nil {
+			entry.Time = managed.Time.UTC()
+		}
+	}
+	for _, sink := range AuditSinks {
+		if err := sink.WriteAudit(entry); err != nil {
+			return fmt.Errorf("failed to write audit entry for HelmChart %s: %w", key, err)
+		}
+	}
+	auditSpecsByKey[key] = current
+
+	if AuditLog {
+		c.recordAudit(chart, entry)
+	}
+	return nil
+}
+
+func (c *Controller) recordAudit(chart *helmv1.HelmChart, entry AuditEntry) {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		logrus.Warnf("Failed to encode audit entry for HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+		return
+	}
+	paths := make([]string, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		paths = append(paths, change.Path)
+	}
+	manager := entry.Manager
+	if manager == "" {
+		manager = "unknown manager"
+	}
+	annotations := map[string]string{
+		AuditManagerAnnotation: entry.Manager,
+		AuditChangesAnnotation: string(changes),
+	}
+	c.recorder.AnnotatedEventf(chart, annotations, core.EventTypeNormal, "SpecChanged", "Spec changed to generation %d by %s: %s", entry.Generation, manager, strings.Join(paths, ", "))
+}
+
+// forgetAudit removes the remembered spec of a chart that has been deleted.
+func forgetAudit(chart *helmv1.HelmChart) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	delete(auditSpecsByKey, chart.Namespace+"/"+chart.Name)
+}
+
+// specManager returns the most recent managed fields entry that owns any field of the spec.
+func specManager(chart *helmv1.HelmChart) *meta.ManagedFieldsEntry {
+	var latest *meta.ManagedFieldsEntry
+	for i := range chart.ManagedFields {
+		entry := &chart.ManagedFields[i]
+		if entry.FieldsV1 == nil || !bytes.Contains(entry.FieldsV1.Raw, []byte(`"f:spec"`)) {
+			continue
+		}
+		if latest == nil || (entry.Time != nil && (latest.Time == nil || !entry.Time.Before(latest.Time))) {
+			latest = entry
+		}
+	}
+	return latest
+}
+
+// flattenSpec returns the fields of the spec keyed by their dotted path, with the values of secret-like fields
+// redacted. The chart content is always redacted, and valuesContent is flattened into its individual values.
+func flattenSpec(spec helmv1.HelmChartSpec) (map[string]auditField, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]auditField{}
+	if content, ok := obj["chartContent"].(string); ok {
+		delete(obj, "chartContent")
+		fields["chartContent"] = redactedField(content)
+	}
+	if content, ok := obj["valuesContent"].(string); ok {
+		delete(obj, "valuesContent")
+		var values interface{}
+		if err := yaml.Unmarshal([]byte(content), &values); err != nil {
+			// not valid yaml; record it as a single value
+			values = content
+		}
+		flatten(fields, "valuesContent", values, false)
+	}
+	flatten(fields, "", obj, false)
+	return fields, nil
+}
+
+func flatten(fields map[string]auditField, path string, value interface{}, redact bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			flatten(fields, joinPath(path, k), v, redact || secretField.MatchString(k))
+		}
+	case []interface{}:
+		for i, v := range value {
+			flatten(fields, fmt.Sprintf("%s[%d]", path, i), v, redact)
+		}
+	case nil:
+	default:
+		s, ok := value.(string)
+		if !ok {
+			data, _ := json.Marshal(value)
+			s = string(data)
+		}
+		if redact {
+			fields[path] = redactedField(s)
+		} else {
+			fields[path] = auditField{value: s}
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// auditChanges returns the fields that differ between old and new, sorted by path. Redacted fields are listed
+// without their values.
+func auditChanges(old, new map[string]auditField) []AuditChange {
+	var changes []AuditChange
+	for path, value := range new {
+		if oldValue, ok := old[path]; !ok {
+			changes = append(changes, AuditChange{Path: path, New: value.String()})
+		} else if oldValue != value {
+			changes = append(changes, AuditChange{Path: path, Old: oldValue.String(), New: value.String()})
+		}
+	}
+	for path, value := range old {
+		if _, ok := new[path]; !ok {
+			changes = append(changes, AuditChange{Path: path, Old: value.String()})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,103 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAuditChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	old, err := flattenSpec(v1.HelmChartSpec{
+		Chart:         "stable/traefik",
+		Version:       "1.0.0",
+		Set:           map[string]intstr.IntOrString{"auth.password": intstr.FromString("hunter2")},
+		ValuesContent: "replicas: 1\ntls:\n  key: abc\n",
+		ChartContent:  "Y2hhcnQ=",
+	})
+	assert.NoError(err)
+	new, err := flattenSpec(v1.HelmChartSpec{
+		Chart:         "stable/traefik",
+		Version:       "1.1.0",
+		Set:           map[string]intstr.IntOrString{"auth.password": intstr.FromString("hunter3")},
+		ValuesContent: "replicas: 2\ntls:\n  key: abc\n",
+	})
+	assert.NoError(err)
+
+	assert.Equal([]AuditChange{
+		{Path: "chartContent", Old: Redacted},
+		{Path: "set.auth.password", Old: Redacted, New: Redacted},
+		{Path: "valuesContent.replicas", Old: "1", New: "2"},
+		{Path: "version", Old: "1.0.0", New: "1.1.0"},
+	}, auditChanges(old, new))
+}
+
+func TestAuditRedaction(t *testing.T) {
+	assert := assert.New(t)
+
+	fields, err := flattenSpec(v1.HelmChartSpec{
+		Set: map[string]intstr.IntOrString{
+			"db.pass":         intstr.FromString("hunter2"),
+			"smtp.passphrase": intstr.FromString("hunter2"),
+			"replicas":        intstr.FromInt(2),
+		},
+		ValuesContent: "ingress:\n  basicAuth: dXNlcjpwYXNz\n  host: example.com\nredis:\n  pwd: hunter2\nsentry:\n  dsn: https://abc@sentry.example.com/1\nssh:\n  privateKey: abc\n",
+	})
+	assert.NoError(err)
+
+	for path, change := range map[string]AuditChange{
+		"set.db.pass":                     {Path: "set.db.pass", New: Redacted},
+		"set.smtp.passphrase":             {Path: "set.smtp.passphrase", New: Redacted},
+		"set.replicas":                    {Path: "set.replicas", New: "2"},
+		"valuesContent.ingress.basicAuth": {Path: "valuesContent.ingress.basicAuth", New: Redacted},
+		"valuesContent.ingress.host":      {Path: "valuesContent.ingress.host", New: "example.com"},
+		"valuesContent.redis.pwd":         {Path: "valuesContent.redis.pwd", New: Redacted},
+		"valuesContent.sentry.dsn":        {Path: "valuesContent.sentry.dsn", New: Redacted},
+		"valuesContent.ssh.privateKey":    {Path: "valuesContent.ssh.privateKey", New: Redacted},
+	} {
+		assert.Contains(auditChanges(nil, fields), change, path)
+	}
+}
+
+func TestAuditSpecChange(t *testing.T) {
+	assert := assert.New(t)
+	defer func(enabled bool, sinks []AuditSink) { AuditLog, AuditSinks = enabled, sinks }(AuditLog, AuditSinks)
+
+	var entries []AuditEntry
+	AuditLog = true
+	AuditSinks = []AuditSink{AuditSinkFunc(func(entry AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	chart.Generation = 1
+	defer forgetAudit(chart)
+	assert.NoError(c.auditSpecChange(chart))
+	assert.Empty(entries)
+
+	changed := meta.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	chart.Generation = 2
+	chart.Spec.Version = "1.1.0"
+	chart.ManagedFields = []meta.ManagedFieldsEntry{
+		{Manager: "kubectl", Operation: meta.ManagedFieldsOperationUpdate, Time: &changed, FieldsV1: &meta.FieldsV1{Raw: []byte(`{"f:spec":{"f:version":{}}}`)}},
+		{Manager: Name, Operation: meta.ManagedFieldsOperationUpdate, Time: &changed, FieldsV1: &meta.FieldsV1{Raw: []byte(`{"f:status":{}}`)}},
+	}
+	assert.NoError(c.auditSpecChange(chart))
+	assert.NoError(c.auditSpecChange(chart))
+	if assert.Len(entries, 1) {
+		assert.Equal("kubectl", entries[0].Manager)
+		assert.Equal(changed.Time, entries[0].Time)
+		assert.Equal([]AuditChange{{Path: "version", New: "1.1.0"}}, entries[0].Changes)
+	}
+	assert.Len(recorder.Events, 1)
+	assert.Equal("Normal SpecChanged Spec changed to generation 2 by kubectl: version", <-recorder.Events)
+}
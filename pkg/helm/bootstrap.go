@@ -0,0 +1,72 @@
+package helm
+
+import (
+	"fmt"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/objectset"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// bootstrapRetryInterval is how often bootstrap charts waiting for bootstrap charts with a lower weight are
+// retried, in case the update that made them succeed was missed
+const bootstrapRetryInterval = 15 * time.Second
+
+// bootstrapBlocker returns a bootstrap chart with a lower BootstrapWeight than the chart that has not yet
+// succeeded, so that a new job for the chart is held back until the charts that it depends on, such as the CNI,
+// are installed. Charts with the same weight are installed concurrently. Jobs that already exist for the current
+// chart configuration are always allowed to continue, as are charts that are not bootstrap charts or are being
+// deleted. Unmanaged charts, and charts that are being deleted, do not hold back other charts.
+func (c *Controller) bootstrapBlocker(chart *helmv1.HelmChart, objs *objectset.ObjectSet) (*helmv1.HelmChart, error) {
+	if !chart.Spec.Bootstrap || chart.DeletionTimestamp != nil || c.jobUpToDate(chart, objs) {
+		return nil, nil
+	}
+	charts, err := c.helmController.Cache().List("", labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var blocker *helmv1.HelmChart
+	for _, other := range charts {
+		if !other.Spec.Bootstrap || other.Spec.BootstrapWeight >= chart.Spec.BootstrapWeight || other.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := other.Annotations[Unmanaged]; ok || !hasChart(other) || jobSucceeded(other) {
+			continue
+		}
+		if blocker == nil || other.Spec.BootstrapWeight < blocker.Spec.BootstrapWeight {
+			blocker = other
+		}
+	}
+	return blocker, nil
+}
+
+// setWaitingForBootstrap marks the chart as waiting for a bootstrap chart with a lower weight, recording an event
+// when the chart starts waiting for it.
+func (c *Controller) setWaitingForBootstrap(chart, blocker *helmv1.HelmChart) {
+	message := fmt.Sprintf("waiting for bootstrap chart %s/%s with weight %d to succeed", blocker.Namespace, blocker.Name, blocker.Spec.BootstrapWeight)
+	if ConditionReady.GetReason(chart) != "WaitingForBootstrap" || ConditionReady.GetMessage(chart) != message {
+		c.recorder.Eventf(chart, core.EventTypeNormal, "WaitingForBootstrap", "Waiting for bootstrap chart %s/%s with weight %d to succeed", blocker.Namespace, blocker.Name, blocker.Spec.BootstrapWeight)
+	}
+	ConditionReady.False(chart)
+	ConditionReady.Reason(chart, "WaitingForBootstrap")
+	ConditionReady.Message(chart, message)
+}
+
+// enqueueBootstrapDependents enqueues the bootstrap charts with a higher weight than a bootstrap chart that has
+// just succeeded, so that they do not wait for the bootstrap retry interval.
+func (c *Controller) enqueueBootstrapDependents(chart *helmv1.HelmChart) {
+	if !chart.Spec.Bootstrap {
+		return
+	}
+	charts, err := c.helmController.Cache().List("", labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, other := range charts {
+		if other.Spec.Bootstrap && other.Spec.BootstrapWeight > chart.Spec.BootstrapWeight {
+			c.helmController.Enqueue(other.Namespace, other.Name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestBootstrapBlocker(t *testing.T) {
+	assert := assert.New(t)
+	cni := v1.NewHelmChart("kube-system", "cni", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "cni", Bootstrap: true}})
+	ccm := v1.NewHelmChart("kube-system", "ccm", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "ccm", Bootstrap: true, BootstrapWeight: 10}})
+	ingress := v1.NewHelmChart("kube-system", "ingress", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "ingress", Bootstrap: true, BootstrapWeight: 20}})
+	app := v1.NewHelmChart("default", "app", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "app"}})
+	helms := &helmController{charts: []*v1.HelmChart{app, ingress, ccm, cni}}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{helmController: helms, jobsCache: &jobCache{}, recorder: recorder}
+
+	objs, err := render.Objects(ingress, nil, render.Options{})
+	assert.NoError(err)
+	blocker, err := c.bootstrapBlocker(ingress, objs)
+	assert.NoError(err)
+	assert.Equal(cni, blocker)
+
+	blocker, err = c.bootstrapBlocker(cni, objs)
+	assert.NoError(err)
+	assert.Nil(blocker)
+	blocker, err = c.bootstrapBlocker(app, objs)
+	assert.NoError(err)
+	assert.Nil(blocker)
+
+	ConditionReady.True(cni)
+	ConditionReady.Reason(cni, "JobSucceeded")
+	blocker, err = c.bootstrapBlocker(ingress, objs)
+	assert.NoError(err)
+	assert.Equal(ccm, blocker)
+
+	chart := ingress.DeepCopy()
+	c.setWaitingForBootstrap(chart, blocker)
+	c.setWaitingForBootstrap(chart, blocker)
+	assert.True(ConditionReady.IsFalse(chart))
+	assert.Equal("WaitingForBootstrap", ConditionReady.GetReason(chart))
+	assert.Equal("waiting for bootstrap chart kube-system/ccm with weight 10 to succeed", ConditionReady.GetMessage(chart))
+	assert.Len(recorder.Events, 1)
+
+	ccm.Annotations = map[string]string{Unmanaged: "true"}
+	blocker, err = c.bootstrapBlocker(ingress, objs)
+	assert.NoError(err)
+	assert.Nil(blocker)
+
+	c.enqueueBootstrapDependents(cni)
+	c.enqueueBootstrapDependents(app)
+	assert.Equal([]string{"kube-system/ingress", "kube-system/ccm"}, helms.enqueued)
+}
@@ -0,0 +1,73 @@
+package helm
+
+import (
+	"strconv"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/objectset"
+	"github.com/sirupsen/logrus"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// jobSlotAvailable returns false if creating the chart's job would exceed the number of jobs allowed to run
+// concurrently in the chart's namespace. Jobs that already exist for the current chart configuration are
+// always allowed to continue. The limit is enforced against the job cache, so it is best-effort: charts
+// reconciled at the same moment by different workers may briefly exceed it.
+func (c *Controller) jobSlotAvailable(chart *helmv1.HelmChart, objs *objectset.ObjectSet) (bool, error) {
+	namespace, err := c.namespaceCache.Get(chart.Namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	limit := maxConcurrentJobs(namespace)
+	if limit <= 0 {
+		return true, nil
+	}
+
+	if c.jobUpToDate(chart, objs) {
+		return true, nil
+	}
+
+	jobs, err := c.jobsCache.List(chart.Namespace, labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	active := 0
+	for _, job := range jobs {
+		if owner := job.Labels[Label]; owner == "" || owner == chart.Name {
+			continue
+		}
+		if !jobFinished(job) {
+			active++
+		}
+	}
+
+	return active < limit, nil
+}
+
+// maxConcurrentJobs returns the concurrent job limit for a namespace. The namespace annotation takes
+// precedence over the controller-wide MaxConcurrentJobs; a limit of zero or less means unlimited.
+func maxConcurrentJobs(namespace *core.Namespace) int {
+	if namespace != nil {
+		if value, ok := namespace.Annotations[MaxConcurrentJobsAnnotation]; ok {
+			limit, err := strconv.Atoi(value)
+			if err == nil {
+				return limit
+			}
+			logrus.Warnf("Ignoring invalid %s annotation on namespace %s: %v", MaxConcurrentJobsAnnotation, namespace.Name, err)
+		}
+	}
+	return MaxConcurrentJobs
+}
+
+// jobFinished returns true if the job has completed or failed.
+func jobFinished(job *batch.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batch.JobComplete || cond.Type == batch.JobFailed) && cond.Status == core.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
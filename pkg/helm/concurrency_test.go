@@ -0,0 +1,41 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaxConcurrentJobs(t *testing.T) {
+	assert := assert.New(t)
+	defer func(limit int) { MaxConcurrentJobs = limit }(MaxConcurrentJobs)
+	MaxConcurrentJobs = 2
+
+	namespace := &core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "edge"}}
+	assert.Equal(2, maxConcurrentJobs(nil))
+	assert.Equal(2, maxConcurrentJobs(namespace))
+
+	namespace.Annotations = map[string]string{MaxConcurrentJobsAnnotation: "1"}
+	assert.Equal(1, maxConcurrentJobs(namespace))
+
+	namespace.Annotations[MaxConcurrentJobsAnnotation] = "0"
+	assert.Equal(0, maxConcurrentJobs(namespace))
+
+	namespace.Annotations[MaxConcurrentJobsAnnotation] = "one"
+	assert.Equal(2, maxConcurrentJobs(namespace))
+}
+
+func TestJobFinished(t *testing.T) {
+	assert := assert.New(t)
+	job := &batch.Job{}
+	assert.False(jobFinished(job))
+
+	job.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionFalse}}
+	assert.False(jobFinished(job))
+
+	job.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue}}
+	assert.True(jobFinished(job))
+}
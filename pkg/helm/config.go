@@ -0,0 +1,85 @@
+package helm
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// updateConfigStatus records whether the config applies to any charts, and whether the jobs of all of those charts
+// have been rendered with the config's current settings and succeeded. The hash of the settings is recorded once
+// they have been applied to every chart.
+func (c *Controller) updateConfigStatus(conf *helmv1.HelmChartConfig, targets []*helmv1.HelmChart) (*helmv1.HelmChartConfig, error) {
+	if conf.DeletionTimestamp != nil {
+		return conf, nil
+	}
+
+	confCopy := conf.DeepCopy()
+	c.setConfigValuesCondition(confCopy)
+	confCopy.Status.ObservedGeneration = conf.Generation
+	confCopy.Status.TargetChartFound = len(targets) > 0
+
+	hash := render.ConfigHash(conf)
+	applied, err := c.configApplied(targets, hash)
+	if err != nil {
+		return conf, err
+	}
+	confCopy.Status.Applied = applied
+	if applied {
+		confCopy.Status.LastAppliedHash = hash
+	}
+
+	if equality.Semantic.DeepEqual(conf.Status, confCopy.Status) {
+		return conf, nil
+	}
+	return c.confController.UpdateStatus(confCopy)
+}
+
+// configApplied returns true if every chart is ready, and its job was rendered with the config settings that hash
+// to hash.
+func (c *Controller) configApplied(charts []*helmv1.HelmChart, hash string) (bool, error) {
+	if len(charts) == 0 {
+		return false, nil
+	}
+	for _, chart := range charts {
+		if !ConditionReady.IsTrue(chart) {
+			return false, nil
+		}
+		job, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart))
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if job.Annotations[render.HelmChartConfigHashAnnotation] != hash {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// setConfigValuesCondition validates the config's ValuesContent, and records the result in the config's ValuesValid
+// condition. Charts that use an invalid config are not installed, and report the error on their own Ready
+// condition; the condition and event on the config point at the config itself, which may be used by many charts.
+func (c *Controller) setConfigValuesCondition(conf *helmv1.HelmChartConfig) {
+	if err := render.ValidateConfigValuesContent(conf); err != nil {
+		if ConditionConfigValuesValid.GetReason(conf) != "InvalidValues" || ConditionConfigValuesValid.GetMessage(conf) != err.Error() {
+			c.recorder.Eventf(conf, core.EventTypeWarning, "InvalidValues", "Values cannot be merged into charts using this HelmChartConfig: %v", err)
+		}
+		ConditionConfigValuesValid.False(conf)
+		ConditionConfigValuesValid.Reason(conf, "InvalidValues")
+		ConditionConfigValuesValid.Message(conf, err.Error())
+	} else {
+		ConditionConfigValuesValid.True(conf)
+		ConditionConfigValuesValid.Reason(conf, "")
+		ConditionConfigValuesValid.Message(conf, "")
+	}
+}
+
+// enqueueConfig requeues the config used by the chart, so that its status is updated when the chart is installed or
+// removed. Charts without a config enqueue a key that does not exist, which is ignored.
+func (c *Controller) enqueueConfig(chart *helmv1.HelmChart) {
+	c.confController.Enqueue(ConfigKey(chart))
+}
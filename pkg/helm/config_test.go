@@ -0,0 +1,121 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	helmcontroller "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	batchcontroller "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+)
+
+type confController struct {
+	helmcontroller.HelmChartConfigController
+	updated  []*v1.HelmChartConfig
+	enqueued []string
+}
+
+func (c *confController) UpdateStatus(conf *v1.HelmChartConfig) (*v1.HelmChartConfig, error) {
+	c.updated = append(c.updated, conf)
+	return conf, nil
+}
+
+func (c *confController) Enqueue(namespace, name string) {
+	c.enqueued = append(c.enqueued, namespace+"/"+name)
+}
+
+func (c *confController) Cache() helmcontroller.HelmChartConfigCache {
+	return &confCache{}
+}
+
+type confCache struct {
+	helmcontroller.HelmChartConfigCache
+}
+
+func (c *confCache) Get(namespace, name string) (*v1.HelmChartConfig, error) {
+	return nil, errors.NewNotFound(v1.Resource("helmchartconfigs"), name)
+}
+
+type jobCache struct {
+	batchcontroller.JobCache
+	jobs map[string]*batch.Job
+}
+
+func (c *jobCache) Get(namespace, name string) (*batch.Job, error) {
+	if job, ok := c.jobs[namespace+"/"+name]; ok {
+		return job, nil
+	}
+	return nil, errors.NewNotFound(batch.Resource("jobs"), name)
+}
+
+func TestConfigValuesCondition(t *testing.T) {
+	assert := assert.New(t)
+	confs := &confController{}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{confController: confs, jobsCache: &jobCache{}, recorder: recorder}
+
+	conf := v1.NewHelmChartConfig("kube-system", "traefik", v1.HelmChartConfig{
+		Spec: v1.HelmChartConfigSpec{ValuesContent: "ports:\n  web: 8000\n  web: 8080\n"},
+	})
+	conf, err := c.updateConfigStatus(conf, nil)
+	assert.NoError(err)
+	assert.Len(confs.updated, 1)
+	assert.True(ConditionConfigValuesValid.IsFalse(conf))
+	assert.Equal(`spec.valuesContent is invalid: line 3: key "web" already set in map`, ConditionConfigValuesValid.GetMessage(conf))
+	assert.Len(recorder.Events, 1)
+
+	// unchanged status is not updated again
+	conf, err = c.updateConfigStatus(conf, nil)
+	assert.NoError(err)
+	assert.Len(confs.updated, 1)
+	assert.Len(recorder.Events, 1)
+
+	conf.Spec.ValuesContent = "ports:\n  web: 8000\n"
+	conf, err = c.updateConfigStatus(conf, nil)
+	assert.NoError(err)
+	assert.Len(confs.updated, 2)
+	assert.True(ConditionConfigValuesValid.IsTrue(conf))
+	assert.Empty(ConditionConfigValuesValid.GetMessage(conf))
+}
+
+func TestConfigApplied(t *testing.T) {
+	assert := assert.New(t)
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	c := &Controller{confController: &confController{}, jobsCache: jobs, recorder: record.NewFakeRecorder(10)}
+
+	conf := v1.NewHelmChartConfig("kube-system", "traefik", v1.HelmChartConfig{
+		Spec: v1.HelmChartConfigSpec{ValuesContent: "ports:\n  web: 8000\n"},
+	})
+	conf.Generation = 2
+	conf, err := c.updateConfigStatus(conf, nil)
+	assert.NoError(err)
+	assert.Equal(int64(2), conf.Status.ObservedGeneration)
+	assert.False(conf.Status.TargetChartFound)
+	assert.False(conf.Status.Applied)
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	conf, err = c.updateConfigStatus(conf, []*v1.HelmChart{chart})
+	assert.NoError(err)
+	assert.True(conf.Status.TargetChartFound)
+	assert.False(conf.Status.Applied)
+
+	// the chart's job was rendered with a previous version of the config
+	ConditionReady.True(chart)
+	job := &batch.Job{}
+	job.Annotations = map[string]string{render.HelmChartConfigHashAnnotation: "SHA256=0"}
+	jobs.jobs["kube-system/"+render.JobName(chart)] = job
+	conf, err = c.updateConfigStatus(conf, []*v1.HelmChart{chart})
+	assert.NoError(err)
+	assert.False(conf.Status.Applied)
+	assert.Empty(conf.Status.LastAppliedHash)
+
+	job.Annotations[render.HelmChartConfigHashAnnotation] = render.ConfigHash(conf)
+	conf, err = c.updateConfigStatus(conf, []*v1.HelmChart{chart})
+	assert.NoError(err)
+	assert.True(conf.Status.Applied)
+	assert.Equal(render.ConfigHash(conf), conf.Status.LastAppliedHash)
+}
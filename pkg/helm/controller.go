@@ -2,20 +2,23 @@ package helm
 
 import (
 	"context"
-	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"os"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
 
 	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
 	helmcontroller "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
 	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/condition"
 	batchcontroller "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
 	corecontroller "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	networkingcontroller "github.com/rancher/wrangler/pkg/generated/controllers/networking.k8s.io/v1"
 	rbaccontroller "github.com/rancher/wrangler/pkg/generated/controllers/rbac/v1"
+	"github.com/rancher/wrangler/pkg/generic"
 	"github.com/rancher/wrangler/pkg/objectset"
 	"github.com/rancher/wrangler/pkg/relatedresource"
 	"github.com/rancher/wrangler/pkg/schemes"
@@ -23,64 +26,225 @@ import (
 	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
-	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
-	"k8s.io/utils/pointer"
 )
 
 var (
-	commaRE              = regexp.MustCompile(`\\*,`)
 	deletePolicy         = meta.DeletePropagationForeground
-	DefaultJobImage      = "rancher/klipper-helm:v0.7.3-build20220613"
-	DefaultFailurePolicy = FailurePolicyReinstall
+	DefaultJobImage      = render.DefaultJobImage
+	DefaultFailurePolicy = render.DefaultFailurePolicy
+	// DefaultTimeout matches the default helm uses when TIMEOUT is not set on the job
+	DefaultTimeout = 300 * time.Second
+	// MaxTimeout is the longest timeout that charts may set; zero means unlimited
+	MaxTimeout time.Duration
+	// CommonLabels are added to all objects generated for charts
+	CommonLabels = map[string]string{}
+	// ReleaseSecretLabels are added to the Secrets that helm stores releases in, unless overridden by the chart
+	ReleaseSecretLabels = map[string]string{}
+	// ReleaseLabels are added to every resource installed by charts, unless overridden by the chart's common labels
+	ReleaseLabels = map[string]string{}
+	// ReleaseAnnotations are added to every resource installed by charts, unless overridden by the chart's common annotations
+	ReleaseAnnotations = map[string]string{}
+	// DisableSidecars prevents service mesh sidecar injection into jobs, unless overridden by the chart
+	DisableSidecars = false
+	// JobSafeToEvict sets the cluster autoscaler safe-to-evict annotation on jobs, unless overridden by the chart;
+	// nil leaves it unset
+	JobSafeToEvict *bool
+	// UpgradeJobImage re-runs charts that use the default job image when the default changes, instead of keeping the
+	// image of their existing job until the chart is changed
+	UpgradeJobImage = false
+	// DisableJobSecurityContext runs jobs without a restrictive security context, unless overridden by the chart
+	DisableJobSecurityContext = false
+	// TolerateUnschedulable allows jobs to run on cordoned nodes, unless overridden by the chart
+	TolerateUnschedulable = false
+	// RepoMirrors maps upstream repo URL prefixes to mirror URL prefixes that are used in their place
+	RepoMirrors = map[string]string{}
+	// RepoMirrorAuthSecret is the name of the Secret holding credentials for repo mirrors
+	RepoMirrorAuthSecret = ""
+	// CABundle is the ConfigMap key holding a CA bundle that is used by jobs, unless overridden by the chart
+	CABundle *core.ConfigMapKeySelector
+	// TerminatingNamespacePolicy controls whether charts in terminating namespaces wait for their delete job
+	TerminatingNamespacePolicy = TerminatingNamespacePolicyWait
+	// SpreadJobs prefers scheduling jobs on different nodes, unless overridden by the chart
+	SpreadJobs = false
+	// JobNodeSelector is added to the node selector of jobs, along with the chart's own node selector
+	JobNodeSelector = map[string]string{}
+	// JobTolerations are added to jobs, along with the chart's own tolerations
+	JobTolerations []core.Toleration
+	// ClusterRoleBindingGCInterval is how often ClusterRoleBindings left behind by deleted charts are cleaned up
+	ClusterRoleBindingGCInterval = 5 * time.Minute
+	// ReinstallOnNamespaceRecreate re-runs the install job for charts whose target namespace is deleted and re-created
+	ReinstallOnNamespaceRecreate = false
+	// MaxConcurrentJobs limits the number of jobs running at once in each namespace; zero means unlimited
+	MaxConcurrentJobs = 0
+	// JobQueueRetryInterval is how often charts waiting for a free job slot in their namespace are retried
+	JobQueueRetryInterval = 15 * time.Second
+	// CacheSyncRetryInterval is how often charts are retried while waiting for the jobs cache to sync
+	CacheSyncRetryInterval = time.Second
+	// SlowJobFactor is the multiple of a chart's timeout that its job may run for before it is considered slow,
+	// unless the chart sets its own expected duration; zero disables slow job detection
+	SlowJobFactor = 2.0
+	// ReleaseVerifyInterval is how often the releases of installed charts are checked for removal or changes made
+	// outside of the controller; zero disables the check
+	ReleaseVerifyInterval = 10 * time.Minute
+	// LogApplyPlan logs and records an event listing the objects that will be created, updated or deleted for
+	// charts before they are applied
+	LogApplyPlan = false
+	// DeleteJobTTL is how long finished delete jobs are kept if the controller does not remove them; zero disables the TTL
+	DeleteJobTTL time.Duration
+	// ProjectedServiceAccountToken mounts a projected ServiceAccount token into jobs, instead of relying on automounting
+	ProjectedServiceAccountToken = false
+	// StreamJobLogs mirrors the logs of job pods into the controller log, prefixed with the chart they belong to
+	StreamJobLogs = false
+	// RecordProvenance records the chart, app version and images of each chart's deployed release in a ConfigMap
+	RecordProvenance = false
+	// DriftGracePeriod is how long the desired configuration of a chart may differ from its last successfully
+	// installed configuration before the chart is reported as drifted; zero disables drift detection
+	DriftGracePeriod time.Duration
+	// TransientErrorBudget is how many consecutive transient apiserver errors are retried quietly for each chart
+	// before the error is reported; zero reports every error
+	TransientErrorBudget = 5
+	// TransientRetryInterval is the initial interval between retries of charts that failed with a transient error
+	TransientRetryInterval = time.Second
+	// StatusHistoryLimit is how many reconcile milestones are kept in the status history of each chart; zero disables
+	// the history
+	StatusHistoryLimit = 10
+	// CheckRepoReachability checks that the repo of each chart can be reached from the controller before a job is
+	// created for it, and reports the result in the RepoReachable condition
+	CheckRepoReachability = false
+	// DryRun records the objects that would be applied for charts, without creating or deleting anything
+	DryRun = false
+	// StatusWriters are passed each chart after it is updated, so that embedders can mirror chart status elsewhere
+	StatusWriters []StatusWriter
+	// AuditLog records a SpecChanged event, annotated with the redacted changes, each time the spec of a chart changes
+	AuditLog = false
+	// AuditSinks are passed an entry each time the spec of a chart changes, so that changes can be kept for auditing
+	AuditSinks []AuditSink
+	// ValuesTransformers may modify the merged values of each chart and its config before they are passed to the job
+	ValuesTransformers []render.ValuesTransformer
+	// TargetContextSelector selects the kubeconfig Secrets of virtual clusters that charts can be installed into;
+	// nil disables target contexts
+	TargetContextSelector labels.Selector
+
+	ConditionReady           = condition.Cond(helmv1.HelmChartReady)
+	ConditionUpgradesFrozen  = condition.Cond(helmv1.HelmChartUpgradesFrozen)
+	ConditionFailed          = condition.Cond(helmv1.HelmChartFailed)
+	ConditionRelocating      = condition.Cond(helmv1.HelmChartRelocating)
+	ConditionProgressing     = condition.Cond(helmv1.HelmChartProgressing)
+	ConditionRolledBack      = condition.Cond(helmv1.HelmChartRolledBack)
+	ConditionReleaseVerified = condition.Cond(helmv1.HelmChartReleaseVerified)
+	ConditionPaused          = condition.Cond(helmv1.HelmChartPaused)
+	ConditionSourceConflict  = condition.Cond(helmv1.HelmChartSourceConflict)
+	ConditionDrifted         = condition.Cond(helmv1.HelmChartDrifted)
+	ConditionRepoReachable   = condition.Cond(helmv1.HelmChartRepoReachable)
+
+	ConditionConfigValuesValid = condition.Cond(helmv1.HelmChartConfigValuesValid)
 )
 
 type Controller struct {
-	namespace      string
-	helmController helmcontroller.HelmChartController
-	confController helmcontroller.HelmChartConfigController
-	jobsCache      batchcontroller.JobCache
-	apply          apply.Apply
-	recorder       record.EventRecorder
+	namespace         string
+	helmController    helmcontroller.HelmChartController
+	confController    helmcontroller.HelmChartConfigController
+	summaryController helmcontroller.HelmChartSummaryController
+	jobs              batchcontroller.JobClient
+	jobsCache         batchcontroller.JobCache
+	jobsSynced        cache.InformerSynced
+	crbController     rbaccontroller.ClusterRoleBindingController
+	configMapCache    corecontroller.ConfigMapCache
+	secretController  corecontroller.SecretController
+	secretCache       corecontroller.SecretCache
+	namespaceCache    corecontroller.NamespaceCache
+	pods              typedv1.PodsGetter
+	apply             apply.Apply
+	dynamic           dynamic.Interface
+	restMapper        apimeta.RESTMapper
+	recorder          record.EventRecorder
 }
 
 const (
-	Label         = "helmcharts.helm.cattle.io/chart"
-	Annotation    = "helmcharts.helm.cattle.io/configHash"
-	Unmanaged     = "helmcharts.helm.cattle.io/unmanaged"
-	CRDName       = "helmcharts.helm.cattle.io"
-	ConfigCRDName = "helmchartconfigs.helm.cattle.io"
-	Name          = "helm-controller"
-
-	TaintExternalCloudProvider = "node.cloudprovider.kubernetes.io/uninitialized"
-	LabelNodeRolePrefix        = "node-role.kubernetes.io/"
-	LabelControlPlaneSuffix    = "control-plane"
-	LabelEtcdSuffix            = "etcd"
-
-	FailurePolicyReinstall = "reinstall"
-	FailurePolicyAbort     = "abort"
+	Label          = render.Label
+	Annotation     = render.Annotation
+	Unmanaged      = "helmcharts.helm.cattle.io/unmanaged"
+	CRDName        = "helmcharts.helm.cattle.io"
+	ConfigCRDName  = "helmchartconfigs.helm.cattle.io"
+	SummaryCRDName = "helmchartsummaries.helm.cattle.io"
+	// SummaryName is the name of the HelmChartSummary maintained by the controller
+	SummaryName = Name
+	// SummaryMaxFailures is the number of most recent failures listed for each namespace in the summary
+	SummaryMaxFailures = 5
+	Name               = "helm-controller"
+
+	TargetNamespaceUIDAnnotation = "helmcharts.helm.cattle.io/targetNamespaceUID"
+	MaxConcurrentJobsAnnotation  = "helmcharts.helm.cattle.io/maxConcurrentJobs"
+	FreezeAnnotation             = "helm.cattle.io/freeze"
+	RollbackAnnotation           = "helm.cattle.io/rollback-to"
+	PauseAnnotation              = "helm.cattle.io/pause"
+	// UninstallResourcesAnnotation holds the resources of the release of a chart with UninstallWait set that is
+	// being uninstalled, whose removal is verified once the delete job has succeeded
+	UninstallResourcesAnnotation = "helmcharts.helm.cattle.io/uninstallResources"
+
+	// TerminatingNamespacePolicyWait waits for the delete job to complete before removing charts in terminating namespaces
+	TerminatingNamespacePolicyWait = "wait"
+	// TerminatingNamespacePolicySkip removes charts in terminating namespaces without running the delete job
+	TerminatingNamespacePolicySkip = "skip"
+
+	TaintExternalCloudProvider = render.TaintExternalCloudProvider
+	LabelNodeRolePrefix        = render.LabelNodeRolePrefix
+	LabelControlPlaneSuffix    = render.LabelControlPlaneSuffix
+	LabelEtcdSuffix            = render.LabelEtcdSuffix
+
+	FailurePolicyReinstall = render.FailurePolicyReinstall
+	FailurePolicyAbort     = render.FailurePolicyAbort
 )
 
+// Register registers the controller's handlers, and returns the controller, which implements ChartReconciler,
+// JobBuilder and ValuesMerger.
 func Register(ctx context.Context,
 	k8s kubernetes.Interface,
+	dyn dynamic.Interface,
 	apply apply.Apply,
 	helms helmcontroller.HelmChartController,
 	confs helmcontroller.HelmChartConfigController,
+	summaries helmcontroller.HelmChartSummaryController,
 	jobs batchcontroller.JobController,
 	crbs rbaccontroller.ClusterRoleBindingController,
 	sas corecontroller.ServiceAccountController,
-	cm corecontroller.ConfigMapController) {
+	cm corecontroller.ConfigMapController,
+	secrets corecontroller.SecretController,
+	netpols networkingcontroller.NetworkPolicyController,
+	namespaces corecontroller.NamespaceController) *Controller {
 	apply = apply.WithSetID(Name).
-		WithCacheTypes(helms, confs, jobs, crbs, sas, cm).
-		WithStrictCaching().WithPatcher(batch.SchemeGroupVersion.WithKind("Job"), func(namespace, name string, pt types.PatchType, data []byte) (runtime.Object, error) {
-		err := jobs.Delete(namespace, name, &meta.DeleteOptions{PropagationPolicy: &deletePolicy})
+		WithCacheTypes(helms, confs, jobs, crbs, sas, cm, netpols).
+		WithStrictCaching().WithInjector(podFailurePolicyInjector(jobs.Cache())).WithPatcher(batch.SchemeGroupVersion.WithKind("Job"), func(namespace, name string, pt types.PatchType, data []byte) (runtime.Object, error) {
+		data, err := withoutPodFailurePolicy(data)
+		if err != nil {
+			return nil, err
+		}
+		if string(data) == "{}" {
+			return jobs.Cache().Get(namespace, name)
+		}
+		// changes to the job's metadata alone, such as its owner references, do not require the job to be re-run,
+		// and suspended jobs are resumed in place
+		var namespaceUID string
+		if existing, err := jobs.Cache().Get(namespace, name); err == nil {
+			namespaceUID = existing.Annotations[TargetNamespaceUIDAnnotation]
+		}
+		if inPlacePatch(data, namespaceUID) {
+			return jobs.Patch(namespace, name, pt, data)
+		}
+		err = jobs.Delete(namespace, name, &meta.DeleteOptions{PropagationPolicy: &deletePolicy})
 		if err == nil {
 			return nil, fmt.Errorf("replace job")
 		}
@@ -106,6 +270,68 @@ func Register(ctx context.Context,
 		confs,
 		jobs)
 
+	relatedresource.Watch(ctx, "helm-secret-watch",
+		func(namespace, name string, obj runtime.Object) ([]relatedresource.Key, error) {
+			secret, ok := obj.(*v1.Secret)
+			if !ok {
+				return nil, nil
+			}
+			charts, err := helms.Cache().List(namespace, labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			var keys []relatedresource.Key
+			for _, chart := range charts {
+				if contentSecret := chart.Spec.ChartContentSecret; (contentSecret != nil && contentSecret.Name == name) || setFilesSecret(chart, name) || targetContextSecret(chart, secret) {
+					keys = append(keys, relatedresource.NewKey(chart.Namespace, chart.Name))
+				}
+			}
+			return keys, nil
+		},
+		helms,
+		secrets)
+
+	relatedresource.Watch(ctx, "helm-configmap-watch",
+		func(namespace, name string, obj runtime.Object) ([]relatedresource.Key, error) {
+			if _, ok := obj.(*v1.ConfigMap); !ok {
+				return nil, nil
+			}
+			charts, err := helms.Cache().List(namespace, labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			var keys []relatedresource.Key
+			for _, chart := range charts {
+				if setFilesConfigMap(chart, name) || caBundleConfigMap(chart) == name {
+					keys = append(keys, relatedresource.NewKey(chart.Namespace, chart.Name))
+				}
+			}
+			return keys, nil
+		},
+		helms,
+		cm)
+
+	relatedresource.Watch(ctx, "helm-namespace-watch",
+		func(_, name string, obj runtime.Object) ([]relatedresource.Key, error) {
+			if _, ok := obj.(*v1.Namespace); !ok {
+				return nil, nil
+			}
+			charts, err := helms.Cache().List("", labels.Everything())
+			if err != nil {
+				return nil, err
+			}
+			var keys []relatedresource.Key
+			for _, chart := range charts {
+				// charts in the namespace are resumed when it is no longer paused
+				if chart.Spec.TargetNamespace == name || chart.Namespace == name {
+					keys = append(keys, relatedresource.NewKey(chart.Namespace, chart.Name))
+				}
+			}
+			return keys, nil
+		},
+		helms,
+		namespaces)
+
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(logrus.Infof)
 	eventBroadcaster.StartRecordingToSink(&typedv1.EventSinkImpl{Interface: k8s.CoreV1().Events(meta.NamespaceSystem)})
@@ -115,87 +341,313 @@ func Register(ctx context.Context,
 	}
 
 	controller := &Controller{
-		helmController: helms,
-		confController: confs,
-		jobsCache:      jobs.Cache(),
-		apply:          apply,
-		recorder:       eventBroadcaster.NewRecorder(schemes.All, eventSource),
+		helmController:    helms,
+		confController:    confs,
+		summaryController: summaries,
+		jobs:              jobs,
+		jobsCache:         jobs.Cache(),
+		jobsSynced:        jobs.Informer().HasSynced,
+		crbController:     crbs,
+		configMapCache:    cm.Cache(),
+		secretController:  secrets,
+		secretCache:       secrets.Cache(),
+		namespaceCache:    namespaces.Cache(),
+		pods:              k8s.CoreV1(),
+		apply:             apply,
+		dynamic:           dyn,
+		restMapper:        restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(k8s.Discovery())),
+		recorder:          eventPolicyRecorder{eventBroadcaster.NewRecorder(schemes.All, eventSource)},
+	}
+
+	jobs.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{DeleteFunc: controller.onJobDelete})
+	if StreamJobLogs {
+		jobs.OnChange(ctx, "helm-job-logs", func(key string, job *batch.Job) (*batch.Job, error) {
+			return controller.streamJobLogs(ctx, key, job)
+		})
 	}
 
-	helms.OnChange(ctx, Name, controller.OnHelmChange)
+	helms.OnChange(ctx, Name, controller.withErrorBudget(controller.OnHelmChange))
 	helms.OnRemove(ctx, Name, controller.OnHelmRemove)
 	confs.OnChange(ctx, Name, controller.OnConfChange)
 	confs.OnRemove(ctx, Name, controller.OnConfChange)
+	helms.OnChange(ctx, "helm-summary", controller.OnChartSummaryChange)
+	summaries.OnChange(ctx, Name, controller.OnSummaryChange)
+	summaries.Enqueue(SummaryName)
+
+	go controller.runClusterRoleBindingGC(ctx)
+	return controller
 }
 
 func (c *Controller) OnHelmChange(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
 	if chart == nil {
 		return nil, nil
 	}
-	if chart.Spec.Chart == "" && chart.Spec.ChartContent == "" {
+	if !hasChart(chart) {
 		return chart, nil
 	}
+	if err := c.auditSpecChange(chart); err != nil {
+		return chart, err
+	}
 	if _, ok := chart.Annotations[Unmanaged]; ok {
 		return chart, nil
 	}
-
-	failurePolicy := DefaultFailurePolicy
-	objs := objectset.NewObjectSet()
-	job, valuesConfigMap, contentConfigMap := job(chart)
-	objs.Add(serviceAccount(chart))
-	objs.Add(roleBinding(chart))
-
-	if chart.Spec.FailurePolicy != "" {
-		failurePolicy = chart.Spec.FailurePolicy
+	if paused, err := c.paused(chart); err != nil {
+		return chart, err
+	} else if paused {
+		return c.setPausedCondition(chart)
 	}
 
-	if config, err := c.confController.Cache().Get(chart.Namespace, chart.Name); err != nil {
+	config, err := c.confController.Cache().Get(ConfigKey(chart))
+	if err != nil {
 		if !errors.IsNotFound(err) {
 			return chart, err
 		}
-	} else if config != nil {
-		valuesConfigMapAddConfig(valuesConfigMap, config)
-		if config.Spec.FailurePolicy != "" {
-			failurePolicy = config.Spec.FailurePolicy
+	}
+
+	if chart.DeletionTimestamp == nil {
+		if err := render.ValidateSet(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateSetFieldRefs(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateChartContentSize(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateTimeout(chart, MaxTimeout); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateValuesContent(chart, config); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateHelmPlugins(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateChartChecksum(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateServiceAccount(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateRestartPolicy(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidatePodFailurePolicy(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateCredentialsMountMode(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateTargetNamespacePolicy(chart); err != nil {
+			return c.invalidSpec(chart, err)
 		}
+		if err := render.ValidateFailurePolicyRetries(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateNodeName(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateTargetContext(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+		if err := render.ValidateCommonMetadata(chart); err != nil {
+			return c.invalidSpec(chart, err)
+		}
+	}
+
+	if !c.jobsCacheSynced(chart) {
+		return chart, nil
+	}
+
+	if DryRun {
+		objs, err := c.renderObjects(chart, config, c.renderOptions())
+		if err != nil {
+			return chart, err
+		}
+		c.dryRun(chart, objs)
+		return chart, nil
+	}
+
+	if updated, ok, err := c.rollback(chart, config); err != nil || !ok {
+		return updated, err
+	}
+
+	if updated, ok, err := c.relocateRelease(chart, config); err != nil || !ok {
+		return updated, err
 	}
 
-	setFailurePolicy(job, failurePolicy)
-	hashConfigMaps(job, contentConfigMap, valuesConfigMap)
+	objs, err := c.renderObjects(chart, config, c.renderOptions())
+	if err != nil {
+		return chart, err
+	}
+	jobName := render.JobName(chart)
+
+	if err := c.retainConfigMapRevisions(chart, objs); err != nil {
+		return chart, err
+	}
+
+	namespaceFound, err := c.setTargetNamespaceUID(chart, objs)
+	if err != nil {
+		return chart, err
+	}
+
+	if frozen(chart) {
+		chartCopy := chart.DeepCopy()
+		c.setUpgradesFrozen(chartCopy, objs)
+		return c.updateStatus(chartCopy)
+	}
+
+	blocker, err := c.bootstrapBlocker(chart, objs)
+	if err != nil {
+		return chart, err
+	}
+	if blocker != nil {
+		chartCopy := chart.DeepCopy()
+		c.setWaitingForBootstrap(chartCopy, blocker)
+		c.setDriftCondition(chartCopy, objs, time.Now())
+		c.helmController.EnqueueAfter(chart.Namespace, chart.Name, bootstrapRetryInterval)
+		return c.updateStatus(chartCopy)
+	}
 
-	objs.Add(contentConfigMap)
-	objs.Add(valuesConfigMap)
-	objs.Add(job)
+	available, err := c.jobSlotAvailable(chart, objs)
+	if err != nil {
+		return chart, err
+	}
+	if !available {
+		chartCopy := chart.DeepCopy()
+		if ConditionReady.GetReason(chartCopy) != "JobQueued" {
+			c.recorder.Eventf(chart, core.EventTypeNormal, "JobQueued", "Waiting for a free job slot in namespace %s", chart.Namespace)
+		}
+		ConditionReady.False(chartCopy)
+		ConditionReady.Reason(chartCopy, "JobQueued")
+		ConditionReady.Message(chartCopy, fmt.Sprintf("waiting for a free job slot in namespace %s", chart.Namespace))
+		c.setDriftCondition(chartCopy, objs, time.Now())
+		c.helmController.EnqueueAfter(chart.Namespace, chart.Name, JobQueueRetryInterval)
+		return c.updateStatus(chartCopy)
+	}
 
-	c.recorder.Eventf(chart, core.EventTypeNormal, "ApplyJob", "Applying HelmChart using Job %s/%s", job.Namespace, job.Name)
+	if LogApplyPlan {
+		c.logApplyPlan(chart, objs)
+	}
+	if chart.Spec.Force && chart.DeletionTimestamp == nil && !c.jobUpToDate(chart, objs) {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "ForceUpgrade", "Upgrading HelmChart with --force, which deletes and re-creates resources that cannot be updated in place, such as Services")
+	}
+	if overrides := render.SetOverrides(chart, config); len(overrides) > 0 && chart.DeletionTimestamp == nil && !c.jobUpToDate(chart, objs) {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "SetOverridesValues", "Values set by %s take precedence over the same keys in the values content", strings.Join(overrides, ", "))
+	}
+	history := c.jobHistory(chart, objs, time.Now())
+	repoCheck := c.checkRepo(chart, objs)
+	c.recorder.Eventf(chart, core.EventTypeNormal, "ApplyJob", "Applying HelmChart using Job %s/%s", chart.Namespace, jobName)
 	if err := c.apply.WithOwner(chart).Apply(objs); err != nil {
 		return chart, err
 	}
 
 	chartCopy := chart.DeepCopy()
-	chartCopy.Status.JobName = job.Name
-	return c.helmController.Update(chartCopy)
+	chartCopy.Status.JobName = jobName
+	chartCopy.Status.JobImage = jobImage(objs)
+	if chart.DeletionTimestamp == nil {
+		chartCopy.Status.TargetNamespace = render.TargetNamespace(chart)
+		chartCopy.Status.ReleaseName = render.ReleaseName(chart)
+	}
+	c.setReadyCondition(chartCopy, objs, namespaceFound)
+	c.setDriftCondition(chartCopy, objs, time.Now())
+	c.setSourceConflictCondition(chartCopy)
+	c.setRepoReachableCondition(chartCopy, repoCheck)
+	setRelocatedCondition(chartCopy)
+	addHistory(chartCopy, append(history, conditionHistory(chart, chartCopy, time.Now())...)...)
+	if ConditionReady.IsTrue(chartCopy) && !ConditionReady.IsTrue(chart) && chart.DeletionTimestamp == nil {
+		if err := c.verifyRelease(chartCopy); err != nil {
+			logrus.Warnf("Failed to check for resources orphaned by upgrade of HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+		}
+		if err := c.setReleaseStatus(chartCopy); err != nil {
+			logrus.Warnf("Failed to get installed release of HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+		}
+	}
+	if err := c.verifyReleaseExists(chartCopy); err != nil {
+		logrus.Warnf("Failed to verify release of HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+	}
+	if ConditionUpgradesFrozen.GetStatus(chartCopy) != "" {
+		ConditionUpgradesFrozen.False(chartCopy)
+		ConditionUpgradesFrozen.Reason(chartCopy, "")
+		ConditionUpgradesFrozen.Message(chartCopy, "")
+	}
+	if ConditionPaused.GetStatus(chartCopy) != "" {
+		ConditionPaused.False(chartCopy)
+		ConditionPaused.Reason(chartCopy, "")
+		ConditionPaused.Message(chartCopy, "")
+	}
+	if config != nil && ConditionReady.GetStatus(chartCopy) != ConditionReady.GetStatus(chart) {
+		c.enqueueConfig(chartCopy)
+	}
+	updated, err := c.updateStatus(chartCopy)
+	if err == nil && jobSucceeded(chartCopy) && !jobSucceeded(chart) {
+		c.enqueueBootstrapDependents(chartCopy)
+	}
+	return updated, err
 }
 
 func (c *Controller) OnHelmRemove(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
 	if chart == nil {
 		return nil, nil
 	}
-	if chart.Spec.Chart == "" {
+	if !hasChart(chart) {
 		return chart, nil
 	}
 	if _, ok := chart.Annotations[Unmanaged]; ok {
 		return chart, nil
 	}
 
-	job, _, _ := job(chart)
-	job, err := c.jobsCache.Get(chart.Namespace, job.Name)
-
-	if errors.IsNotFound(err) {
-		_, err := c.OnHelmChange(key, chart)
+	if DryRun {
+		config, err := c.confController.Cache().Get(ConfigKey(chart))
+		if err != nil && !errors.IsNotFound(err) {
+			return chart, err
+		}
+		objs, err := render.Objects(chart, config, c.renderOptions())
 		if err != nil {
 			return chart, err
 		}
+		c.dryRun(chart, objs)
+		// keep the finalizer, without requeueing, until the controller is restarted without DryRun
+		return chart, generic.ErrSkip
+	}
+
+	if !c.jobsCacheSynced(chart) {
+		return chart, generic.ErrSkip
+	}
+	// the chart no longer counts as a target of its config
+	c.enqueueConfig(chart)
+
+	if skip, err := c.skipDeleteJob(chart); err != nil {
+		return chart, err
+	} else if skip {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "DeleteJobSkipped", "Namespace %s is terminating; removing HelmChart without uninstalling release %s/%s", chart.Namespace, render.TargetNamespace(chart), render.ReleaseName(chart))
+		forgetChart(chart)
+		forgetAudit(chart)
+		forgetUninstall(chart)
+		forgetDrift(chart)
+		forgetTransient(chart)
+		return chart, c.apply.WithOwner(chart).Apply(objectset.NewObjectSet())
+	}
+	if gone, err := c.targetContextGone(chart); err != nil {
+		return chart, err
+	} else if gone {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "DeleteJobSkipped", "Target context %s no longer exists; removing HelmChart without uninstalling release %s/%s", chart.Spec.TargetContext, render.TargetNamespace(chart), render.ReleaseName(chart))
+		forgetChart(chart)
+		forgetAudit(chart)
+		forgetUninstall(chart)
+		forgetDrift(chart)
+		forgetTransient(chart)
+		return chart, c.apply.WithOwner(chart).Apply(objectset.NewObjectSet())
+	}
+
+	chart, err := c.recordUninstallResources(chart)
+	if err != nil {
+		return chart, err
+	}
+
+	job, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart))
+	if errors.IsNotFound(err) {
+		return chart, c.createDeleteJob(key, chart)
 	} else if err != nil {
 		return chart, err
 	}
@@ -203,429 +655,573 @@ func (c *Controller) OnHelmRemove(key string, chart *helmv1.HelmChart) (*helmv1.
 	if job.Status.Succeeded <= 0 {
 		return chart, fmt.Errorf("waiting for delete of helm chart for %s by %s", key, job.Name)
 	}
+	if err := c.verifyUninstall(chart); err != nil {
+		return chart, err
+	}
 
 	chartCopy := chart.DeepCopy()
 	chartCopy.Status.JobName = job.Name
-	newChart, err := c.helmController.Update(chartCopy)
+	newChart, err := c.updateStatus(chartCopy)
 
 	if err != nil {
 		return newChart, err
 	}
 
+	forgetChart(newChart)
+	forgetAudit(newChart)
+	forgetUninstall(newChart)
+	forgetDrift(newChart)
+	forgetTransient(newChart)
 	return newChart, c.apply.WithOwner(newChart).Apply(objectset.NewObjectSet())
 }
 
-func (c *Controller) OnConfChange(key string, conf *helmv1.HelmChartConfig) (*helmv1.HelmChartConfig, error) {
-	if conf == nil {
-		return nil, nil
+// jobsCacheSynced returns true once the jobs cache has synced. Until then, the chart is requeued, as the cache may
+// be missing the chart's job, and decisions such as creating the job or deleting the chart once the job has
+// succeeded must not be made from a cold cache.
+func (c *Controller) jobsCacheSynced(chart *helmv1.HelmChart) bool {
+	if c.jobsSynced() {
+		return true
 	}
+	logrus.Debugf("Waiting for jobs cache to sync before reconciling HelmChart %s/%s", chart.Namespace, chart.Name)
+	c.helmController.EnqueueAfter(chart.Namespace, chart.Name, CacheSyncRetryInterval)
+	return false
+}
 
-	if chart, err := c.helmController.Cache().Get(conf.Namespace, conf.Name); err != nil {
-		if !errors.IsNotFound(err) {
-			return conf, err
-		}
-	} else if chart != nil {
-		c.helmController.EnqueueAfter(conf.Namespace, conf.Name, time.Second)
+// onJobDelete requeues the chart that a deleted job belongs to, so that a job deleted by hand is re-created
+// straight away, instead of when the chart is next changed or resynced. Jobs deleted by the controller itself, when
+// they are replaced, are also requeued, which is harmless.
+func (c *Controller) onJobDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	job, ok := obj.(*batch.Job)
+	if !ok {
+		return
+	}
+	name := job.Labels[Label]
+	if name == "" {
+		return
 	}
-	return conf, nil
+	logrus.Debugf("Job %s/%s for HelmChart %s/%s was deleted", job.Namespace, job.Name, job.Namespace, name)
+	c.helmController.Enqueue(job.Namespace, name)
 }
 
-func job(chart *helmv1.HelmChart) (*batch.Job, *core.ConfigMap, *core.ConfigMap) {
-	jobImage := strings.TrimSpace(chart.Spec.JobImage)
-	if jobImage == "" {
-		jobImage = DefaultJobImage
+// createDeleteJob applies the job that uninstalls the chart's release. Unlike OnHelmChange, the chart's status
+// is not updated and no install events are emitted, as the chart is going away. An error is always returned, so
+// that the chart's finalizer is kept until the job has been seen to succeed.
+func (c *Controller) createDeleteJob(key string, chart *helmv1.HelmChart) error {
+	config, err := c.confController.Cache().Get(ConfigKey(chart))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
 	}
 
-	action := "install"
-	if chart.DeletionTimestamp != nil {
-		action = "delete"
+	objs, err := render.Objects(chart, config, c.renderOptions())
+	if err != nil {
+		return err
 	}
+	jobName := render.JobName(chart)
 
-	targetNamespace := chart.Namespace
-	if len(chart.Spec.TargetNamespace) != 0 {
-		targetNamespace = chart.Spec.TargetNamespace
+	available, err := c.jobSlotAvailable(chart, objs)
+	if err != nil {
+		return err
 	}
-
-	job := &batch.Job{
-		TypeMeta: meta.TypeMeta{
-			APIVersion: "batch/v1",
-			Kind:       "Job",
-		},
-		ObjectMeta: meta.ObjectMeta{
-			Name:      fmt.Sprintf("helm-%s-%s", action, chart.Name),
-			Namespace: chart.Namespace,
-			Labels: map[string]string{
-				Label: chart.Name,
-			},
-		},
-		Spec: batch.JobSpec{
-			BackoffLimit: pointer.Int32Ptr(1000),
-			Template: core.PodTemplateSpec{
-				ObjectMeta: meta.ObjectMeta{
-					Annotations: map[string]string{},
-					Labels: map[string]string{
-						Label: chart.Name,
-					},
-				},
-				Spec: core.PodSpec{
-					RestartPolicy: core.RestartPolicyOnFailure,
-					Containers: []core.Container{
-						{
-							Name:            "helm",
-							Image:           jobImage,
-							ImagePullPolicy: core.PullIfNotPresent,
-							Args:            args(chart),
-							Env: []core.EnvVar{
-								{
-									Name:  "NAME",
-									Value: chart.Name,
-								},
-								{
-									Name:  "VERSION",
-									Value: chart.Spec.Version,
-								},
-								{
-									Name:  "REPO",
-									Value: chart.Spec.Repo,
-								},
-								{
-									Name:  "HELM_DRIVER",
-									Value: "secret",
-								},
-								{
-									Name:  "CHART_NAMESPACE",
-									Value: chart.Namespace,
-								},
-								{
-									Name:  "CHART",
-									Value: chart.Spec.Chart,
-								},
-								{
-									Name:  "HELM_VERSION",
-									Value: chart.Spec.HelmVersion,
-								},
-								{
-									Name:  "TARGET_NAMESPACE",
-									Value: targetNamespace,
-								},
-							},
-						},
-					},
-					ServiceAccountName: fmt.Sprintf("helm-%s", chart.Name),
-				},
-			},
-		},
+	if !available {
+		return fmt.Errorf("waiting for a free job slot in namespace %s to delete helm chart for %s", chart.Namespace, key)
 	}
 
-	if chart.Spec.Timeout != nil {
-		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
-			Name:  "TIMEOUT",
-			Value: chart.Spec.Timeout.Duration.String(),
-		})
+	if err := c.apply.WithOwner(chart).Apply(objs); err != nil {
+		return err
 	}
+	c.recorder.Eventf(chart, core.EventTypeNormal, "UninstallJobCreated", "Uninstalling HelmChart using Job %s/%s", chart.Namespace, jobName)
+	return fmt.Errorf("waiting for delete of helm chart for %s by %s", key, jobName)
+}
 
-	job.Spec.Template.Spec.NodeSelector = make(map[string]string)
-	job.Spec.Template.Spec.NodeSelector[core.LabelOSStable] = "linux"
+// hasChart returns true if the chart specifies a chart to install, by reference or by content.
+func hasChart(chart *helmv1.HelmChart) bool {
+	return chart.Spec.Chart != "" || chart.Spec.ChartContent != "" || chart.Spec.ChartContentSecret != nil
+}
 
-	if chart.Spec.Bootstrap {
-		job.Spec.Template.Spec.NodeSelector[LabelNodeRolePrefix+LabelControlPlaneSuffix] = "true"
-		job.Spec.Template.Spec.HostNetwork = true
-		job.Spec.Template.Spec.Tolerations = []core.Toleration{
-			{
-				Key:    core.TaintNodeNotReady,
-				Effect: core.TaintEffectNoSchedule,
-			},
-			{
-				Key:      TaintExternalCloudProvider,
-				Operator: core.TolerationOpEqual,
-				Value:    "true",
-				Effect:   core.TaintEffectNoSchedule,
-			},
-			{
-				Key:      "CriticalAddonsOnly",
-				Operator: core.TolerationOpExists,
-			},
-			{
-				Key:      LabelNodeRolePrefix + LabelEtcdSuffix,
-				Operator: core.TolerationOpExists,
-				Effect:   core.TaintEffectNoExecute,
-			},
-			{
-				Key:      LabelNodeRolePrefix + LabelControlPlaneSuffix,
-				Operator: core.TolerationOpExists,
-				Effect:   core.TaintEffectNoSchedule,
-			},
-		}
-		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, []core.EnvVar{
-			{
-				Name:  "KUBERNETES_SERVICE_HOST",
-				Value: "127.0.0.1"},
-			{
-				Name:  "KUBERNETES_SERVICE_PORT",
-				Value: "6443"},
-			{
-				Name:  "BOOTSTRAP",
-				Value: "true"},
-		}...)
+// skipDeleteJob returns true if the chart's namespace is terminating and TerminatingNamespacePolicy allows
+// the delete job to be skipped. New jobs cannot be created in a terminating namespace, and existing jobs often
+// cannot be scheduled, so waiting for the delete job would block the namespace from ever being deleted.
+func (c *Controller) skipDeleteJob(chart *helmv1.HelmChart) (bool, error) {
+	if TerminatingNamespacePolicy != TerminatingNamespacePolicySkip {
+		return false, nil
 	}
+	namespace, err := c.namespaceCache.Get(chart.Namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return namespaceTerminating(namespace), nil
+}
 
-	setProxyEnv(job)
-	valueConfigMap := setValuesConfigMap(job, chart)
-	contentConfigMap := setContentConfigMap(job, chart)
-
-	return job, valueConfigMap, contentConfigMap
+// namespaceTerminating returns true if the namespace is being deleted, or is already gone.
+func namespaceTerminating(namespace *core.Namespace) bool {
+	return namespace == nil || namespace.DeletionTimestamp != nil || namespace.Status.Phase == core.NamespaceTerminating
 }
 
-func valuesConfigMap(chart *helmv1.HelmChart) *core.ConfigMap {
-	var configMap = &core.ConfigMap{
-		TypeMeta: meta.TypeMeta{
-			APIVersion: "v1",
-			Kind:       "ConfigMap",
-		},
-		ObjectMeta: meta.ObjectMeta{
-			Name:      fmt.Sprintf("chart-values-%s", chart.Name),
-			Namespace: chart.Namespace,
-		},
-		Data: map[string]string{},
+func (c *Controller) OnConfChange(key string, conf *helmv1.HelmChartConfig) (*helmv1.HelmChartConfig, error) {
+	if conf == nil {
+		return nil, nil
 	}
 
-	if chart.Spec.ValuesContent != "" {
-		configMap.Data["values-01_HelmChart.yaml"] = chart.Spec.ValuesContent
+	charts, err := c.helmController.Cache().List("", labels.Everything())
+	if err != nil {
+		return conf, err
 	}
-	if chart.Spec.RepoCA != "" {
-		configMap.Data["ca-file.pem"] = chart.Spec.RepoCA
+
+	// charts only need to be updated when the spec has changed, not when only the status has been updated
+	changed := conf.DeletionTimestamp != nil || conf.Generation != conf.Status.ObservedGeneration
+	var targets []*helmv1.HelmChart
+	for _, chart := range charts {
+		if namespace, name := ConfigKey(chart); namespace == conf.Namespace && name == conf.Name {
+			if changed {
+				c.helmController.EnqueueAfter(chart.Namespace, chart.Name, time.Second)
+			}
+			if chart.DeletionTimestamp == nil {
+				targets = append(targets, chart)
+			}
+		}
 	}
+	if len(targets) == 0 && conf.DeletionTimestamp == nil {
+		c.checkConfPlacement(conf, charts)
+	}
+	return c.updateConfigStatus(conf, targets)
+}
 
-	return configMap
+// ConfigKey returns the namespace and name of the HelmChartConfig that applies to the chart. Unless the chart
+// references a config by name, this is the config with the same namespace and name as the chart.
+func ConfigKey(chart *helmv1.HelmChart) (string, string) {
+	ref := chart.Spec.HelmChartConfigRef
+	if ref == nil || ref.Name == "" {
+		return chart.Namespace, chart.Name
+	}
+	if ref.Namespace == "" {
+		return chart.Namespace, ref.Name
+	}
+	return ref.Namespace, ref.Name
 }
 
-func valuesConfigMapAddConfig(configMap *core.ConfigMap, config *helmv1.HelmChartConfig) {
-	if config.Spec.ValuesContent != "" {
-		configMap.Data["values-10_HelmChartConfig.yaml"] = config.Spec.ValuesContent
+// checkConfPlacement warns about a HelmChartConfig that does not apply to any HelmChart, but shares its name
+// with charts in other namespaces. Unless referenced by a chart's HelmChartConfigRef, configs only apply to
+// the chart in the same namespace, so this is most likely a config that was created in the wrong namespace.
+func (c *Controller) checkConfPlacement(conf *helmv1.HelmChartConfig, charts []*helmv1.HelmChart) {
+	var namespaces []string
+	for _, chart := range charts {
+		if chart.Name == conf.Name && chart.Namespace != conf.Namespace && chart.Spec.HelmChartConfigRef == nil {
+			namespaces = append(namespaces, chart.Namespace)
+			c.recorder.Eventf(chart, core.EventTypeWarning, "ConfigNamespaceMismatch", "HelmChartConfig %s/%s does not apply to this chart; it must be created in namespace %s, or referenced by spec.helmChartConfigRef", conf.Namespace, conf.Name, chart.Namespace)
+		}
+	}
+	if len(namespaces) > 0 {
+		sort.Strings(namespaces)
+		c.recorder.Eventf(conf, core.EventTypeWarning, "ChartNotFound", "HelmChart %s/%s does not exist; HelmChartConfig must be in the same namespace as its HelmChart, which exists in namespace %s", conf.Namespace, conf.Name, strings.Join(namespaces, ", "))
 	}
 }
 
-func roleBinding(chart *helmv1.HelmChart) *rbac.ClusterRoleBinding {
-	return &rbac.ClusterRoleBinding{
-		TypeMeta: meta.TypeMeta{
-			APIVersion: "rbac.authorization.k8s.io/v1",
-			Kind:       "ClusterRoleBinding",
-		},
-		ObjectMeta: meta.ObjectMeta{
-			Name: fmt.Sprintf("helm-%s-%s", chart.Namespace, chart.Name),
-		},
-		RoleRef: rbac.RoleRef{
-			Kind:     "ClusterRole",
-			APIGroup: "rbac.authorization.k8s.io",
-			Name:     "cluster-admin",
-		},
-		Subjects: []rbac.Subject{
-			{
-				Name:      fmt.Sprintf("helm-%s", chart.Name),
-				Kind:      "ServiceAccount",
-				Namespace: chart.Namespace,
-			},
-		},
+// setFilesConfigMap returns true if the chart's SetFiles reference a key of the named ConfigMap.
+func setFilesConfigMap(chart *helmv1.HelmChart, name string) bool {
+	for _, file := range render.SetFiles(chart) {
+		if file.ConfigMapKeyRef != nil && file.ConfigMapKeyRef.Name == name {
+			return true
+		}
 	}
+	return false
 }
 
-func serviceAccount(chart *helmv1.HelmChart) *core.ServiceAccount {
-	return &core.ServiceAccount{
-		TypeMeta: meta.TypeMeta{
-			APIVersion: "v1",
-			Kind:       "ServiceAccount",
-		},
-		ObjectMeta: meta.ObjectMeta{
-			Name:      fmt.Sprintf("helm-%s", chart.Name),
-			Namespace: chart.Namespace,
-		},
-		AutomountServiceAccountToken: pointer.BoolPtr(true),
+// caBundleConfigMap returns the name of the ConfigMap holding the CA bundle used by the chart's jobs, if any.
+func caBundleConfigMap(chart *helmv1.HelmChart) string {
+	if caBundle := render.CABundle(chart, render.Options{CABundle: CABundle}); caBundle != nil {
+		return caBundle.Name
 	}
+	return ""
 }
 
-func args(chart *helmv1.HelmChart) []string {
-	if chart.DeletionTimestamp != nil {
-		return []string{
-			"delete",
+// setFilesSecret returns true if the chart's SetFiles or SetFrom reference a key of the named Secret.
+func setFilesSecret(chart *helmv1.HelmChart, name string) bool {
+	for _, file := range render.SetFiles(chart) {
+		if file.SecretKeyRef != nil && file.SecretKeyRef.Name == name {
+			return true
 		}
 	}
+	return false
+}
 
-	spec := chart.Spec
-	args := []string{
-		"install",
+// invalidSpec reports a chart whose spec cannot be rendered into a valid job. The chart is not retried,
+// as it will not become valid until it is changed. When DryRun is set, the event is recorded but the status is
+// left as it is.
+func (c *Controller) invalidSpec(chart *helmv1.HelmChart, err error) (*helmv1.HelmChart, error) {
+	if DryRun {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "InvalidSpec", "Not applying HelmChart: %v", err)
+		return chart, nil
 	}
-	if spec.TargetNamespace != "" {
-		args = append(args, "--namespace", spec.TargetNamespace)
+	chartCopy := chart.DeepCopy()
+	if ConditionReady.GetReason(chartCopy) != "InvalidSpec" || ConditionReady.GetMessage(chartCopy) != err.Error() {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "InvalidSpec", "Not applying HelmChart: %v", err)
 	}
-	if spec.Repo != "" {
-		args = append(args, "--repo", spec.Repo)
+	ConditionReady.False(chartCopy)
+	ConditionReady.Reason(chartCopy, "InvalidSpec")
+	ConditionReady.Message(chartCopy, err.Error())
+	return c.updateStatus(chartCopy)
+}
+
+// setTargetNamespaceUID checks that the chart's target namespace exists, returning false if it does not. Charts
+// that set CreateNamespace are not expected to find their target namespace until their job has created it.
+// If ReinstallOnNamespaceRecreate is enabled, the UID of the target namespace is recorded on the job, so that
+// the job is replaced and the chart re-installed if the namespace is deleted and later re-created.
+func (c *Controller) setTargetNamespaceUID(chart *helmv1.HelmChart, objs *objectset.ObjectSet) (bool, error) {
+	if chart.Spec.TargetNamespace == "" || chart.Spec.TargetNamespace == chart.Namespace || chart.DeletionTimestamp != nil || !localRelease(chart) {
+		return true, nil
 	}
-	if spec.Version != "" {
-		args = append(args, "--version", spec.Version)
+
+	var uid string
+	namespace, err := c.namespaceCache.Get(chart.Spec.TargetNamespace)
+	if err == nil {
+		uid = string(namespace.UID)
+	} else if errors.IsNotFound(err) {
+		// keep the UID recorded on the current job, so that the job is not replaced while the namespace is missing
+		if job, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart)); err == nil {
+			uid = job.Annotations[TargetNamespaceUIDAnnotation]
+		}
+	} else {
+		return false, err
 	}
 
-	for _, k := range keys(spec.Set) {
-		val := spec.Set[k]
-		if typedVal(val) {
-			args = append(args, "--set", fmt.Sprintf("%s=%s", k, val.String()))
-		} else {
-			args = append(args, "--set-string", fmt.Sprintf("%s=%s", k, commaRE.ReplaceAllStringFunc(val.String(), escapeComma)))
+	if job := renderedJob(objs); job != nil && ReinstallOnNamespaceRecreate && uid != "" {
+		if job.Annotations == nil {
+			job.Annotations = map[string]string{}
 		}
+		job.Annotations[TargetNamespaceUIDAnnotation] = uid
 	}
 
-	return args
+	return (namespace != nil && err == nil) || chart.Spec.CreateNamespace, nil
 }
 
-func keys(val map[string]intstr.IntOrString) []string {
-	var keys []string
-	for k := range val {
-		keys = append(keys, k)
+// setReadyCondition sets the Ready condition based on the state of the chart's target namespace and job.
+// The chart is only considered ready once the job for the current chart configuration has succeeded.
+func (c *Controller) setReadyCondition(chart *helmv1.HelmChart, objs *objectset.ObjectSet, namespaceFound bool) {
+	if !namespaceFound {
+		if ConditionReady.GetReason(chart) != "TargetNamespaceNotFound" {
+			c.recorder.Eventf(chart, core.EventTypeWarning, "TargetNamespaceNotFound", "Target namespace %s does not exist", chart.Spec.TargetNamespace)
+		}
+		ConditionReady.False(chart)
+		ConditionReady.Reason(chart, "TargetNamespaceNotFound")
+		ConditionReady.Message(chart, fmt.Sprintf("target namespace %s does not exist", chart.Spec.TargetNamespace))
+		return
+	}
+
+	job, err := c.jobsCache.Get(chart.Namespace, chart.Status.JobName)
+	if err != nil || !jobMatches(job, renderedJob(objs)) {
+		job = nil
+	}
+	observeJob(chart, job, c.jobRestarts(context.TODO(), job))
+	c.setProgressingCondition(chart, job)
+
+	if failure := jobFailure(job); failure != nil {
+		if !ConditionFailed.IsTrue(chart) {
+			c.recorder.Eventf(chart, core.EventTypeWarning, "JobFailed", "Job %s/%s failed: %s", chart.Namespace, job.Name, failure.Message)
+		}
+		ConditionFailed.True(chart)
+		ConditionFailed.Reason(chart, failure.Reason)
+		ConditionFailed.Message(chart, failure.Message)
+		ConditionReady.False(chart)
+		ConditionReady.Reason(chart, "JobFailed")
+		ConditionReady.Message(chart, fmt.Sprintf("job %s/%s failed; the chart will not be retried until it is changed", chart.Namespace, job.Name))
+		return
+	}
+	if ConditionFailed.GetStatus(chart) != "" {
+		ConditionFailed.False(chart)
+		ConditionFailed.Reason(chart, "")
+		ConditionFailed.Message(chart, "")
+	}
+
+	if job != nil && job.Status.Succeeded > 0 {
+		ConditionReady.True(chart)
+		ConditionReady.Reason(chart, "JobSucceeded")
+		ConditionReady.Message(chart, "")
+		return
 	}
-	sort.Strings(keys)
-	return keys
+
+	if job != nil && job.Spec.Suspend != nil && *job.Spec.Suspend {
+		ConditionReady.False(chart)
+		ConditionReady.Reason(chart, "JobSuspended")
+		ConditionReady.Message(chart, fmt.Sprintf("job %s/%s is suspended until spec.jobSuspend is cleared", chart.Namespace, job.Name))
+		return
+	}
+
+	ConditionReady.False(chart)
+	ConditionReady.Reason(chart, "JobPending")
+	ConditionReady.Message(chart, fmt.Sprintf("waiting for job %s/%s to succeed", chart.Namespace, chart.Status.JobName))
 }
 
-// typedVal is a modified version of helm's typedVal function that operates on kubernetes IntOrString types.
-// Things that look like an integer, boolean, or null should use --set; everything else should use --set-string.
-// Ref: https://github.com/helm/helm/blob/v3.5.4/pkg/strvals/parser.go#L415
-func typedVal(val intstr.IntOrString) bool {
-	if intstr.Int == val.Type {
-		return true
+// setProgressingCondition sets the Progressing condition to False, with reason Slow, if the chart's job has been
+// running for longer than expected. The chart is requeued for when a running job would become slow, as the job
+// itself may not change in the meantime.
+func (c *Controller) setProgressingCondition(chart *helmv1.HelmChart, job *batch.Job) {
+	running, expected := jobRunTime(job, time.Now()), expectedDuration(chart)
+	if running == 0 || expected <= 0 {
+		observeSlowJob(chart, false)
+		if ConditionProgressing.GetStatus(chart) != "" {
+			ConditionProgressing.False(chart)
+			ConditionProgressing.Reason(chart, "")
+			ConditionProgressing.Message(chart, "")
+		}
+		return
 	}
-	switch strings.ToLower(val.StrVal) {
-	case "true", "false", "null":
-		return true
-	default:
-		return false
+
+	if running < expected {
+		observeSlowJob(chart, false)
+		ConditionProgressing.True(chart)
+		ConditionProgressing.Reason(chart, "JobRunning")
+		ConditionProgressing.Message(chart, "")
+		c.helmController.EnqueueAfter(chart.Namespace, chart.Name, expected-running)
+		return
 	}
+
+	if ConditionProgressing.GetReason(chart) != "Slow" {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "JobSlow", "Job %s/%s has been running for %s, longer than the expected %s", chart.Namespace, job.Name, running.Round(time.Second), expected)
+	}
+	observeSlowJob(chart, true)
+	ConditionProgressing.False(chart)
+	ConditionProgressing.Reason(chart, "Slow")
+	ConditionProgressing.Message(chart, fmt.Sprintf("job %s/%s has been running for longer than the expected %s", chart.Namespace, job.Name, expected))
 }
 
-// escapeComma should be passed a string consisting of zero or more backslashes, followed by a comma.
-// If there are an even number of characters (such as `\,` or `\\\,`) then the comma is escaped.
-// If there are an uneven number of characters (such as `,` or `\\,` then the comma is not escaped,
-// and we need to escape it by adding an additional backslash.
-// This logic is difficult if not impossible to accomplish with a simple regex submatch replace.
-func escapeComma(match string) string {
-	if len(match)%2 == 1 {
-		match = `\` + match
+// expectedDuration returns how long the chart's job is expected to run for: the chart's ExpectedDuration if set,
+// otherwise SlowJobFactor times the chart's timeout.
+func expectedDuration(chart *helmv1.HelmChart) time.Duration {
+	if chart.Spec.ExpectedDuration != nil {
+		return chart.Spec.ExpectedDuration.Duration
 	}
-	return match
+	timeout := DefaultTimeout
+	if chart.Spec.Timeout != nil {
+		timeout = chart.Spec.Timeout.Duration
+	}
+	return time.Duration(SlowJobFactor * float64(timeout))
 }
 
-func setProxyEnv(job *batch.Job) {
-	proxySysEnv := []string{
-		"all_proxy",
-		"ALL_PROXY",
-		"http_proxy",
-		"HTTP_PROXY",
-		"https_proxy",
-		"HTTPS_PROXY",
-		"no_proxy",
-		"NO_PROXY",
+// jobRunTime returns how long the job has been running for, or zero if it is not running.
+func jobRunTime(job *batch.Job, now time.Time) time.Duration {
+	if job == nil || job.Status.StartTime == nil || job.Status.CompletionTime != nil || job.Status.Succeeded > 0 || jobFailure(job) != nil {
+		return 0
 	}
-	for _, proxyEnv := range proxySysEnv {
-		proxyEnvValue := os.Getenv(proxyEnv)
-		if len(proxyEnvValue) == 0 {
-			continue
-		}
-		envar := core.EnvVar{
-			Name:  proxyEnv,
-			Value: proxyEnvValue,
+	if running := now.Sub(job.Status.StartTime.Time); running > 0 {
+		return running
+	}
+	return 0
+}
+
+// setSourceConflictCondition sets the SourceConflict condition if the chart sets more than one source to install
+// the chart from, listing the fields that are ignored because a source with higher precedence is set.
+func (c *Controller) setSourceConflictCondition(chart *helmv1.HelmChart) {
+	ignored := render.IgnoredSourceFields(chart)
+	if len(ignored) == 0 {
+		if ConditionSourceConflict.GetStatus(chart) != "" {
+			ConditionSourceConflict.False(chart)
+			ConditionSourceConflict.Reason(chart, "")
+			ConditionSourceConflict.Message(chart, "")
 		}
-		job.Spec.Template.Spec.Containers[0].Env = append(
-			job.Spec.Template.Spec.Containers[0].Env,
-			envar)
+		return
 	}
+
+	message := fmt.Sprintf("chart is installed from spec.%s; %s ignored", render.ChartSource(chart), strings.Join(ignored, ", "))
+	if ConditionSourceConflict.GetMessage(chart) != message {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "SourceConflict", "HelmChart is installed from spec.%s; %s ignored", render.ChartSource(chart), strings.Join(ignored, ", "))
+	}
+	ConditionSourceConflict.True(chart)
+	ConditionSourceConflict.Reason(chart, "FieldsIgnored")
+	ConditionSourceConflict.Message(chart, message)
 }
 
-func contentConfigMap(chart *helmv1.HelmChart) *core.ConfigMap {
-	configMap := &core.ConfigMap{
-		TypeMeta: meta.TypeMeta{
-			APIVersion: "v1",
-			Kind:       "ConfigMap",
-		},
-		ObjectMeta: meta.ObjectMeta{
-			Name:      fmt.Sprintf("chart-content-%s", chart.Name),
-			Namespace: chart.Namespace,
-		},
-		Data: map[string]string{},
+// jobFailure returns the Failed condition of the job, if the job has failed.
+func jobFailure(job *batch.Job) *batch.JobCondition {
+	if job == nil {
+		return nil
+	}
+	for i, cond := range job.Status.Conditions {
+		if cond.Type == batch.JobFailed && cond.Status == core.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
 	}
+	return nil
+}
 
-	if chart.Spec.ChartContent != "" {
-		key := fmt.Sprintf("%s.tgz.base64", chart.Name)
-		configMap.Data[key] = chart.Spec.ChartContent
+// jobUpToDate returns true if the chart's current job was created from the rendered configuration.
+func (c *Controller) jobUpToDate(chart *helmv1.HelmChart, objs *objectset.ObjectSet) bool {
+	desired := renderedJob(objs)
+	if desired == nil {
+		return true
 	}
+	existing, err := c.jobsCache.Get(chart.Namespace, desired.Name)
+	return err == nil && jobMatches(existing, desired)
+}
 
-	return configMap
+// frozen returns true if the chart has been annotated to prevent new jobs from being created for it.
+// Charts that are being deleted are never frozen, so that the finalizer is not blocked.
+func frozen(chart *helmv1.HelmChart) bool {
+	return chart.Annotations[FreezeAnnotation] == "true" && chart.DeletionTimestamp == nil
 }
 
-func setValuesConfigMap(job *batch.Job, chart *helmv1.HelmChart) *core.ConfigMap {
-	configMap := valuesConfigMap(chart)
+// setUpgradesFrozen marks the chart's upgrades as frozen, noting whether it has changes that are being held back,
+// and records an event when changes start being held back.
+func (c *Controller) setUpgradesFrozen(chart *helmv1.HelmChart, objs *objectset.ObjectSet) {
+	ConditionUpgradesFrozen.True(chart)
+	if c.jobUpToDate(chart, objs) {
+		ConditionUpgradesFrozen.Reason(chart, "")
+		ConditionUpgradesFrozen.Message(chart, "")
+		return
+	}
+	if ConditionUpgradesFrozen.GetReason(chart) != "ChangesSuppressed" {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "UpgradeSuppressed", "Not applying changes to HelmChart using Job %s/%s: upgrades are frozen by the %s annotation", chart.Namespace, render.JobName(chart), FreezeAnnotation)
+	}
+	ConditionUpgradesFrozen.Reason(chart, "ChangesSuppressed")
+	ConditionUpgradesFrozen.Message(chart, "chart has changes that will be applied once upgrades are unfrozen")
+}
 
-	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
-		Name: "values",
-		VolumeSource: core.VolumeSource{
-			ConfigMap: &core.ConfigMapVolumeSource{
-				LocalObjectReference: core.LocalObjectReference{
-					Name: configMap.Name,
-				},
-			},
-		},
-	})
+// renderedJob returns the Job from a rendered object set.
+func renderedJob(objs *objectset.ObjectSet) *batch.Job {
+	for _, obj := range objs.All() {
+		if job, ok := obj.(*batch.Job); ok {
+			return job
+		}
+	}
+	return nil
+}
 
-	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
-		MountPath: "/config",
-		Name:      "values",
-	})
+// jobMatches returns true if the existing job was created from the same configuration as the desired job.
+func jobMatches(existing, desired *batch.Job) bool {
+	if existing == nil || desired == nil {
+		return false
+	}
+	if uid := existing.Annotations[TargetNamespaceUIDAnnotation]; uid != "" && desired.Annotations[TargetNamespaceUIDAnnotation] != "" &&
+		uid != desired.Annotations[TargetNamespaceUIDAnnotation] {
+		return false
+	}
+	return existing.Annotations[render.JobHashAnnotation] == desired.Annotations[render.JobHashAnnotation]
+}
 
-	return configMap
+// inPlacePatch returns true if the patch only changes the object's metadata, or whether the job is suspended. A
+// patch that changes the target namespace UID recorded on the job from namespaceUID is not applied in place, as the
+// job must be re-run to re-install the chart into the re-created namespace. Nor is a patch that changes the job
+// hash, which also covers the pod failure policy that is left out of patches.
+func inPlacePatch(data []byte, namespaceUID string) bool {
+	patch := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &patch); err != nil || len(patch) == 0 {
+		return false
+	}
+	for field, value := range patch {
+		switch field {
+		case "metadata":
+			metadata := struct {
+				Annotations map[string]*string `json:"annotations"`
+			}{}
+			if err := json.Unmarshal(value, &metadata); err != nil {
+				return false
+			}
+			if uid, ok := metadata.Annotations[TargetNamespaceUIDAnnotation]; ok && uid != nil && namespaceUID != "" && *uid != namespaceUID {
+				return false
+			}
+			if _, ok := metadata.Annotations[render.JobHashAnnotation]; ok {
+				return false
+			}
+		case "spec":
+			spec := map[string]json.RawMessage{}
+			if err := json.Unmarshal(value, &spec); err != nil {
+				return false
+			}
+			if _, ok := spec["suspend"]; !ok || len(spec) != 1 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
 }
 
-func setContentConfigMap(job *batch.Job, chart *helmv1.HelmChart) *core.ConfigMap {
-	configMap := contentConfigMap(chart)
-	if configMap == nil {
+// retainConfigMapRevisions keeps the ConfigMap revisions mounted by the chart's current job in the desired set
+// until that job has succeeded, so that apply does not delete them while the job may still be using them.
+// Once the job has succeeded, or has been replaced by a job mounting newer revisions, they are removed by apply.
+func (c *Controller) retainConfigMapRevisions(chart *helmv1.HelmChart, objs *objectset.ObjectSet) error {
+	job, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart))
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if job.Status.Succeeded > 0 {
 		return nil
 	}
 
-	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
-		Name: "content",
-		VolumeSource: core.VolumeSource{
-			ConfigMap: &core.ConfigMapVolumeSource{
-				LocalObjectReference: core.LocalObjectReference{
-					Name: configMap.Name,
-				},
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.ConfigMap == nil {
+			continue
+		}
+		key := objectset.ObjectKey{Namespace: job.Namespace, Name: volume.ConfigMap.Name}
+		if objs.Contains(core.SchemeGroupVersion.WithKind("ConfigMap").GroupKind(), key) {
+			continue
+		}
+		configMap, err := c.configMapCache.Get(key.Namespace, key.Name)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		objs.Add(&core.ConfigMap{
+			TypeMeta: meta.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
 			},
-		},
-	})
-
-	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
-		MountPath: "/chart",
-		Name:      "content",
-	})
-
-	return configMap
-}
+			ObjectMeta: meta.ObjectMeta{
+				Name:            configMap.Name,
+				Namespace:       configMap.Namespace,
+				Labels:          withoutApplyMetadata(configMap.Labels),
+				Annotations:     withoutApplyMetadata(configMap.Annotations),
+				OwnerReferences: configMap.OwnerReferences,
+			},
+			Data:       configMap.Data,
+			BinaryData: configMap.BinaryData,
+		})
+	}
 
-func setFailurePolicy(job *batch.Job, failurePolicy string) {
-	job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
-		Name:  "FAILURE_POLICY",
-		Value: failurePolicy,
-	})
+	return nil
 }
 
-func hashConfigMaps(job *batch.Job, maps ...*core.ConfigMap) {
-	hash := sha256.New()
-
-	for _, configMap := range maps {
-		for k, v := range configMap.Data {
-			hash.Write([]byte(k))
-			hash.Write([]byte(v))
-		}
-		for k, v := range configMap.BinaryData {
-			hash.Write([]byte(k))
-			hash.Write(v)
+// withoutApplyMetadata returns a copy of the map without the labels or annotations managed by apply.
+func withoutApplyMetadata(m map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range m {
+		if !strings.HasPrefix(k, apply.LabelPrefix) {
+			result[k] = v
 		}
 	}
+	return result
+}
 
-	job.Spec.Template.ObjectMeta.Annotations[Annotation] = fmt.Sprintf("SHA256=%X", hash.Sum(nil))
+func (c *Controller) renderOptions() render.Options {
+	return render.Options{
+		JobImage:                     DefaultJobImage,
+		FailurePolicy:                DefaultFailurePolicy,
+		CommonLabels:                 CommonLabels,
+		SecretGetter:                 c.secretCache.Get,
+		ConfigMapGetter:              c.configMapCache.Get,
+		DisableSidecars:              DisableSidecars,
+		SafeToEvict:                  JobSafeToEvict,
+		DisableSecurityContext:       DisableJobSecurityContext,
+		TolerateUnschedulable:        TolerateUnschedulable,
+		RepoMirrors:                  RepoMirrors,
+		RepoMirrorAuthSecret:         RepoMirrorAuthSecret,
+		SpreadJobs:                   SpreadJobs,
+		CABundle:                     CABundle,
+		NodeSelector:                 JobNodeSelector,
+		Tolerations:                  JobTolerations,
+		ReleaseSecretLabels:          ReleaseSecretLabels,
+		ReleaseLabels:                ReleaseLabels,
+		ReleaseAnnotations:           ReleaseAnnotations,
+		ValuesTransformers:           ValuesTransformers,
+		DeleteJobTTL:                 DeleteJobTTL,
+		ProjectedServiceAccountToken: ProjectedServiceAccountToken,
+		TargetContextGetter:          c.targetContext,
+		ProvenanceGetter:             c.releaseProvenance,
+	}
 }
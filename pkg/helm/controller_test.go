@@ -1,90 +1,464 @@
 package helm
 
 import (
-	"strings"
+	"fmt"
 	"testing"
 	"time"
 
 	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	helmcontroller "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/apply"
+	corecontroller "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/rancher/wrangler/pkg/objectset"
 	"github.com/stretchr/testify/assert"
-	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
-func TestSetVals(t *testing.T) {
+func TestFrozen(t *testing.T) {
 	assert := assert.New(t)
-	tests := map[string]bool{
-		"":      false,
-		" ":     false,
-		"foo":   false,
-		"1.0":   false,
-		"0.1":   false,
-		"0":     true,
-		"1":     true,
-		"-1":    true,
-		"true":  true,
-		"TrUe":  true,
-		"false": true,
-		"FaLsE": true,
-		"null":  true,
-		"NuLl":  true,
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	assert.False(frozen(chart))
+
+	chart.Annotations = map[string]string{FreezeAnnotation: "true"}
+	assert.True(frozen(chart))
+
+	chart.DeletionTimestamp = &meta.Time{}
+	assert.False(frozen(chart))
+}
+
+func TestSetUpgradesFrozen(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "traefik"}})
+	chart.Annotations = map[string]string{FreezeAnnotation: "true"}
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{jobsCache: jobs, recorder: recorder}
+
+	c.setUpgradesFrozen(chart, objs)
+	c.setUpgradesFrozen(chart, objs)
+	assert.True(ConditionUpgradesFrozen.IsTrue(chart))
+	assert.Equal("ChangesSuppressed", ConditionUpgradesFrozen.GetReason(chart))
+	assert.Len(recorder.Events, 1)
+	<-recorder.Events
+
+	jobs.jobs["kube-system/"+render.JobName(chart)] = renderedJob(objs)
+	c.setUpgradesFrozen(chart, objs)
+	assert.True(ConditionUpgradesFrozen.IsTrue(chart))
+	assert.Empty(ConditionUpgradesFrozen.GetReason(chart))
+	assert.Len(recorder.Events, 0)
+
+	delete(jobs.jobs, "kube-system/"+render.JobName(chart))
+	c.setUpgradesFrozen(chart, objs)
+	assert.Len(recorder.Events, 1)
+}
+
+func TestNamespaceTerminating(t *testing.T) {
+	assert := assert.New(t)
+	namespace := &core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "edge"}}
+	assert.False(namespaceTerminating(namespace))
+
+	namespace.Status.Phase = core.NamespaceTerminating
+	assert.True(namespaceTerminating(namespace))
+
+	namespace.Status.Phase = core.NamespaceActive
+	namespace.DeletionTimestamp = &meta.Time{}
+	assert.True(namespaceTerminating(namespace))
+
+	assert.True(namespaceTerminating(nil))
+}
+
+func TestHasChart(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	assert.False(hasChart(chart))
+
+	chart.Spec.ChartContent = "H4sIAAAAAAAA/ypJLS4BBAAA//8AAAAAAAAAAA=="
+	assert.True(hasChart(chart))
+
+	chart.Spec.ChartContent = ""
+	chart.Spec.ChartContentSecret = &core.LocalObjectReference{Name: "traefik-chart"}
+	assert.True(hasChart(chart))
+}
+
+func TestInPlacePatch(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(inPlacePatch([]byte(`{"metadata":{"ownerReferences":[{"kind":"HelmChart","name":"traefik"}]}}`), ""))
+	assert.False(inPlacePatch([]byte(`{"metadata":{"annotations":{}},"spec":{"backoffLimit":1000}}`), ""))
+	assert.False(inPlacePatch([]byte(`[{"op":"remove","path":"/spec"}]`), ""))
+	assert.False(inPlacePatch([]byte(`{}`), ""))
+
+	// suspended jobs are resumed without being replaced
+	assert.True(inPlacePatch([]byte(`{"spec":{"suspend":null}}`), ""))
+	assert.True(inPlacePatch([]byte(`{"metadata":{"annotations":{}},"spec":{"suspend":false}}`), ""))
+	assert.False(inPlacePatch([]byte(`{"spec":{"suspend":null,"backoffLimit":1000}}`), ""))
+
+	// a re-created target namespace requires the job to be re-run
+	assert.True(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+TargetNamespaceUIDAnnotation+`":"uid-1"}}}`), ""))
+	assert.True(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+TargetNamespaceUIDAnnotation+`":"uid-1"}}}`), "uid-1"))
+	assert.True(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+TargetNamespaceUIDAnnotation+`":null}}}`), "uid-1"))
+	assert.False(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+TargetNamespaceUIDAnnotation+`":"uid-2"}}}`), "uid-1"))
+
+	// a changed job hash requires the job to be re-run, even if only the pod failure policy changed
+	assert.False(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+render.JobHashAnnotation+`":"SHA256=00"}}}`), ""))
+}
+
+func TestNamespaceRecreateReplacesJob(t *testing.T) {
+	assert := assert.New(t)
+	defer func(reinstall bool) { ReinstallOnNamespaceRecreate = reinstall }(ReinstallOnNamespaceRecreate)
+	ReinstallOnNamespaceRecreate = true
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik", TargetNamespace: "traefik"}})
+	namespace := &core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "traefik", UID: "uid-1"}}
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	c := &Controller{jobsCache: jobs, namespaceCache: &namespaceCache{namespaces: []*core.Namespace{namespace}}}
+
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	found, err := c.setTargetNamespaceUID(chart, objs)
+	assert.NoError(err)
+	assert.True(found)
+	job := renderedJob(objs)
+	jobs.jobs["kube-system/"+job.Name] = job
+	assert.True(c.jobUpToDate(chart, objs))
+
+	namespace.UID = "uid-2"
+	objs, err = render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	_, err = c.setTargetNamespaceUID(chart, objs)
+	assert.NoError(err)
+	assert.False(c.jobUpToDate(chart, objs))
+	assert.False(inPlacePatch([]byte(`{"metadata":{"annotations":{"`+TargetNamespaceUIDAnnotation+`":"uid-2"}}}`), job.Annotations[TargetNamespaceUIDAnnotation]))
+}
+
+func TestSlowJob(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	assert.Equal(2*DefaultTimeout, expectedDuration(chart))
+
+	chart.Spec.Timeout = &meta.Duration{Duration: time.Minute}
+	assert.Equal(2*time.Minute, expectedDuration(chart))
+
+	chart.Spec.ExpectedDuration = &meta.Duration{Duration: 30 * time.Second}
+	assert.Equal(30*time.Second, expectedDuration(chart))
+
+	now := time.Unix(1000, 0)
+	start := meta.NewTime(now.Add(-time.Minute))
+	assert.Zero(jobRunTime(nil, now))
+	job := &batch.Job{Status: batch.JobStatus{StartTime: &start}}
+	assert.Equal(time.Minute, jobRunTime(job, now))
+
+	job.Status.Succeeded = 1
+	assert.Zero(jobRunTime(job, now))
+
+	job.Status.Succeeded = 0
+	job.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue}}
+	assert.Zero(jobRunTime(job, now))
+}
+
+func TestNamespacePaused(t *testing.T) {
+	assert := assert.New(t)
+	assert.False(namespacePaused(nil))
+
+	namespace := &core.Namespace{}
+	assert.False(namespacePaused(namespace))
+
+	namespace.Annotations = map[string]string{PauseAnnotation: "true"}
+	assert.True(namespacePaused(namespace))
+}
+
+type helmController struct {
+	helmcontroller.HelmChartController
+	enqueued []string
+	charts   []*v1.HelmChart
+	updated  []*v1.HelmChart
+}
+
+func (c *helmController) Cache() helmcontroller.HelmChartCache {
+	return &helmCache{charts: c.charts}
+}
+
+func (c *helmController) Enqueue(namespace, name string) {
+	c.enqueued = append(c.enqueued, namespace+"/"+name)
+}
+
+func (c *helmController) EnqueueAfter(namespace, name string, after time.Duration) {
+	c.enqueued = append(c.enqueued, fmt.Sprintf("%s/%s after %s", namespace, name, after))
+}
+
+func (c *helmController) Update(chart *v1.HelmChart) (*v1.HelmChart, error) {
+	c.updated = append(c.updated, chart)
+	return chart, nil
+}
+
+// applyInterface is embedded in the applier under another name, as the embedded field would otherwise clash with
+// its Apply method
+type applyInterface = apply.Apply
+
+type applier struct {
+	applyInterface
+	owners  []runtime.Object
+	applied []*objectset.ObjectSet
+}
+
+func (a *applier) WithOwner(obj runtime.Object) apply.Apply {
+	a.owners = append(a.owners, obj)
+	return a
+}
+
+func (a *applier) Apply(set *objectset.ObjectSet) error {
+	a.applied = append(a.applied, set)
+	return nil
+}
+
+type configMapCache struct {
+	corecontroller.ConfigMapCache
+}
+
+func (c *configMapCache) Get(namespace, name string) (*core.ConfigMap, error) {
+	return nil, errors.NewNotFound(core.Resource("configmaps"), name)
+}
+
+type helmCache struct {
+	helmcontroller.HelmChartCache
+	charts []*v1.HelmChart
+}
+
+func (c *helmCache) List(namespace string, selector labels.Selector) ([]*v1.HelmChart, error) {
+	return c.charts, nil
+}
+
+type namespaceCache struct {
+	corecontroller.NamespaceCache
+	namespaces []*core.Namespace
+}
+
+func (c *namespaceCache) Get(name string) (*core.Namespace, error) {
+	for _, namespace := range c.namespaces {
+		if namespace.Name == name {
+			return namespace, nil
+		}
 	}
-	for testString, isTyped := range tests {
-		ret := typedVal(intstr.Parse(testString))
-		assert.Equal(isTyped, ret, "expected typedVal(%s) = %t", testString, isTyped)
+	return nil, errors.NewNotFound(core.Resource("namespaces"), name)
+}
+
+func TestOnJobDelete(t *testing.T) {
+	assert := assert.New(t)
+	helms := &helmController{}
+	c := &Controller{helmController: helms}
+
+	job := &batch.Job{ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "helm-install-traefik", Labels: map[string]string{Label: "traefik"}}}
+	c.onJobDelete(job)
+	c.onJobDelete(cache.DeletedFinalStateUnknown{Key: "kube-system/helm-install-traefik", Obj: job})
+	c.onJobDelete(&batch.Job{ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "other"}})
+	assert.Equal([]string{"kube-system/traefik", "kube-system/traefik"}, helms.enqueued)
+}
+
+func TestSuspendedJobCondition(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik", JobSuspend: true}})
+	defer forgetChart(chart)
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	job := renderedJob(objs)
+	chart.Status.JobName = job.Name
+
+	c := &Controller{jobsCache: &jobCache{jobs: map[string]*batch.Job{"kube-system/" + job.Name: job}}}
+	c.setReadyCondition(chart, objs, true)
+	assert.True(ConditionReady.IsFalse(chart))
+	assert.Equal("JobSuspended", ConditionReady.GetReason(chart))
+
+	job.Spec.Suspend = nil
+	c.setReadyCondition(chart, objs, true)
+	assert.Equal("JobPending", ConditionReady.GetReason(chart))
+}
+
+// testController returns a controller, backed by fakes, for the traefik chart in kube-system, whose release has a
+// Deployment in traefik.
+func testController() (*Controller, *helmController, *jobCache, *applier, *dynamicClient) {
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "apps", Version: "v1"}})
+	restMapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, apimeta.RESTScopeNamespace)
+	helms := &helmController{}
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	applies := &applier{}
+	client := &dynamicClient{existing: map[string]types.UID{"deployments/traefik/traefik": "deployment-uid"}}
+	c := &Controller{
+		helmController:   helms,
+		confController:   &confController{},
+		namespaceCache:   &namespaceCache{namespaces: []*core.Namespace{{ObjectMeta: meta.ObjectMeta{Name: "kube-system"}}}},
+		jobsCache:        jobs,
+		jobsSynced:       func() bool { return true },
+		secretController: &secretController{},
+		secretCache:      &secretCache{},
+		configMapCache:   &configMapCache{},
+		apply:            applies,
+		dynamic:          client,
+		restMapper:       restMapper,
+		recorder:         record.NewFakeRecorder(10),
 	}
+	return c, helms, jobs, applies, client
+}
+
+func removedChart() *v1.HelmChart {
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik", TargetNamespace: "traefik"}})
+	chart.DeletionTimestamp = &meta.Time{}
+	return chart
 }
 
-func TestInstallJob(t *testing.T) {
+func TestOnHelmRemove(t *testing.T) {
 	assert := assert.New(t)
-	chart := NewChart()
-	job, _, _ := job(chart)
-	assert.Equal("helm-install-traefik", job.Name)
-	assert.Equal(DefaultJobImage, job.Spec.Template.Spec.Containers[0].Image)
-	assert.Equal("helm-traefik", job.Spec.Template.Spec.ServiceAccountName)
+	c, helms, jobs, applies, _ := testController()
+	chart := removedChart()
+
+	// the delete job is created, and the finalizer is kept until it has succeeded
+	_, err := c.OnHelmRemove("kube-system/traefik", chart)
+	assert.EqualError(err, "waiting for delete of helm chart for kube-system/traefik by helm-delete-traefik")
+	if assert.Len(applies.applied, 1) {
+		job := renderedJob(applies.applied[0])
+		assert.Equal("helm-delete-traefik", job.Name)
+		jobs.jobs["kube-system/"+job.Name] = job.DeepCopy()
+	}
+	assert.Equal([]string{"kube-system/traefik"}, c.confController.(*confController).enqueued)
+
+	_, err = c.OnHelmRemove("kube-system/traefik", chart)
+	assert.EqualError(err, "waiting for delete of helm chart for kube-system/traefik by helm-delete-traefik")
+	assert.Len(applies.applied, 1)
+
+	// the finalizer is released once the job has succeeded, and the chart's objects are removed
+	jobs.jobs["kube-system/helm-delete-traefik"].Status.Succeeded = 1
+	updated, err := c.OnHelmRemove("kube-system/traefik", chart)
+	assert.NoError(err)
+	assert.Equal("helm-delete-traefik", updated.Status.JobName)
+	assert.Len(helms.updated, 1)
+	if assert.Len(applies.applied, 2) {
+		assert.Empty(applies.applied[1].All())
+	}
 }
 
-func TestDeleteJob(t *testing.T) {
+func TestOnHelmRemoveVerifyUninstall(t *testing.T) {
 	assert := assert.New(t)
-	chart := NewChart()
-	deleteTime := v12.NewTime(time.Time{})
-	chart.DeletionTimestamp = &deleteTime
-	job, _, _ := job(chart)
-	assert.Equal("helm-delete-traefik", job.Name)
+	c, helms, jobs, applies, client := testController()
+	chart := removedChart()
+	chart.Spec.UninstallWait = true
+	chart.Annotations = map[string]string{UninstallResourcesAnnotation: `[{"Group":"apps","Kind":"Deployment","Namespace":"","Name":"traefik","UID":"deployment-uid"}]`}
+	defer forgetUninstall(chart)
+
+	objs, err := render.Objects(chart, nil, c.renderOptions())
+	assert.NoError(err)
+	job := renderedJob(objs)
+	job.Status.Succeeded = 1
+	jobs.jobs["kube-system/"+job.Name] = job
+
+	// the finalizer is kept while the release's Deployment still exists after the delete job has succeeded
+	_, err = c.OnHelmRemove("kube-system/traefik", chart)
+	assert.EqualError(err, "waiting for removal of 1 resources of HelmChart kube-system/traefik")
+	assert.Empty(helms.updated)
+	assert.Empty(applies.applied)
+
+	delete(client.existing, "deployments/traefik/traefik")
+	_, err = c.OnHelmRemove("kube-system/traefik", chart)
+	assert.NoError(err)
+	assert.Len(helms.updated, 1)
+	if assert.Len(applies.applied, 1) {
+		assert.Empty(applies.applied[0].All())
+	}
 }
 
-func TestInstallArgs(t *testing.T) {
+func TestOnHelmRemoveTerminatingNamespace(t *testing.T) {
 	assert := assert.New(t)
-	stringArgs := strings.Join(args(NewChart()), " ")
-	assert.Equal("install "+
-		"--set-string acme.dnsProvider.name=cloudflare "+
-		"--set-string global.clusterCIDR=10.42.0.0/16\\,fd42::/48 "+
-		"--set-string global.systemDefaultRegistry= "+
-		"--set rbac.enabled=true "+
-		"--set ssl.enabled=false",
-		stringArgs)
+	defer func(policy string) { TerminatingNamespacePolicy = policy }(TerminatingNamespacePolicy)
+	c, helms, _, applies, _ := testController()
+	c.namespaceCache.(*namespaceCache).namespaces[0].DeletionTimestamp = &meta.Time{}
+	recorder := c.recorder.(*record.FakeRecorder)
+	chart := removedChart()
+
+	// the delete job is still run unless the policy allows it to be skipped
+	TerminatingNamespacePolicy = TerminatingNamespacePolicyWait
+	_, err := c.OnHelmRemove("kube-system/traefik", chart)
+	assert.EqualError(err, "waiting for delete of helm chart for kube-system/traefik by helm-delete-traefik")
+	if assert.Len(applies.applied, 1) {
+		assert.NotNil(renderedJob(applies.applied[0]))
+	}
+
+	// the finalizer is released without running the delete job
+	TerminatingNamespacePolicy = TerminatingNamespacePolicySkip
+	_, err = c.OnHelmRemove("kube-system/traefik", chart)
+	assert.NoError(err)
+	if assert.Len(applies.applied, 2) {
+		assert.Empty(applies.applied[1].All())
+	}
+	assert.Empty(helms.updated)
+	assert.Contains(<-recorder.Events, "UninstallJobCreated")
+	assert.Equal("Warning DeleteJobSkipped Namespace kube-system is terminating; removing HelmChart without uninstalling release traefik/traefik", <-recorder.Events)
 }
 
-func TestDeleteArgs(t *testing.T) {
+func TestOnHelmRemoveTargetContextGone(t *testing.T) {
 	assert := assert.New(t)
-	chart := NewChart()
-	deleteTime := v12.NewTime(time.Time{})
-	chart.DeletionTimestamp = &deleteTime
-	stringArgs := strings.Join(args(chart), " ")
-	assert.Equal("delete", stringArgs)
-}
-
-func NewChart() *v1.HelmChart {
-	return v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
-		Spec: v1.HelmChartSpec{
-			Chart: "stable/traefik",
-			Set: map[string]intstr.IntOrString{
-				"rbac.enabled":                 intstr.Parse("true"),
-				"ssl.enabled":                  intstr.Parse("false"),
-				"acme.dnsProvider.name":        intstr.Parse("cloudflare"),
-				"global.clusterCIDR":           intstr.Parse("10.42.0.0/16,fd42::/48"),
-				"global.systemDefaultRegistry": intstr.Parse(""),
-			},
-		},
-	})
+	defer func() { TargetContextSelector = nil }()
+	vcluster := map[string]string{"app": "vcluster"}
+	TargetContextSelector = labels.SelectorFromSet(vcluster)
+	c, helms, _, applies, _ := testController()
+	recorder := c.recorder.(*record.FakeRecorder)
+	chart := removedChart()
+	chart.Spec.TargetContext = "tenant-a"
+
+	// the finalizer is released without running the delete job, as the release cannot be reached
+	_, err := c.OnHelmRemove("kube-system/traefik", chart)
+	assert.NoError(err)
+	if assert.Len(applies.applied, 1) {
+		assert.Empty(applies.applied[0].All())
+	}
+	assert.Empty(helms.updated)
+	assert.Equal("Warning DeleteJobSkipped Target context tenant-a no longer exists; removing HelmChart without uninstalling release traefik/traefik", <-recorder.Events)
+
+	// the delete job is run while the target context exists
+	c.secretCache.(*secretCache).secrets = []*core.Secret{{ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "vc-tenant-a", Labels: vcluster}}}
+	_, err = c.OnHelmRemove("kube-system/traefik", chart)
+	assert.EqualError(err, "waiting for delete of helm chart for kube-system/traefik by helm-delete-traefik")
+	if assert.Len(applies.applied, 2) {
+		assert.NotNil(renderedJob(applies.applied[1]))
+	}
+}
+
+func TestJobsCacheNotSynced(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, _, applies, _ := testController()
+	synced := false
+	c.jobsSynced = func() bool { return synced }
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik", TargetNamespace: "traefik"}})
+	defer forgetChart(chart)
+
+	// nothing is applied from a cold cache, which may be missing the chart's job; the chart is requeued instead
+	updated, err := c.OnHelmChange("kube-system/traefik", chart)
+	assert.NoError(err)
+	assert.Same(chart, updated)
+	assert.Empty(applies.applied)
+	assert.Empty(helms.updated)
+	assert.Equal([]string{"kube-system/traefik after 1s"}, helms.enqueued)
+
+	_, err = c.OnHelmRemove("kube-system/traefik", removedChart())
+	assert.Equal(generic.ErrSkip, err)
+	assert.Empty(applies.applied)
+	assert.Equal([]string{"kube-system/traefik after 1s", "kube-system/traefik after 1s"}, helms.enqueued)
+
+	// the chart is applied once the cache has synced
+	synced = true
+	_, err = c.OnHelmChange("kube-system/traefik", chart)
+	assert.NoError(err)
+	if assert.Len(applies.applied, 1) {
+		assert.Equal("helm-install-traefik", renderedJob(applies.applied[0]).Name)
+	}
 }
@@ -0,0 +1,79 @@
+package helm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/k3s-io/helm-controller/pkg/metrics"
+	"github.com/rancher/wrangler/pkg/objectset"
+	core "k8s.io/api/core/v1"
+)
+
+var (
+	driftLock sync.Mutex
+	// driftSince holds the time that the desired configuration of each chart was first seen to differ from its last
+	// successfully installed configuration, keyed by namespace and name
+	driftSince = map[string]time.Time{}
+)
+
+// setDriftCondition sets the Drifted condition, and the config drift metric, once the hash of the chart's desired
+// job, which covers the chart and its HelmChartConfig, has differed from the hash of its last successful job for
+// longer than the DriftGracePeriod. This flags rollouts that are stuck, such as jobs that keep failing or are
+// queued, across many charts, without flagging every change while it is being rolled out. The chart is requeued
+// for when the grace period expires, as its job may not change in the meantime.
+func (c *Controller) setDriftCondition(chart *helmv1.HelmChart, objs *objectset.ObjectSet, now time.Time) {
+	if DriftGracePeriod <= 0 || chart.DeletionTimestamp != nil {
+		return
+	}
+
+	desired := renderedJob(objs)
+	job, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart))
+	if desired == nil || (err == nil && job.Status.Succeeded > 0 && jobMatches(job, desired)) {
+		forgetDrift(chart)
+		metrics.ConfigDrift.Set(chartLabels(chart), 0)
+		if ConditionDrifted.GetStatus(chart) != "" {
+			ConditionDrifted.False(chart)
+			ConditionDrifted.Reason(chart, "")
+			ConditionDrifted.Message(chart, "")
+		}
+		return
+	}
+
+	drifted := now.Sub(startDrift(chart, now))
+	if drifted < DriftGracePeriod {
+		metrics.ConfigDrift.Set(chartLabels(chart), 0)
+		c.helmController.EnqueueAfter(chart.Namespace, chart.Name, DriftGracePeriod-drifted)
+		return
+	}
+
+	if !ConditionDrifted.IsTrue(chart) {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "ConfigDrift", "Desired configuration of HelmChart has not been installed for %s", drifted.Round(time.Second))
+	}
+	metrics.ConfigDrift.Set(chartLabels(chart), 1)
+	ConditionDrifted.True(chart)
+	ConditionDrifted.Reason(chart, "RolloutStuck")
+	ConditionDrifted.Message(chart, fmt.Sprintf("desired configuration has not been installed successfully for longer than %s", DriftGracePeriod))
+}
+
+// startDrift returns the time that the chart was first seen to have drifted, recording now if it had not.
+func startDrift(chart *helmv1.HelmChart, now time.Time) time.Time {
+	driftLock.Lock()
+	defer driftLock.Unlock()
+	key := chart.Namespace + "/" + chart.Name
+	since, ok := driftSince[key]
+	if !ok {
+		since = now
+		driftSince[key] = since
+	}
+	return since
+}
+
+// forgetDrift removes the drift start time of a chart that has been installed or deleted.
+func forgetDrift(chart *helmv1.HelmChart) {
+	driftLock.Lock()
+	defer driftLock.Unlock()
+	delete(driftSince, chart.Namespace+"/"+chart.Name)
+}
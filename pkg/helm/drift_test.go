@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDriftCondition(t *testing.T) {
+	assert := assert.New(t)
+	defer func(period time.Duration) { DriftGracePeriod = period }(DriftGracePeriod)
+	DriftGracePeriod = 10 * time.Minute
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	defer forgetChart(chart)
+	defer forgetDrift(chart)
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	succeeded := renderedJob(objs).DeepCopy()
+	succeeded.Status.Succeeded = 1
+
+	jobs := &jobCache{jobs: map[string]*batch.Job{"kube-system/" + succeeded.Name: succeeded}}
+	helms := &helmController{}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{jobsCache: jobs, helmController: helms, recorder: recorder}
+
+	now := time.Unix(1000, 0)
+	c.setDriftCondition(chart, objs, now)
+	assert.Empty(ConditionDrifted.GetStatus(chart))
+
+	// the chart is changed, and its new job keeps failing
+	chart.Spec.Version = "2.0.0"
+	changed, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	c.setDriftCondition(chart, changed, now)
+	assert.Empty(ConditionDrifted.GetStatus(chart))
+	assert.Equal([]string{"kube-system/traefik after 10m0s"}, helms.enqueued)
+
+	c.setDriftCondition(chart, changed, now.Add(11*time.Minute))
+	assert.True(ConditionDrifted.IsTrue(chart))
+	assert.Equal("RolloutStuck", ConditionDrifted.GetReason(chart))
+	assert.Len(recorder.Events, 1)
+
+	// the new job succeeds
+	installed := renderedJob(changed).DeepCopy()
+	installed.Status.Succeeded = 1
+	jobs.jobs["kube-system/"+installed.Name] = installed
+	c.setDriftCondition(chart, changed, now.Add(12*time.Minute))
+	assert.True(ConditionDrifted.IsFalse(chart))
+	assert.NotContains(driftSince, "kube-system/traefik")
+}
@@ -0,0 +1,44 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/objectset"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+)
+
+// dryRun records the objects that would be applied for the chart, instead of applying them. It is used when
+// DryRun is set, so that the controller can be checked against a restored cluster before it is allowed to act.
+func (c *Controller) dryRun(chart *helmv1.HelmChart, objs *objectset.ObjectSet) {
+	action := "install"
+	if chart.DeletionTimestamp != nil {
+		action = "uninstall"
+	}
+	objects := describeObjects(objs)
+	logrus.Infof("Dry run: would %s HelmChart %s/%s by applying %s", action, chart.Namespace, chart.Name, objects)
+	c.recorder.Eventf(chart, core.EventTypeNormal, "DryRun", "Would %s HelmChart by applying %s", action, objects)
+}
+
+// describeObjects returns a sorted list of the kind, namespace and name of each object in the set.
+func describeObjects(objs *objectset.ObjectSet) string {
+	var objects []string
+	for _, obj := range objs.All() {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		metadata, err := apimeta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if metadata.GetNamespace() == "" {
+			objects = append(objects, fmt.Sprintf("%s %s", kind, metadata.GetName()))
+		} else {
+			objects = append(objects, fmt.Sprintf("%s %s/%s", kind, metadata.GetNamespace(), metadata.GetName()))
+		}
+	}
+	sort.Strings(objects)
+	return strings.Join(objects, ", ")
+}
@@ -0,0 +1,37 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDescribeObjects(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	description := describeObjects(objs)
+	assert.True(strings.HasPrefix(description, "ClusterRoleBinding helm-kube-system-traefik, ConfigMap kube-system/chart-content-traefik-"))
+	assert.Contains(description, ", Job kube-system/helm-install-traefik, ServiceAccount kube-system/helm-traefik")
+}
+
+func TestDryRunInvalidSpec(t *testing.T) {
+	assert := assert.New(t)
+	DryRun = true
+	defer func() { DryRun = false }()
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{recorder: recorder}
+
+	updated, err := c.invalidSpec(chart, fmt.Errorf("spec.restartPolicy must be OnFailure or Never, not \"Always\""))
+	assert.NoError(err)
+	assert.Equal(chart, updated)
+	assert.Empty(ConditionReady.GetReason(updated))
+	assert.Len(recorder.Events, 1)
+}
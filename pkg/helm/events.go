@@ -0,0 +1,58 @@
+package helm
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// EventPolicyAll records all events for the chart
+	EventPolicyAll = "all"
+	// EventPolicyFailuresOnly records only warning events for the chart
+	EventPolicyFailuresOnly = "failures-only"
+	// EventPolicyNone does not record any events for the chart
+	EventPolicyNone = "none"
+)
+
+// eventPolicyRecorder drops events for charts that are not allowed by the chart's EventPolicy. Events for other
+// objects, such as HelmChartConfigs, are always recorded.
+type eventPolicyRecorder struct {
+	record.EventRecorder
+}
+
+func (r eventPolicyRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if recordEvent(object, eventtype) {
+		r.EventRecorder.Event(object, eventtype, reason, message)
+	}
+}
+
+func (r eventPolicyRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if recordEvent(object, eventtype) {
+		r.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	}
+}
+
+func (r eventPolicyRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if recordEvent(object, eventtype) {
+		r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	}
+}
+
+// recordEvent returns true if an event of the given type may be recorded for the object. Unknown policies are
+// treated as EventPolicyAll, so that events are not lost to a typo.
+func recordEvent(object runtime.Object, eventtype string) bool {
+	chart, ok := object.(*helmv1.HelmChart)
+	if !ok {
+		return true
+	}
+	switch chart.Spec.EventPolicy {
+	case EventPolicyNone:
+		return false
+	case EventPolicyFailuresOnly:
+		return eventtype == core.EventTypeWarning
+	default:
+		return true
+	}
+}
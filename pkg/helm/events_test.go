@@ -0,0 +1,32 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	config := v1.NewHelmChartConfig("kube-system", "traefik", v1.HelmChartConfig{})
+	for policy, expected := range map[string]int{
+		"":                      2,
+		EventPolicyAll:          2,
+		EventPolicyFailuresOnly: 1,
+		EventPolicyNone:         0,
+		"verbose":               2,
+	} {
+		fake := record.NewFakeRecorder(10)
+		recorder := eventPolicyRecorder{fake}
+		chart.Spec.EventPolicy = policy
+		recorder.Eventf(chart, core.EventTypeNormal, "ApplyJob", "Applying HelmChart")
+		recorder.Eventf(chart, core.EventTypeWarning, "JobFailed", "Job failed")
+		recorder.Event(config, core.EventTypeNormal, "ConfigChanged", "Config changed")
+		assert.Len(fake.Events, expected+1, "policy %q", policy)
+	}
+}
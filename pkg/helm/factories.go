@@ -0,0 +1,83 @@
+package helm
+
+import (
+	"context"
+
+	helmfactory "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io"
+	"github.com/rancher/lasso/pkg/controller"
+	"github.com/rancher/wrangler/pkg/apply"
+	batchfactory "github.com/rancher/wrangler/pkg/generated/controllers/batch"
+	corefactory "github.com/rancher/wrangler/pkg/generated/controllers/core"
+	networkingfactory "github.com/rancher/wrangler/pkg/generated/controllers/networking.k8s.io"
+	rbacfactory "github.com/rancher/wrangler/pkg/generated/controllers/rbac"
+	"github.com/rancher/wrangler/pkg/start"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Factories holds the factories that the controllers passed to Register are built from.
+type Factories struct {
+	Helm       *helmfactory.Factory
+	Batch      *batchfactory.Factory
+	Rbac       *rbacfactory.Factory
+	Core       *corefactory.Factory
+	Networking *networkingfactory.Factory
+}
+
+// NewFactories returns the factories for the controller. If shared is not nil, every factory builds its
+// controllers from it, so that embedders that already run informers, such as k3s for jobs and ConfigMaps, share
+// their caches with the controller instead of caching the same objects twice; the namespace is then set by the
+// shared factory's cache factory. Otherwise each factory caches objects in the namespace, or in all namespaces if
+// it is empty.
+func NewFactories(cfg *rest.Config, namespace string, shared controller.SharedControllerFactory) (*Factories, error) {
+	opts := &helmfactory.FactoryOptions{Namespace: namespace}
+	if shared != nil {
+		opts = &helmfactory.FactoryOptions{SharedControllerFactory: shared}
+	}
+
+	var (
+		f   Factories
+		err error
+	)
+	if f.Helm, err = helmfactory.NewFactoryFromConfigWithOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	if f.Batch, err = batchfactory.NewFactoryFromConfigWithOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	if f.Rbac, err = rbacfactory.NewFactoryFromConfigWithOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	if f.Core, err = corefactory.NewFactoryFromConfigWithOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	if f.Networking, err = networkingfactory.NewFactoryFromConfigWithOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Start syncs the caches of the factories and starts their controllers. Embedders that passed a shared controller
+// factory to NewFactories may start it along with their own controllers instead.
+func (f *Factories) Start(ctx context.Context, threadiness int) error {
+	return start.All(ctx, threadiness, f.Helm, f.Batch, f.Rbac, f.Core, f.Networking)
+}
+
+// RegisterFactories registers the controller with the controllers built from the factories.
+func RegisterFactories(ctx context.Context, k8s kubernetes.Interface, dyn dynamic.Interface, apply apply.Apply, f *Factories) *Controller {
+	return Register(ctx,
+		k8s,
+		dyn,
+		apply,
+		f.Helm.Helm().V1().HelmChart(),
+		f.Helm.Helm().V1().HelmChartConfig(),
+		f.Helm.Helm().V1().HelmChartSummary(),
+		f.Batch.Batch().V1().Job(),
+		f.Rbac.Rbac().V1().ClusterRoleBinding(),
+		f.Core.Core().V1().ServiceAccount(),
+		f.Core.Core().V1().ConfigMap(),
+		f.Core.Core().V1().Secret(),
+		f.Networking.Networking().V1().NetworkPolicy(),
+		f.Core.Core().V1().Namespace())
+}
@@ -0,0 +1,33 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/rancher/lasso/pkg/cache"
+	"github.com/rancher/lasso/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewFactories(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &rest.Config{Host: "https://127.0.0.1:6443"}
+
+	factories, err := NewFactories(cfg, "kube-system", nil)
+	assert.NoError(err)
+	assert.NotEqual(factories.Core.ControllerFactory(), factories.Batch.ControllerFactory())
+
+	// embedders pass their own shared factory, so that every controller uses its caches
+	shared := controller.NewSharedControllerFactory(cache.NewSharedCachedFactory(nil, nil), nil)
+	factories, err = NewFactories(cfg, "", shared)
+	assert.NoError(err)
+	for _, f := range []controller.SharedControllerFactory{
+		factories.Helm.ControllerFactory(),
+		factories.Batch.ControllerFactory(),
+		factories.Rbac.ControllerFactory(),
+		factories.Core.ControllerFactory(),
+		factories.Networking.ControllerFactory(),
+	} {
+		assert.Equal(shared, f)
+	}
+}
@@ -0,0 +1,84 @@
+package helm
+
+import (
+	"context"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/sirupsen/logrus"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// runClusterRoleBindingGC periodically removes ClusterRoleBindings created for charts that no longer exist.
+// ClusterRoleBindings are cluster-scoped, so they are not removed along with the chart namespace, and the
+// chart finalizer may never get a chance to run if the namespace is deleted out from under it.
+func (c *Controller) runClusterRoleBindingGC(ctx context.Context) {
+	ticker := time.NewTicker(ClusterRoleBindingGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if DryRun {
+				continue
+			}
+			if err := c.gcClusterRoleBindings(); err != nil {
+				logrus.Errorf("Failed to clean up orphaned ClusterRoleBindings: %v", err)
+			}
+		}
+	}
+}
+
+func (c *Controller) gcClusterRoleBindings() error {
+	crbs, err := c.crbController.Cache().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, crb := range crbs {
+		namespace, name, ok := chartOwner(crb)
+		if !ok {
+			continue
+		}
+
+		_, err := c.helmController.Get(namespace, name, meta.GetOptions{})
+		if err == nil {
+			continue
+		} else if !errors.IsNotFound(err) {
+			logrus.Debugf("Unable to check owner of ClusterRoleBinding %s: %v", crb.Name, err)
+			continue
+		}
+
+		logrus.Infof("Deleting ClusterRoleBinding %s for deleted HelmChart %s/%s", crb.Name, namespace, name)
+		if err := c.crbController.Delete(crb.Name, &meta.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chartOwner returns the namespace and name of the HelmChart that a ClusterRoleBinding was created for,
+// based on the owner annotations set by apply. ClusterRoleBindings not created for a HelmChart,
+// or not named by the ClusterRoleBinding name template, are ignored.
+func chartOwner(crb *rbac.ClusterRoleBinding) (string, string, bool) {
+	if crb.Annotations[apply.LabelID] != Name || crb.Annotations[apply.LabelGVK] != helmv1.SchemeGroupVersion.WithKind("HelmChart").String() {
+		return "", "", false
+	}
+
+	namespace := crb.Annotations[apply.LabelNamespace]
+	name := crb.Annotations[apply.LabelName]
+	chart := &helmv1.HelmChart{ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: name}}
+	if namespace == "" || name == "" || crb.Name != render.ClusterRoleBindingName(chart) {
+		return "", "", false
+	}
+
+	return namespace, name, true
+}
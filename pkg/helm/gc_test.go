@@ -0,0 +1,39 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/stretchr/testify/assert"
+	rbac "k8s.io/api/rbac/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChartOwner(t *testing.T) {
+	assert := assert.New(t)
+	crb := &rbac.ClusterRoleBinding{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "helm-kube-system-traefik",
+			Annotations: map[string]string{
+				apply.LabelID:        Name,
+				apply.LabelGVK:       "helm.cattle.io/v1, Kind=HelmChart",
+				apply.LabelNamespace: "kube-system",
+				apply.LabelName:      "traefik",
+			},
+		},
+	}
+
+	namespace, name, ok := chartOwner(crb)
+	assert.True(ok)
+	assert.Equal("kube-system", namespace)
+	assert.Equal("traefik", name)
+
+	crb.Name = "cluster-admin"
+	_, _, ok = chartOwner(crb)
+	assert.False(ok)
+
+	crb.Name = "helm-kube-system-traefik"
+	crb.Annotations[apply.LabelGVK] = "apps/v1, Kind=Deployment"
+	_, _, ok = chartOwner(crb)
+	assert.False(ok)
+}
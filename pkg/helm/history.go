@@ -0,0 +1,117 @@
+package helm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/objectset"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HistoryValuesChanged is recorded when a job is replaced because the values or content of the chart changed
+	HistoryValuesChanged = "ValuesChanged"
+	// HistoryJobCreated is recorded when a job is created or replaced for the chart
+	HistoryJobCreated = "JobCreated"
+	// HistoryJobSucceeded is recorded when the chart's job succeeds
+	HistoryJobSucceeded = "JobSucceeded"
+	// HistoryJobFailed is recorded when the chart's job fails
+	HistoryJobFailed = "JobFailed"
+)
+
+// jobHistory returns the history entries for applying the chart's rendered job, and so must be called before the
+// job is applied: JobCreated if the job does not exist or differs from the existing job, preceded by ValuesChanged
+// if the values or content of the existing job differ. Each JobCreated entry names the hash of the job, so that a
+// job is not recorded again if the chart is handled before the job cache has seen it.
+func (c *Controller) jobHistory(chart *helmv1.HelmChart, objs *objectset.ObjectSet, now time.Time) []helmv1.HelmChartHistoryEntry {
+	desired := renderedJob(objs)
+	if StatusHistoryLimit <= 0 || desired == nil {
+		return nil
+	}
+	existing, err := c.jobsCache.Get(chart.Namespace, desired.Name)
+	if err == nil && jobMatches(existing, desired) {
+		return nil
+	}
+
+	created := helmv1.HelmChartHistoryEntry{
+		Type:    HistoryJobCreated,
+		Time:    meta.NewTime(now),
+		Message: fmt.Sprintf("created job %s/%s with hash %s", chart.Namespace, desired.Name, shortJobHash(desired.Annotations[render.JobHashAnnotation])),
+	}
+	if last := lastHistory(chart, HistoryJobCreated); last != nil && last.Message == created.Message {
+		return nil
+	}
+
+	var entries []helmv1.HelmChartHistoryEntry
+	if err == nil && existing.Spec.Template.Annotations[render.Annotation] != desired.Spec.Template.Annotations[render.Annotation] {
+		entries = append(entries, helmv1.HelmChartHistoryEntry{
+			Type:    HistoryValuesChanged,
+			Time:    meta.NewTime(now),
+			Message: fmt.Sprintf("values of job %s/%s changed", chart.Namespace, desired.Name),
+		})
+	}
+	return append(entries, created)
+}
+
+// conditionHistory returns the history entries for the chart's job having succeeded or failed since the status of
+// the previous version of the chart was recorded.
+func conditionHistory(previous, chart *helmv1.HelmChart, now time.Time) []helmv1.HelmChartHistoryEntry {
+	if StatusHistoryLimit <= 0 {
+		return nil
+	}
+	var entries []helmv1.HelmChartHistoryEntry
+	if jobSucceeded(chart) && !jobSucceeded(previous) {
+		entries = append(entries, helmv1.HelmChartHistoryEntry{
+			Type:    HistoryJobSucceeded,
+			Time:    meta.NewTime(now),
+			Message: fmt.Sprintf("job %s/%s succeeded", chart.Namespace, chart.Status.JobName),
+		})
+	}
+	if ConditionFailed.IsTrue(chart) && !ConditionFailed.IsTrue(previous) {
+		entries = append(entries, helmv1.HelmChartHistoryEntry{
+			Type:    HistoryJobFailed,
+			Time:    meta.NewTime(now),
+			Message: ConditionFailed.GetMessage(chart),
+		})
+	}
+	return entries
+}
+
+func jobSucceeded(chart *helmv1.HelmChart) bool {
+	return ConditionReady.IsTrue(chart) && ConditionReady.GetReason(chart) == "JobSucceeded"
+}
+
+// addHistory appends the entries to the chart's history, keeping only the most recent StatusHistoryLimit entries.
+func addHistory(chart *helmv1.HelmChart, entries ...helmv1.HelmChartHistoryEntry) {
+	if StatusHistoryLimit <= 0 {
+		chart.Status.History = nil
+		return
+	}
+	history := append(chart.Status.History, entries...)
+	if len(history) > StatusHistoryLimit {
+		history = history[len(history)-StatusHistoryLimit:]
+	}
+	chart.Status.History = history
+}
+
+// lastHistory returns the most recent history entry of the given type, or nil if there is none.
+func lastHistory(chart *helmv1.HelmChart, entryType string) *helmv1.HelmChartHistoryEntry {
+	for i := len(chart.Status.History) - 1; i >= 0; i-- {
+		if chart.Status.History[i].Type == entryType {
+			return &chart.Status.History[i]
+		}
+	}
+	return nil
+}
+
+// shortJobHash returns the first 12 characters of the hex digest in a job hash annotation.
+func shortJobHash(hash string) string {
+	hash = strings.TrimPrefix(hash, "SHA256=")
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	return hash
+}
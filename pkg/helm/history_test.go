@@ -0,0 +1,68 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+)
+
+func historyTypes(chart *v1.HelmChart) []string {
+	var types []string
+	for _, entry := range chart.Status.History {
+		types = append(types, entry.Type)
+	}
+	return types
+}
+
+func TestStatusHistory(t *testing.T) {
+	assert := assert.New(t)
+	defer func(limit int) { StatusHistoryLimit = limit }(StatusHistoryLimit)
+	StatusHistoryLimit = 4
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	defer forgetChart(chart)
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	c := &Controller{jobsCache: jobs}
+	now := time.Unix(1000, 0)
+
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	addHistory(chart, c.jobHistory(chart, objs, now)...)
+	assert.Equal([]string{HistoryJobCreated}, historyTypes(chart))
+
+	// the chart is handled again before the job cache has seen the job
+	addHistory(chart, c.jobHistory(chart, objs, now)...)
+	assert.Equal([]string{HistoryJobCreated}, historyTypes(chart))
+
+	installed := renderedJob(objs).DeepCopy()
+	jobs.jobs["kube-system/"+installed.Name] = installed
+	assert.Empty(c.jobHistory(chart, objs, now))
+
+	previous := chart.DeepCopy()
+	chart.Status.JobName = installed.Name
+	ConditionReady.True(chart)
+	ConditionReady.Reason(chart, "JobSucceeded")
+	addHistory(chart, conditionHistory(previous, chart, now)...)
+	assert.Equal([]string{HistoryJobCreated, HistoryJobSucceeded}, historyTypes(chart))
+	assert.Equal("job kube-system/helm-install-traefik succeeded", chart.Status.History[1].Message)
+
+	// the values change, and the new job fails
+	chart.Spec.ValuesContent = "replicas: 2\n"
+	changed, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	addHistory(chart, c.jobHistory(chart, changed, now.Add(time.Minute))...)
+	previous = chart.DeepCopy()
+	ConditionFailed.True(chart)
+	ConditionFailed.Message(chart, "BackoffLimitExceeded")
+	addHistory(chart, conditionHistory(previous, chart, now.Add(2*time.Minute))...)
+	assert.Equal([]string{HistoryJobSucceeded, HistoryValuesChanged, HistoryJobCreated, HistoryJobFailed}, historyTypes(chart))
+	assert.Equal(now.Add(2*time.Minute).Unix(), chart.Status.History[3].Time.Unix())
+
+	StatusHistoryLimit = 0
+	addHistory(chart)
+	assert.Nil(chart.Status.History)
+}
@@ -0,0 +1,47 @@
+package helm
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	batch "k8s.io/api/batch/v1"
+)
+
+// ChartReconciler reconciles HelmCharts and HelmChartConfigs. It is implemented by the Controller returned by
+// Register; embedders that drive or wrap the controller can depend on it instead, and mock it in their tests.
+type ChartReconciler interface {
+	OnHelmChange(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error)
+	OnHelmRemove(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error)
+	OnConfChange(key string, conf *helmv1.HelmChartConfig) (*helmv1.HelmChartConfig, error)
+}
+
+// JobBuilder renders the job that the controller runs for a chart in its current state.
+type JobBuilder interface {
+	// BuildJob returns the job for the chart, rendered with the given config, which may be nil, and the
+	// controller's current defaults. Nothing is created.
+	BuildJob(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (*batch.Job, error)
+}
+
+// ValuesMerger returns the values that a chart is installed with.
+type ValuesMerger interface {
+	// MergeValues returns the values content of the chart merged with that of the given config, which may be
+	// nil, after any ValuesTransformers have been applied. Values set with --set args are not included.
+	MergeValues(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (map[string]interface{}, error)
+}
+
+var (
+	_ ChartReconciler = &Controller{}
+	_ JobBuilder      = &Controller{}
+	_ ValuesMerger    = &Controller{}
+)
+
+func (c *Controller) BuildJob(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (*batch.Job, error) {
+	objs, err := c.renderObjects(chart, config, c.renderOptions())
+	if err != nil {
+		return nil, err
+	}
+	return renderedJob(objs), nil
+}
+
+func (c *Controller) MergeValues(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (map[string]interface{}, error) {
+	return render.MergeValues(chart, config, ValuesTransformers)
+}
@@ -0,0 +1,33 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeValues(t *testing.T) {
+	assert := assert.New(t)
+	defer func(transformers []render.ValuesTransformer) { ValuesTransformers = transformers }(ValuesTransformers)
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{
+		Chart:         "stable/traefik",
+		ValuesContent: "image:\n  tag: v1\nreplicas: 1\n",
+	}})
+	config := &v1.HelmChartConfig{Spec: v1.HelmChartConfigSpec{ValuesContent: "image:\n  tag: v2\n"}}
+
+	var merger ValuesMerger = &Controller{}
+	values, err := merger.MergeValues(chart, config)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"image": map[string]interface{}{"tag": "v2"}, "replicas": float64(1)}, values)
+
+	ValuesTransformers = []render.ValuesTransformer{render.ValuesTransformerFunc(func(chart *v1.HelmChart, values map[string]interface{}) (map[string]interface{}, error) {
+		values["clusterID"] = "edge"
+		return values, nil
+	})}
+	values, err = merger.MergeValues(chart, nil)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"image": map[string]interface{}{"tag": "v1"}, "replicas": float64(1), "clusterID": "edge"}, values)
+}
@@ -0,0 +1,50 @@
+package helm
+
+import (
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/objectset"
+)
+
+// renderObjects renders the objects for the chart. Unless UpgradeJobImage is set, charts that do not set their own
+// job image keep the image that their existing job was created with, as long as nothing else about the job has
+// changed, so that upgrading the controller to a new default job image does not re-run every chart. The new
+// image is used the next time the chart is changed.
+func (c *Controller) renderObjects(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig, opts render.Options) (*objectset.ObjectSet, error) {
+	objs, err := render.Objects(chart, config, opts)
+	if err != nil || UpgradeJobImage || strings.TrimSpace(chart.Spec.JobImage) != "" {
+		return objs, err
+	}
+
+	desired := renderedJob(objs)
+	existing, err := c.jobsCache.Get(chart.Namespace, render.JobName(chart))
+	if err != nil || desired == nil || jobMatches(existing, desired) || len(existing.Spec.Template.Spec.Containers) == 0 {
+		return objs, nil
+	}
+	image := existing.Spec.Template.Spec.Containers[0].Image
+	if image == desired.Spec.Template.Spec.Containers[0].Image {
+		return objs, nil
+	}
+
+	opts.JobImage = image
+	pinned, err := render.Objects(chart, config, opts)
+	if err != nil {
+		return nil, err
+	}
+	if jobMatches(existing, renderedJob(pinned)) {
+		return pinned, nil
+	}
+	return objs, nil
+}
+
+// jobImage returns the image of the rendered job's helm container, which is recorded in the chart's status so that
+// the image that a chart that does not set spec.jobImage resolved to can be seen.
+func jobImage(objs *objectset.ObjectSet) string {
+	job := renderedJob(objs)
+	if job == nil || len(job.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	return job.Spec.Template.Spec.Containers[0].Image
+}
@@ -0,0 +1,40 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+)
+
+func TestRenderObjectsJobImage(t *testing.T) {
+	assert := assert.New(t)
+	defer func(upgrade bool) { UpgradeJobImage = upgrade }(UpgradeJobImage)
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	c := &Controller{jobsCache: jobs}
+	objs, err := c.renderObjects(chart, nil, render.Options{JobImage: "rancher/klipper-helm:old"})
+	assert.NoError(err)
+	jobs.jobs["kube-system/"+render.JobName(chart)] = renderedJob(objs)
+
+	// the existing job keeps its image after the default changes
+	objs, err = c.renderObjects(chart, nil, render.Options{JobImage: "rancher/klipper-helm:new"})
+	assert.NoError(err)
+	assert.Equal("rancher/klipper-helm:old", jobImage(objs))
+
+	// until the chart is changed
+	changed := chart.DeepCopy()
+	changed.Spec.Version = "2.0.0"
+	objs, err = c.renderObjects(changed, nil, render.Options{JobImage: "rancher/klipper-helm:new"})
+	assert.NoError(err)
+	assert.Equal("rancher/klipper-helm:new", jobImage(objs))
+
+	// or all charts are upgraded
+	UpgradeJobImage = true
+	objs, err = c.renderObjects(chart, nil, render.Options{JobImage: "rancher/klipper-helm:new"})
+	assert.NoError(err)
+	assert.Equal("rancher/klipper-helm:new", jobImage(objs))
+}
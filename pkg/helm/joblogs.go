@@ -0,0 +1,179 @@
+package helm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// jobLogRetryInterval is how often the logs of a job pod are requested while its container is starting
+	jobLogRetryInterval = 2 * time.Second
+	// jobLogRetryTimeout is how long the logs of a job pod are retried for before giving up
+	jobLogRetryTimeout = 5 * time.Minute
+)
+
+var (
+	jobLogLock sync.Mutex
+	// jobLogPods holds the key of the job of each pod whose logs have been streamed, keyed by the pod UID
+	jobLogPods = map[types.UID]string{}
+)
+
+// streamJobLogs mirrors the logs of the pods of active chart jobs into the controller log, each line prefixed with
+// the namespace and name of the chart, so that helm failures can be found in the controller log instead of in the
+// logs of completed pods. The logs of each pod are streamed once, including pods of earlier failed attempts, and
+// the logs of its init containers and of each restart of its containers.
+func (c *Controller) streamJobLogs(ctx context.Context, key string, job *batch.Job) (*batch.Job, error) {
+	if job == nil || jobFinished(job) {
+		forgetJobLogs(key)
+		return job, nil
+	}
+	chartName := job.Labels[Label]
+	if chartName == "" || job.Status.Active == 0 {
+		return job, nil
+	}
+
+	pods, err := c.pods.Pods(job.Namespace).List(ctx, meta.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"controller-uid": string(job.UID)}).String(),
+	})
+	if err != nil {
+		return job, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if len(pod.Spec.Containers) == 0 || !startJobLogs(pod.UID, key) {
+			continue
+		}
+		var containers []string
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			containers = append(containers, container.Name)
+		}
+		go c.followPodLogs(ctx, job.Namespace+"/"+chartName, pod.Namespace, pod.Name, containers)
+	}
+	return job, nil
+}
+
+// followPodLogs logs the logs of each of the pod's containers in turn, starting with its init containers.
+func (c *Controller) followPodLogs(ctx context.Context, chart, namespace, name string, containers []string) {
+	for i, container := range containers {
+		prefix := chart
+		if i < len(containers)-1 {
+			prefix = chart + " " + container
+		}
+		if !c.followContainerLogs(ctx, prefix, namespace, name, container) {
+			return
+		}
+	}
+}
+
+// followContainerLogs logs each line of the logs of each instance of the container, until the container has
+// exited without being restarted. Logs cannot be requested until the container has started, so the pod is polled
+// until then, and again after each instance exits, to follow the next instance if the container is restarted.
+// Returns false if the pod's logs can no longer be streamed.
+func (c *Controller) followContainerLogs(ctx context.Context, chart, namespace, name, container string) bool {
+	deadline := time.Now().Add(jobLogRetryTimeout)
+	streamed := int32(-1)
+	for {
+		pod, err := c.pods.Pods(namespace).Get(ctx, name, meta.GetOptions{})
+		if errors.IsNotFound(err) {
+			return false
+		}
+		if err == nil {
+			options, next, done := nextContainerLogs(pod, container, streamed)
+			if done {
+				return true
+			}
+			if options == nil {
+				err = fmt.Errorf("timed out waiting for container to start")
+			} else if stream, streamErr := c.pods.Pods(namespace).GetLogs(name, options).Stream(ctx); streamErr != nil {
+				err = streamErr
+			} else {
+				logLines(chart, stream)
+				stream.Close()
+				streamed = next
+				deadline = time.Now().Add(jobLogRetryTimeout)
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			logrus.Warnf("Unable to stream logs of container %s of pod %s/%s for HelmChart %s: %v", container, namespace, name, chart, err)
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(jobLogRetryInterval):
+		}
+	}
+}
+
+// nextContainerLogs returns the options to request the logs of the next instance of the container that have not
+// been streamed, given the restart count of the last instance whose logs were, or -1 if none were, along with the
+// restart count of that next instance. No options are returned while the next instance has not yet started, and
+// done is returned once the container has exited without being restarted. The previous instance is requested
+// when it exited before its logs could be followed, so that the logs of a failed attempt are not skipped.
+func nextContainerLogs(pod *core.Pod, container string, streamed int32) (*core.PodLogOptions, int32, bool) {
+	podDone := pod.Status.Phase == core.PodSucceeded || pod.Status.Phase == core.PodFailed
+	var status *core.ContainerStatus
+	for _, statuses := range [][]core.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == container {
+				status = &statuses[i]
+			}
+		}
+	}
+	if status == nil {
+		return nil, streamed, podDone
+	}
+
+	if status.RestartCount > 0 && streamed < status.RestartCount-1 && status.LastTerminationState.Terminated != nil {
+		return &core.PodLogOptions{Container: container, Previous: true}, status.RestartCount - 1, false
+	}
+	if streamed < status.RestartCount && (status.State.Running != nil || status.State.Terminated != nil) {
+		return &core.PodLogOptions{Container: container, Follow: true}, status.RestartCount, false
+	}
+	if terminated := status.State.Terminated; terminated != nil && (terminated.ExitCode == 0 || pod.Spec.RestartPolicy == core.RestartPolicyNever) {
+		return nil, streamed, true
+	}
+	return nil, streamed, podDone
+}
+
+func logLines(chart string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		logrus.Infof("[%s] %s", chart, scanner.Text())
+	}
+}
+
+// startJobLogs records that the logs of the pod are being streamed, returning false if they already are.
+func startJobLogs(pod types.UID, job string) bool {
+	jobLogLock.Lock()
+	defer jobLogLock.Unlock()
+	if _, ok := jobLogPods[pod]; ok {
+		return false
+	}
+	jobLogPods[pod] = job
+	return true
+}
+
+// forgetJobLogs removes the pods of a job that has finished or been deleted.
+func forgetJobLogs(job string) {
+	jobLogLock.Lock()
+	defer jobLogLock.Unlock()
+	for pod, podJob := range jobLogPods {
+		if podJob == job {
+			delete(jobLogPods, pod)
+		}
+	}
+}
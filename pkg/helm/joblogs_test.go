@@ -0,0 +1,104 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+type podsGetter struct {
+	typedv1.PodInterface
+	pods     []core.Pod
+	selector string
+}
+
+func (p *podsGetter) Pods(namespace string) typedv1.PodInterface {
+	return p
+}
+
+func (p *podsGetter) List(ctx context.Context, opts meta.ListOptions) (*core.PodList, error) {
+	p.selector = opts.LabelSelector
+	return &core.PodList{Items: p.pods}, nil
+}
+
+func TestStreamJobLogs(t *testing.T) {
+	assert := assert.New(t)
+	pods := &podsGetter{pods: []core.Pod{{
+		ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "helm-install-traefik-abcde", UID: "pod-1"},
+		Spec:       core.PodSpec{Containers: []core.Container{{Name: "helm"}}},
+	}}}
+	c := &Controller{pods: pods}
+	key := "kube-system/helm-install-traefik"
+	defer forgetJobLogs(key)
+
+	// pods that are already being streamed are not streamed again
+	assert.True(startJobLogs("pod-1", key))
+	job := &batch.Job{
+		ObjectMeta: meta.ObjectMeta{Namespace: "kube-system", Name: "helm-install-traefik", UID: "job-1", Labels: map[string]string{Label: "traefik"}},
+		Status:     batch.JobStatus{Active: 1},
+	}
+	_, err := c.streamJobLogs(context.Background(), key, job)
+	assert.NoError(err)
+	assert.Equal("controller-uid=job-1", pods.selector)
+	assert.False(startJobLogs("pod-1", key))
+
+	// pods are forgotten once the job finishes
+	job.Status = batch.JobStatus{Conditions: []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}}
+	_, err = c.streamJobLogs(context.Background(), key, job)
+	assert.NoError(err)
+	assert.Empty(jobLogPods)
+}
+
+func TestNextContainerLogs(t *testing.T) {
+	assert := assert.New(t)
+	running := core.ContainerState{Running: &core.ContainerStateRunning{}}
+	waiting := core.ContainerState{Waiting: &core.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	failed := core.ContainerState{Terminated: &core.ContainerStateTerminated{ExitCode: 1}}
+	succeeded := core.ContainerState{Terminated: &core.ContainerStateTerminated{ExitCode: 0}}
+	pod := func(phase core.PodPhase, status core.ContainerStatus) *core.Pod {
+		status.Name = "helm"
+		return &core.Pod{
+			Spec:   core.PodSpec{RestartPolicy: core.RestartPolicyOnFailure},
+			Status: core.PodStatus{Phase: phase, ContainerStatuses: []core.ContainerStatus{status}},
+		}
+	}
+	follow := &core.PodLogOptions{Container: "helm", Follow: true}
+	previous := &core.PodLogOptions{Container: "helm", Previous: true}
+
+	tests := map[string]struct {
+		pod      *core.Pod
+		streamed int32
+		options  *core.PodLogOptions
+		next     int32
+		done     bool
+	}{
+		"not started":              {pod(core.PodPending, core.ContainerStatus{State: core.ContainerState{Waiting: &core.ContainerStateWaiting{}}}), -1, nil, -1, false},
+		"running":                  {pod(core.PodRunning, core.ContainerStatus{State: running}), -1, follow, 0, false},
+		"exited before following":  {pod(core.PodRunning, core.ContainerStatus{State: failed}), -1, follow, 0, false},
+		"waiting for restart":      {pod(core.PodRunning, core.ContainerStatus{State: failed}), 0, nil, 0, false},
+		"restarted":                {pod(core.PodRunning, core.ContainerStatus{State: running, RestartCount: 1, LastTerminationState: failed}), 0, follow, 1, false},
+		"restarted and exited":     {pod(core.PodRunning, core.ContainerStatus{State: waiting, RestartCount: 2, LastTerminationState: failed}), 0, previous, 1, false},
+		"previous streamed":        {pod(core.PodRunning, core.ContainerStatus{State: waiting, RestartCount: 2, LastTerminationState: failed}), 1, nil, 1, false},
+		"succeeded":                {pod(core.PodRunning, core.ContainerStatus{State: succeeded}), 0, nil, 0, true},
+		"pod failed":               {pod(core.PodFailed, core.ContainerStatus{State: failed}), 0, nil, 0, true},
+		"pod done without started": {pod(core.PodFailed, core.ContainerStatus{State: core.ContainerState{Waiting: &core.ContainerStateWaiting{}}}), -1, nil, -1, true},
+	}
+	for name, test := range tests {
+		options, next, done := nextContainerLogs(test.pod, "helm", test.streamed)
+		assert.Equal(test.options, options, name)
+		assert.Equal(test.next, next, name)
+		assert.Equal(test.done, done, name)
+	}
+
+	// init containers are found by name along with the containers
+	initPod := &core.Pod{Status: core.PodStatus{Phase: core.PodPending, InitContainerStatuses: []core.ContainerStatus{{Name: "plugins", State: running}}}}
+	options, next, done := nextContainerLogs(initPod, "plugins", -1)
+	assert.Equal(&core.PodLogOptions{Container: "plugins", Follow: true}, options)
+	assert.Equal(int32(0), next)
+	assert.False(done)
+}
@@ -0,0 +1,153 @@
+package helm
+
+import (
+	"context"
+	"sync"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/metrics"
+	"github.com/sirupsen/logrus"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// chartMetrics is the state needed to keep the metrics for a chart up to date between reconciles.
+type chartMetrics struct {
+	labels metrics.Labels
+	// job is the UID of the last job seen for the chart, and failed the number of failed attempts of that job
+	// that have been seen
+	job    types.UID
+	failed int32
+	// previousFailures is the number of failed attempts of earlier jobs since the chart was last installed successfully
+	previousFailures int32
+}
+
+var (
+	chartMetricsLock  sync.Mutex
+	chartMetricsByKey = map[string]*chartMetrics{}
+)
+
+// observeJob updates the metrics for the chart from the state of its current install job, and the number of times
+// the containers of the job's pods have been restarted. With the OnFailure restart policy, failed attempts restart
+// the container in the same pod, and are not counted in the job's Failed pods; a job that has failed also counts as
+// at least one failed attempt.
+func observeJob(chart *helmv1.HelmChart, job *batch.Job, restarts int32) {
+	if chart.DeletionTimestamp != nil {
+		return
+	}
+
+	chartMetricsLock.Lock()
+	defer chartMetricsLock.Unlock()
+
+	key := chart.Namespace + "/" + chart.Name
+	state := chartMetricsByKey[key]
+	if state == nil {
+		state = &chartMetrics{}
+		chartMetricsByKey[key] = state
+	}
+	labels := chartLabels(chart)
+	if state.labels != nil && !labelsEqual(state.labels, labels) {
+		metrics.DeleteChart(state.labels)
+	}
+	state.labels = labels
+
+	if job == nil {
+		return
+	}
+	if job.UID != state.job {
+		state.previousFailures += state.failed
+		state.job, state.failed = job.UID, 0
+	}
+
+	if job.Status.Succeeded > 0 {
+		state.failed, state.previousFailures = 0, 0
+		metrics.ConsecutiveFailures.Set(labels, 0)
+		if start, completion := job.Status.StartTime, job.Status.CompletionTime; completion != nil {
+			metrics.LastSuccess.Set(labels, float64(completion.Unix()))
+			if start != nil {
+				metrics.JobDuration.Set(labels, completion.Sub(start.Time).Seconds())
+			}
+		}
+		return
+	}
+	failed := job.Status.Failed + restarts
+	if failed == 0 && jobFailure(job) != nil {
+		failed = 1
+	}
+	// the restarts of pods that have since been removed are no longer counted, so the count never goes down
+	if failed > state.failed {
+		state.failed = failed
+	}
+	metrics.ConsecutiveFailures.Set(labels, float64(state.previousFailures+state.failed))
+}
+
+// jobRestarts returns the number of times the containers of the pods of an active job have been restarted.
+func (c *Controller) jobRestarts(ctx context.Context, job *batch.Job) int32 {
+	if job == nil || job.Status.Active == 0 || jobFinished(job) {
+		return 0
+	}
+	pods, err := c.pods.Pods(job.Namespace).List(ctx, meta.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"controller-uid": string(job.UID)}).String(),
+	})
+	if err != nil {
+		logrus.Debugf("Unable to count restarts of job %s/%s: %v", job.Namespace, job.Name, err)
+		return 0
+	}
+	var restarts int32
+	for _, pod := range pods.Items {
+		for _, statuses := range [][]core.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, status := range statuses {
+				restarts += status.RestartCount
+			}
+		}
+	}
+	return restarts
+}
+
+// observeSlowJob records whether the chart's current install job has been running for longer than expected.
+func observeSlowJob(chart *helmv1.HelmChart, slow bool) {
+	if chart.DeletionTimestamp != nil {
+		return
+	}
+	value := 0.0
+	if slow {
+		value = 1
+	}
+	metrics.JobSlow.Set(chartLabels(chart), value)
+}
+
+// forgetChart removes the metrics for a chart that has been deleted.
+func forgetChart(chart *helmv1.HelmChart) {
+	chartMetricsLock.Lock()
+	defer chartMetricsLock.Unlock()
+
+	key := chart.Namespace + "/" + chart.Name
+	if state := chartMetricsByKey[key]; state != nil {
+		metrics.DeleteChart(state.labels)
+		delete(chartMetricsByKey, key)
+	}
+}
+
+func chartLabels(chart *helmv1.HelmChart) metrics.Labels {
+	return metrics.Labels{
+		"namespace": chart.Namespace,
+		"name":      chart.Name,
+		"chart":     chart.Spec.Chart,
+		"version":   chart.Spec.Version,
+	}
+}
+
+func labelsEqual(a, b metrics.Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
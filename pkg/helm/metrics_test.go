@@ -0,0 +1,72 @@
+package helm
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObserveJob(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	defer forgetChart(chart)
+
+	failed := &batch.Job{ObjectMeta: meta.ObjectMeta{UID: "1"}, Status: batch.JobStatus{Failed: 2}}
+	observeJob(chart, failed, 0)
+	retried := &batch.Job{ObjectMeta: meta.ObjectMeta{UID: "2"}, Status: batch.JobStatus{Failed: 1}}
+	observeJob(chart, retried, 0)
+	assert.Equal(int32(3), chartMetricsByKey["kube-system/traefik"].previousFailures+chartMetricsByKey["kube-system/traefik"].failed)
+
+	// container restarts are failed attempts, and are still counted once the pods are removed
+	observeJob(chart, retried, 2)
+	assert.Equal(int32(5), chartMetricsByKey["kube-system/traefik"].previousFailures+chartMetricsByKey["kube-system/traefik"].failed)
+	observeJob(chart, retried, 0)
+	assert.Equal(int32(5), chartMetricsByKey["kube-system/traefik"].previousFailures+chartMetricsByKey["kube-system/traefik"].failed)
+
+	// a job that failed without any failed pods or restarts, such as one that reached its deadline, counts once
+	deadline := &batch.Job{ObjectMeta: meta.ObjectMeta{UID: "3"}, Status: batch.JobStatus{Conditions: []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue}}}}
+	observeJob(chart, deadline, 0)
+	assert.Equal(int32(6), chartMetricsByKey["kube-system/traefik"].previousFailures+chartMetricsByKey["kube-system/traefik"].failed)
+
+	start := meta.NewTime(time.Unix(1000, 0))
+	completion := meta.NewTime(time.Unix(1090, 0))
+	retried.Status = batch.JobStatus{Failed: 1, Succeeded: 1, StartTime: &start, CompletionTime: &completion}
+	observeJob(chart, retried, 0)
+	assert.Zero(chartMetricsByKey["kube-system/traefik"].previousFailures + chartMetricsByKey["kube-system/traefik"].failed)
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", metrics.Path, nil))
+	assert.Contains(recorder.Body.String(), `helm_controller_chart_job_duration_seconds{namespace="kube-system",name="traefik",chart="stable/traefik",version=""} 90`)
+
+	labels := chartMetricsByKey["kube-system/traefik"].labels
+	chart.Spec.Version = "2.0.0"
+	observeJob(chart, retried, 0)
+	assert.NotEqual(labels, chartMetricsByKey["kube-system/traefik"].labels)
+
+	forgetChart(chart)
+	assert.NotContains(chartMetricsByKey, "kube-system/traefik")
+}
+
+func TestJobRestarts(t *testing.T) {
+	assert := assert.New(t)
+	pods := &podsGetter{pods: []core.Pod{{Status: core.PodStatus{
+		InitContainerStatuses: []core.ContainerStatus{{Name: "plugins", RestartCount: 1}},
+		ContainerStatuses:     []core.ContainerStatus{{Name: "helm", RestartCount: 3}},
+	}}}}
+	c := &Controller{pods: pods}
+
+	job := &batch.Job{ObjectMeta: meta.ObjectMeta{UID: "job-1"}, Status: batch.JobStatus{Active: 1}}
+	assert.Equal(int32(4), c.jobRestarts(context.Background(), job))
+	assert.Equal("controller-uid=job-1", pods.selector)
+
+	job.Status = batch.JobStatus{Conditions: []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}}
+	assert.Zero(c.jobRestarts(context.Background(), job))
+	assert.Zero(c.jobRestarts(context.Background(), nil))
+}
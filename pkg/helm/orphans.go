@@ -0,0 +1,333 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OrphanPolicyIgnore does not check for resources left behind by upgrades
+	OrphanPolicyIgnore = "ignore"
+	// OrphanPolicyReport lists resources left behind by upgrades in the chart's status
+	OrphanPolicyReport = "report"
+	// OrphanPolicyDelete deletes resources left behind by upgrades
+	OrphanPolicyDelete = "delete"
+
+	// helm does not prune resources with this annotation, so they are not considered orphaned
+	helmResourcePolicyAnnotation = "helm.sh/resource-policy"
+	// helm records the release that owns a resource in these annotations
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+var manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// releaseResource identifies a resource in a release manifest.
+type releaseResource struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r releaseResource) String() string {
+	kind := r.Kind
+	if r.Group != "" {
+		kind = r.Kind + "." + r.Group
+	}
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, r.Namespace, r.Name)
+}
+
+// verifyRelease compares the resources in the chart's current release with those in the previous release, and
+// reports or deletes resources that were removed from the release by the upgrade but still exist. Helm prunes
+// these itself, unless it has lost track of them, for example when their ownership metadata was removed.
+// Resources that have since been adopted by another release are left alone, and only resources whose ownership
+// annotations still name the release are deleted; others are reported instead.
+func (c *Controller) verifyRelease(chart *helmv1.HelmChart) error {
+	chart.Status.OrphanedResources = nil
+	if (chart.Spec.OrphanPolicy != OrphanPolicyReport && chart.Spec.OrphanPolicy != OrphanPolicyDelete) || !localRelease(chart) {
+		return nil
+	}
+
+	namespace, name := render.TargetNamespace(chart), render.ReleaseName(chart)
+	current, previous, err := c.releaseManifests(namespace, name)
+	if err != nil || previous == "" {
+		return err
+	}
+	currentResources, err := manifestResources(current, false)
+	if err != nil {
+		return err
+	}
+	previousResources, err := manifestResources(previous, true)
+	if err != nil {
+		return err
+	}
+
+	var orphaned []string
+	for _, resource := range removedResources(previousResources, currentResources) {
+		object, err := c.getOrphan(namespace, resource)
+		if err != nil {
+			logrus.Warnf("Unable to check for orphaned %s from HelmChart %s/%s: %v", resource, chart.Namespace, chart.Name, err)
+			continue
+		}
+		if object == nil || adoptedByOtherRelease(object, namespace, name) {
+			continue
+		}
+		if chart.Spec.OrphanPolicy == OrphanPolicyDelete && ownedByRelease(object, namespace, name) {
+			if err := c.deleteOrphan(namespace, resource, object.GetUID()); err != nil {
+				return err
+			}
+			c.recorder.Eventf(chart, core.EventTypeNormal, "OrphanedResourceDeleted", "Deleted %s, which was removed from the release by the upgrade", resource)
+			continue
+		}
+		orphaned = append(orphaned, resource.String())
+	}
+
+	if len(orphaned) > 0 {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "OrphanedResources", "Resources removed from the release by the upgrade still exist: %s", strings.Join(orphaned, ", "))
+	}
+	chart.Status.OrphanedResources = orphaned
+	return nil
+}
+
+// releaseManifests returns the manifests of the latest two revisions of the release, from the Secrets that
+// helm stores the release history in. The previous manifest is empty if the release has a single revision.
+func (c *Controller) releaseManifests(namespace, name string) (string, string, error) {
+	secrets, err := c.releaseSecrets(namespace, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	var manifests []string
+	for i := 0; i < len(secrets) && i < 2; i++ {
+		manifest, err := releaseManifest(secrets[i].Data["release"])
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decode release Secret %s/%s: %w", namespace, secrets[i].Name, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+	for len(manifests) < 2 {
+		manifests = append(manifests, "")
+	}
+	return manifests[0], manifests[1], nil
+}
+
+// releaseSecrets returns the Secrets that helm stores the release history in, latest revision first. They are
+// listed from the apiserver rather than the cache, as the cache only covers the controller's own namespace when
+// the controller is namespaced, and may not yet have seen the revision created by a job that just finished.
+func (c *Controller) releaseSecrets(namespace, name string) ([]*core.Secret, error) {
+	list, err := c.secretController.List(namespace, meta.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"owner": "helm", "name": name}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]*core.Secret, 0, len(list.Items))
+	for i := range list.Items {
+		secrets = append(secrets, &list.Items[i])
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		vi, _ := strconv.Atoi(secrets[i].Labels["version"])
+		vj, _ := strconv.Atoi(secrets[j].Labels["version"])
+		return vi > vj
+	})
+	return secrets, nil
+}
+
+// helmRelease holds the fields of a helm release that the controller uses.
+type helmRelease struct {
+	Manifest string       `json:"manifest"`
+	Chart    releaseChart `json:"chart"`
+}
+
+type releaseChart struct {
+	Metadata struct {
+		Name       string `json:"name"`
+		Version    string `json:"version"`
+		AppVersion string `json:"appVersion"`
+	} `json:"metadata"`
+	// Digest is the sha256 digest of the chart as stored in the release, including its templates and default
+	// values, so that charts that were republished under the same version can be told apart.
+	Digest string `json:"-"`
+}
+
+func (c *releaseChart) UnmarshalJSON(data []byte) error {
+	type plain releaseChart
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	if string(data) != "null" {
+		c.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	}
+	return nil
+}
+
+// decodeRelease decodes a release as stored by helm: base64 encoded, usually gzipped, JSON.
+func decodeRelease(data []byte) (*helmRelease, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		if decoded, err = ioutil.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	release := &helmRelease{}
+	if err := json.Unmarshal(decoded, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+func releaseManifest(data []byte) (string, error) {
+	release, err := decodeRelease(data)
+	if err != nil {
+		return "", err
+	}
+	return release.Manifest, nil
+}
+
+// manifestResources returns the resources in a release manifest, optionally skipping those that helm keeps
+// when they are removed from the release. Resources without a namespace are either cluster-scoped or in the
+// release namespace, which is resolved once their scope is known.
+func manifestResources(manifest string, skipKept bool) ([]releaseResource, error) {
+	var resources []releaseResource
+	for _, doc := range manifestSeparator.Split(manifest, -1) {
+		object := struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name        string            `json:"name"`
+				Namespace   string            `json:"namespace"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		}{}
+		if err := yaml.Unmarshal([]byte(doc), &object); err != nil {
+			return nil, err
+		}
+		if object.Kind == "" || object.Metadata.Name == "" || (skipKept && object.Metadata.Annotations[helmResourcePolicyAnnotation] == "keep") {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(object.APIVersion)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, releaseResource{
+			Group:     gv.Group,
+			Kind:      object.Kind,
+			Namespace: object.Metadata.Namespace,
+			Name:      object.Metadata.Name,
+		})
+	}
+	return resources, nil
+}
+
+// removedResources returns the resources in previous that are not in current.
+func removedResources(previous, current []releaseResource) []releaseResource {
+	keep := map[releaseResource]bool{}
+	for _, resource := range current {
+		keep[resource] = true
+	}
+	var removed []releaseResource
+	for _, resource := range previous {
+		if !keep[resource] {
+			removed = append(removed, resource)
+		}
+	}
+	return removed
+}
+
+// getOrphan returns a resource removed from the release, or nil if it no longer exists.
+func (c *Controller) getOrphan(namespace string, resource releaseResource) (*unstructured.Unstructured, error) {
+	mapping, err := c.restMapper.RESTMapping(schema.GroupKind{Group: resource.Group, Kind: resource.Kind})
+	if err != nil {
+		return nil, err
+	}
+	client := c.dynamic.Resource(mapping.Resource)
+	var object *unstructured.Unstructured
+	if mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+		object, err = client.Namespace(orphanNamespace(namespace, resource)).Get(context.TODO(), resource.Name, meta.GetOptions{})
+	} else {
+		object, err = client.Get(context.TODO(), resource.Name, meta.GetOptions{})
+	}
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return object, err
+}
+
+// deleteOrphan deletes a resource removed from the release, as long as it is still the object with the given UID,
+// so that an object re-created in its place in the meantime is not deleted.
+func (c *Controller) deleteOrphan(namespace string, resource releaseResource, uid types.UID) error {
+	mapping, err := c.restMapper.RESTMapping(schema.GroupKind{Group: resource.Group, Kind: resource.Kind})
+	if err != nil {
+		return err
+	}
+	client := c.dynamic.Resource(mapping.Resource)
+	options := meta.DeleteOptions{Preconditions: &meta.Preconditions{UID: &uid}}
+	if mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+		err = client.Namespace(orphanNamespace(namespace, resource)).Delete(context.TODO(), resource.Name, options)
+	} else {
+		err = client.Delete(context.TODO(), resource.Name, options)
+	}
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		return nil
+	}
+	return err
+}
+
+// ownedByRelease returns true if the object's helm ownership annotations name the release.
+func ownedByRelease(object meta.Object, namespace, name string) bool {
+	annotations := object.GetAnnotations()
+	return annotations[helmReleaseNameAnnotation] == name && annotations[helmReleaseNamespaceAnnotation] == namespace
+}
+
+// adoptedByOtherRelease returns true if the object's helm ownership annotations name a release other than the
+// given one, as happens when a resource moves between charts.
+func adoptedByOtherRelease(object meta.Object, namespace, name string) bool {
+	annotations := object.GetAnnotations()
+	if annotations[helmReleaseNameAnnotation] == "" {
+		return false
+	}
+	return !ownedByRelease(object, namespace, name)
+}
+
+func orphanNamespace(namespace string, resource releaseResource) string {
+	if resource.Namespace != "" {
+		return resource.Namespace
+	}
+	return namespace
+}
@@ -0,0 +1,176 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	corecontroller "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const previousManifest = `---
+# Source: traefik/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: traefik
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: traefik
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: traefik-dashboard
+  annotations:
+    helm.sh/resource-policy: keep
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: traefik
+  namespace: ingress
+`
+
+const currentManifest = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: traefik
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: traefik
+  namespace: ingress
+`
+
+func TestRemovedResources(t *testing.T) {
+	assert := assert.New(t)
+	previous, err := manifestResources(previousManifest, true)
+	assert.NoError(err)
+	assert.Len(previous, 3)
+	current, err := manifestResources(currentManifest, false)
+	assert.NoError(err)
+
+	removed := removedResources(previous, current)
+	assert.Equal([]releaseResource{{Group: "policy", Kind: "PodSecurityPolicy", Name: "traefik"}}, removed)
+	assert.Equal("PodSecurityPolicy.policy traefik", removed[0].String())
+	assert.Equal("Deployment.apps ingress/traefik", current[1].String())
+}
+
+func TestReleaseManifest(t *testing.T) {
+	assert := assert.New(t)
+	release, _ := json.Marshal(map[string]interface{}{"name": "traefik", "version": 2, "manifest": currentManifest})
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(release)
+	writer.Close()
+
+	manifest, err := releaseManifest([]byte(base64.StdEncoding.EncodeToString(compressed.Bytes())))
+	assert.NoError(err)
+	assert.Equal(currentManifest, manifest)
+
+	manifest, err = releaseManifest([]byte(base64.StdEncoding.EncodeToString(release)))
+	assert.NoError(err)
+	assert.Equal(currentManifest, manifest)
+
+	_, err = releaseManifest([]byte("not base64!"))
+	assert.Error(err)
+}
+
+func TestDecodeRelease(t *testing.T) {
+	assert := assert.New(t)
+	data, _ := json.Marshal(map[string]interface{}{"name": "traefik", "chart": map[string]interface{}{"metadata": map[string]string{"name": "traefik", "version": "2.0.0-rc.1"}}})
+	release, err := decodeRelease([]byte(base64.StdEncoding.EncodeToString(data)))
+	assert.NoError(err)
+	assert.Equal("2.0.0-rc.1", release.Chart.Metadata.Version)
+	assert.Equal("traefik", release.Chart.Metadata.Name)
+	assert.Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"metadata":{"name":"traefik","version":"2.0.0-rc.1"}}`))), release.Chart.Digest)
+
+	data, _ = json.Marshal(map[string]interface{}{"name": "traefik", "manifest": currentManifest})
+	release, err = decodeRelease([]byte(base64.StdEncoding.EncodeToString(data)))
+	assert.NoError(err)
+	assert.Empty(release.Chart.Digest)
+}
+
+type secretController struct {
+	corecontroller.SecretController
+	secrets []core.Secret
+	deleted []string
+}
+
+func (c *secretController) List(namespace string, opts meta.ListOptions) (*core.SecretList, error) {
+	selector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	list := &core.SecretList{}
+	for _, secret := range c.secrets {
+		if secret.Namespace == namespace && selector.Matches(labels.Set(secret.Labels)) {
+			list.Items = append(list.Items, secret)
+		}
+	}
+	return list, nil
+}
+
+func (c *secretController) Delete(namespace, name string, opts *meta.DeleteOptions) error {
+	c.deleted = append(c.deleted, namespace+"/"+name)
+	return nil
+}
+
+func TestReleaseSecrets(t *testing.T) {
+	assert := assert.New(t)
+	release := func(namespace, name, version string) core.Secret {
+		return core.Secret{ObjectMeta: meta.ObjectMeta{
+			Namespace: namespace,
+			Name:      "sh.helm.release.v1." + name + ".v" + version,
+			Labels:    map[string]string{"owner": "helm", "name": name, "version": version},
+		}}
+	}
+	c := &Controller{secretController: &secretController{secrets: []core.Secret{
+		release("traefik", "traefik", "1"),
+		release("traefik", "traefik", "10"),
+		release("traefik", "traefik", "2"),
+		release("traefik", "other", "3"),
+		release("default", "traefik", "4"),
+	}}}
+
+	secrets, err := c.releaseSecrets("traefik", "traefik")
+	assert.NoError(err)
+	var names []string
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	assert.Equal([]string{"sh.helm.release.v1.traefik.v10", "sh.helm.release.v1.traefik.v2", "sh.helm.release.v1.traefik.v1"}, names)
+}
+
+func TestOrphanOwnership(t *testing.T) {
+	assert := assert.New(t)
+	object := &meta.ObjectMeta{}
+	assert.False(ownedByRelease(object, "traefik", "traefik"))
+	assert.False(adoptedByOtherRelease(object, "traefik", "traefik"))
+
+	object.Annotations = map[string]string{helmReleaseNameAnnotation: "traefik", helmReleaseNamespaceAnnotation: "traefik"}
+	assert.True(ownedByRelease(object, "traefik", "traefik"))
+	assert.False(adoptedByOtherRelease(object, "traefik", "traefik"))
+
+	object.Annotations[helmReleaseNameAnnotation] = "ingress"
+	assert.False(ownedByRelease(object, "traefik", "traefik"))
+	assert.True(adoptedByOtherRelease(object, "traefik", "traefik"))
+
+	object.Annotations[helmReleaseNameAnnotation] = "traefik"
+	object.Annotations[helmReleaseNamespaceAnnotation] = "default"
+	assert.True(adoptedByOtherRelease(object, "traefik", "traefik"))
+}
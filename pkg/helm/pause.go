@@ -0,0 +1,41 @@
+package helm
+
+import (
+	"fmt"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// paused returns true if reconciliation of the chart has been paused by annotating its namespace, so that all
+// charts in an environment can be left untouched during maintenance. Charts that are being deleted are never
+// paused, so that the finalizer is not blocked.
+func (c *Controller) paused(chart *helmv1.HelmChart) (bool, error) {
+	if chart.DeletionTimestamp != nil {
+		return false, nil
+	}
+	namespace, err := c.namespaceCache.Get(chart.Namespace)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return namespacePaused(namespace), nil
+}
+
+func namespacePaused(namespace *core.Namespace) bool {
+	return namespace != nil && namespace.Annotations[PauseAnnotation] == "true"
+}
+
+// setPausedCondition records that the chart is not being reconciled because its namespace is paused.
+func (c *Controller) setPausedCondition(chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+	chartCopy := chart.DeepCopy()
+	if !ConditionPaused.IsTrue(chartCopy) {
+		c.recorder.Eventf(chart, core.EventTypeNormal, "ReconcilePaused", "Not reconciling HelmChart: namespace %s is paused by the %s annotation", chart.Namespace, PauseAnnotation)
+	}
+	ConditionPaused.True(chartCopy)
+	ConditionPaused.Reason(chartCopy, "NamespacePaused")
+	ConditionPaused.Message(chartCopy, fmt.Sprintf("namespace %s is paused by the %s annotation", chart.Namespace, PauseAnnotation))
+	return c.updateStatus(chartCopy)
+}
@@ -0,0 +1,57 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/objectset"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+)
+
+// logApplyPlan records which of the chart's objects applying the rendered objects would create, update or
+// delete, using a dry run of the apply. It is used when LogApplyPlan is set, to help explain unexpected job
+// recreation: a Job that is updated is deleted and re-created, unless only its metadata has changed.
+func (c *Controller) logApplyPlan(chart *helmv1.HelmChart, objs *objectset.ObjectSet) {
+	plan, err := c.apply.WithOwner(chart).DryRun(objs.All()...)
+	if err != nil {
+		logrus.Warnf("Failed to plan apply for HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+		return
+	}
+	summary := describePlan(plan)
+	if summary == "" {
+		return
+	}
+	logrus.Infof("Applying HelmChart %s/%s will %s", chart.Namespace, chart.Name, summary)
+	for gvk, patches := range plan.Update {
+		for key, patch := range patches {
+			logrus.Debugf("Applying HelmChart %s/%s will patch %s %s: %s", chart.Namespace, chart.Name, gvk.Kind, key, patch)
+		}
+	}
+	c.recorder.Eventf(chart, core.EventTypeNormal, "ApplyPlan", "Applying HelmChart will %s", summary)
+}
+
+// describePlan returns a sorted list of the objects that the plan creates, updates and deletes.
+func describePlan(plan apply.Plan) string {
+	var changes []string
+	for gvk, keys := range plan.Create {
+		for _, key := range keys {
+			changes = append(changes, fmt.Sprintf("create %s %s", gvk.Kind, key))
+		}
+	}
+	for gvk, patches := range plan.Update {
+		for key := range patches {
+			changes = append(changes, fmt.Sprintf("update %s %s", gvk.Kind, key))
+		}
+	}
+	for gvk, keys := range plan.Delete {
+		for _, key := range keys {
+			changes = append(changes, fmt.Sprintf("delete %s %s", gvk.Kind, key))
+		}
+	}
+	sort.Strings(changes)
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,28 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/rancher/wrangler/pkg/apply"
+	"github.com/rancher/wrangler/pkg/objectset"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+func TestDescribePlan(t *testing.T) {
+	assert := assert.New(t)
+	assert.Empty(describePlan(apply.Plan{}))
+
+	plan := apply.Plan{
+		Create: objectset.ObjectKeyByGVK{},
+		Update: apply.PatchByGVK{},
+		Delete: objectset.ObjectKeyByGVK{},
+	}
+	jobGVK := batch.SchemeGroupVersion.WithKind("Job")
+	configMapGVK := core.SchemeGroupVersion.WithKind("ConfigMap")
+	plan.Update.Add(jobGVK, "kube-system", "helm-install-traefik", `{"spec":{}}`)
+	plan.Create[configMapGVK] = []objectset.ObjectKey{{Namespace: "kube-system", Name: "chart-values-traefik"}}
+	plan.Delete[configMapGVK] = []objectset.ObjectKey{{Namespace: "kube-system", Name: "chart-content-traefik"}}
+	assert.Equal("create ConfigMap kube-system/chart-values-traefik, delete ConfigMap kube-system/chart-content-traefik, update Job kube-system/helm-install-traefik", describePlan(plan))
+}
@@ -0,0 +1,80 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/apply/injectors"
+	batchcontroller "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// podFailurePolicyInjector returns an apply injector that copies the pod failure policy recorded on a job's
+// PodFailurePolicyAnnotation into the spec of the job, which the Job type that the controller is built against
+// does not have. The policy cannot be changed once the job has been created, so it is only added to jobs that do
+// not exist yet; a change to the policy changes the job hash, so the existing job is replaced instead.
+func podFailurePolicyInjector(jobs batchcontroller.JobCache) injectors.ConfigInjector {
+	return func(objs []runtime.Object) ([]runtime.Object, error) {
+		result := make([]runtime.Object, 0, len(objs))
+		for _, obj := range objs {
+			job, ok := obj.(*batch.Job)
+			if !ok || job.Annotations[render.PodFailurePolicyAnnotation] == "" {
+				result = append(result, obj)
+				continue
+			}
+			if _, err := jobs.Get(job.Namespace, job.Name); !errors.IsNotFound(err) {
+				result = append(result, obj)
+				continue
+			}
+			injected, err := withPodFailurePolicy(job)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, injected)
+		}
+		return result, nil
+	}
+}
+
+// withPodFailurePolicy returns the job as an unstructured object with the pod failure policy from its
+// PodFailurePolicyAnnotation set in its spec.
+func withPodFailurePolicy(job *batch.Job) (*unstructured.Unstructured, error) {
+	policy := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(job.Annotations[render.PodFailurePolicyAnnotation]), &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode pod failure policy of job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(job)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: data}
+	if err := unstructured.SetNestedMap(obj.Object, policy, "spec", "podFailurePolicy"); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// withoutPodFailurePolicy removes the pod failure policy from a patch to a job. The policy is only set when the
+// job is created, and is not seen on the cached job, so patches add or remove it even when it has not changed.
+func withoutPodFailurePolicy(data []byte) ([]byte, error) {
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	spec, ok := patch["spec"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+	if _, ok := spec["podFailurePolicy"]; !ok {
+		return data, nil
+	}
+	delete(spec, "podFailurePolicy")
+	if len(spec) == 0 {
+		delete(patch, "spec")
+	}
+	return json.Marshal(patch)
+}
@@ -0,0 +1,67 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPodFailurePolicyInjector(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{
+		Chart:         "stable/traefik",
+		RestartPolicy: "Never",
+		PodFailurePolicy: &v1.HelmChartPodFailurePolicy{Rules: []v1.HelmChartPodFailurePolicyRule{{
+			Action:      render.PodFailurePolicyActionFailJob,
+			OnExitCodes: &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: render.PodFailurePolicyOperatorIn, Values: []int32{3}},
+		}}},
+	}})
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	inject := podFailurePolicyInjector(jobs)
+
+	// the policy is set on jobs that are being created
+	injected, err := inject(objs.All())
+	assert.NoError(err)
+	job, ok := injected[len(injected)-1].(*unstructured.Unstructured)
+	if assert.True(ok) {
+		assert.Equal(batch.SchemeGroupVersion.WithKind("Job"), job.GroupVersionKind())
+		assert.Equal(render.JobName(chart), job.GetName())
+		rules, _, _ := unstructured.NestedSlice(job.Object, "spec", "podFailurePolicy", "rules")
+		assert.Equal([]interface{}{map[string]interface{}{
+			"action":      "FailJob",
+			"onExitCodes": map[string]interface{}{"operator": "In", "values": []interface{}{float64(3)}},
+		}}, rules)
+		assert.Equal("Never", job.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["restartPolicy"])
+	}
+	for _, obj := range injected[:len(injected)-1] {
+		_, ok := obj.(*unstructured.Unstructured)
+		assert.False(ok)
+	}
+
+	// but not on jobs that already exist, as it cannot be changed
+	jobs.jobs["kube-system/"+render.JobName(chart)] = renderedJob(objs)
+	injected, err = inject(objs.All())
+	assert.NoError(err)
+	_, ok = injected[len(injected)-1].(*batch.Job)
+	assert.True(ok)
+}
+
+func TestWithoutPodFailurePolicy(t *testing.T) {
+	assert := assert.New(t)
+	for patch, expected := range map[string]string{
+		`{"spec":{"podFailurePolicy":null}}`:                                      `{}`,
+		`{"spec":{"podFailurePolicy":{"rules":[]},"backoffLimit":1}}`:             `{"spec":{"backoffLimit":1}}`,
+		`{"metadata":{"annotations":{"a":"b"}},"spec":{"podFailurePolicy":null}}`: `{"metadata":{"annotations":{"a":"b"}}}`,
+		`{"metadata":{"annotations":{"a":"b"}}}`:                                  `{"metadata":{"annotations":{"a":"b"}}}`,
+	} {
+		data, err := withoutPodFailurePolicy([]byte(patch))
+		assert.NoError(err)
+		assert.JSONEq(expected, string(data), patch)
+	}
+}
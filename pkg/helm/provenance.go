@@ -0,0 +1,92 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"sigs.k8s.io/yaml"
+)
+
+// releaseProvenance returns the provenance of the chart's latest deployed release, read from its release Secret,
+// so that it is recorded in a ConfigMap alongside the chart's other generated objects. Nothing is recorded until
+// the chart has been installed, or for charts whose release is in a target context.
+func (c *Controller) releaseProvenance(chart *helmv1.HelmChart) (*render.Provenance, error) {
+	if !RecordProvenance || !localRelease(chart) {
+		return nil, nil
+	}
+	namespace, name := render.TargetNamespace(chart), render.ReleaseName(chart)
+	secrets, err := c.releaseSecrets(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if secret.Labels["status"] != "deployed" {
+			continue
+		}
+		release, err := decodeRelease(secret.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode release Secret %s/%s: %w", namespace, secret.Name, err)
+		}
+		images, err := manifestImages(release.Manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest of release Secret %s/%s: %w", namespace, secret.Name, err)
+		}
+		revision, _ := strconv.Atoi(secret.Labels["version"])
+		return &render.Provenance{
+			Chart:      release.Chart.Metadata.Name,
+			Version:    release.Chart.Metadata.Version,
+			AppVersion: release.Chart.Metadata.AppVersion,
+			Revision:   revision,
+			Images:     images,
+		}, nil
+	}
+	return nil, nil
+}
+
+// manifestImages returns the images of the containers, init containers and ephemeral containers of all pod specs
+// in a release manifest, wherever they are nested, so that workload types that helm does not know about are
+// included.
+func manifestImages(manifest string) ([]string, error) {
+	found := map[string]bool{}
+	for _, doc := range manifestSeparator.Split(manifest, -1) {
+		var object interface{}
+		if err := yaml.Unmarshal([]byte(doc), &object); err != nil {
+			return nil, err
+		}
+		collectImages(object, found)
+	}
+
+	images := make([]string, 0, len(found))
+	for image := range found {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+func collectImages(value interface{}, found map[string]bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if key == "containers" || key == "initContainers" || key == "ephemeralContainers" {
+				if containers, ok := child.([]interface{}); ok {
+					for _, container := range containers {
+						if container, ok := container.(map[string]interface{}); ok {
+							if image, ok := container["image"].(string); ok && image != "" {
+								found[image] = true
+							}
+						}
+					}
+				}
+			}
+			collectImages(child, found)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectImages(child, found)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestImages(t *testing.T) {
+	assert := assert.New(t)
+	manifest := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: traefik
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: docker.io/busybox:1.34
+      containers:
+      - name: traefik
+        image: docker.io/traefik:2.5.1
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: docker.io/busybox:1.34
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  containers: none
+`
+	images, err := manifestImages(manifest)
+	assert.NoError(err)
+	assert.Equal([]string{"docker.io/busybox:1.34", "docker.io/traefik:2.5.1"}, images)
+
+	_, err = manifestImages("kind: [")
+	assert.Error(err)
+}
@@ -0,0 +1,177 @@
+package helm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/rancher/wrangler/pkg/objectset"
+	core "k8s.io/api/core/v1"
+)
+
+// repoCheckTimeout is how long the controller waits for a chart's repo to respond
+const repoCheckTimeout = 10 * time.Second
+
+// repoCheck is the result of checking that a chart's repo can be reached.
+type repoCheck struct {
+	url string
+	err error
+}
+
+// checkRepo checks that the chart's repo can be reached from the controller, if CheckRepoReachability is set and
+// a new job is about to be applied for the chart, so that unreachable repos are reported as soon as the job is
+// created instead of after it has failed and backed off. Only repo indexes and artifact URLs are checked, with a
+// HEAD request through the chart's proxy and with its CA certificates. Any response other than a server error or
+// not found is counted as reachable, as authentication is left to the job. It returns nil if the repo was not
+// checked.
+func (c *Controller) checkRepo(chart *helmv1.HelmChart, objs *objectset.ObjectSet) *repoCheck {
+	if !CheckRepoReachability || chart.DeletionTimestamp != nil || c.jobUpToDate(chart, objs) {
+		return nil
+	}
+	target := repoCheckURL(chart)
+	if target == "" {
+		return nil
+	}
+
+	client, err := c.repoClient(chart)
+	if err != nil {
+		return &repoCheck{url: target, err: err}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), repoCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return &repoCheck{url: target, err: err}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return &repoCheck{url: target, err: err}
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode >= http.StatusInternalServerError {
+		return &repoCheck{url: target, err: fmt.Errorf("server returned %s", resp.Status)}
+	}
+	return &repoCheck{url: target}
+}
+
+// setRepoReachableCondition sets the RepoReachable condition from the result of checkRepo, recording a warning
+// event when the repo first becomes unreachable. The condition is kept as it is if the repo was not checked, and
+// removed if the chart is not installed from a repo or checks are disabled.
+func (c *Controller) setRepoReachableCondition(chart *helmv1.HelmChart, check *repoCheck) {
+	if !CheckRepoReachability || repoCheckURL(chart) == "" {
+		if ConditionRepoReachable.GetStatus(chart) != "" {
+			ConditionRepoReachable.False(chart)
+			ConditionRepoReachable.Reason(chart, "")
+			ConditionRepoReachable.Message(chart, "")
+		}
+		return
+	}
+	if check == nil {
+		return
+	}
+	if check.err == nil {
+		ConditionRepoReachable.True(chart)
+		ConditionRepoReachable.Reason(chart, "Reachable")
+		ConditionRepoReachable.Message(chart, "")
+		return
+	}
+
+	message := fmt.Sprintf("%s is not reachable from the controller: %v", check.url, check.err)
+	if ConditionRepoReachable.GetMessage(chart) != message {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "RepoUnreachable", "Repo %s is not reachable from the controller: %v", check.url, check.err)
+	}
+	ConditionRepoReachable.False(chart)
+	ConditionRepoReachable.Reason(chart, "Unreachable")
+	ConditionRepoReachable.Message(chart, message)
+}
+
+// repoCheckURL returns the URL that is requested to check the chart's repo: the artifact URL itself, or the index
+// of an http or https repo, after applying any RepoMirrors. OCI repos are not checked.
+func repoCheckURL(chart *helmv1.HelmChart) string {
+	repo, _ := render.RepoSource(chart, render.Options{RepoMirrors: RepoMirrors})
+	if render.IsArtifactURL(repo) {
+		return repo
+	}
+	if u, err := url.Parse(repo); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimSuffix(repo, "/") + "/index.yaml"
+}
+
+// repoClient returns an http client that trusts the chart's RepoCA and CA bundle in addition to the system roots,
+// and uses the proxy from the chart's ProxySecret, or otherwise from the controller's environment, as the job does.
+func (c *Controller) repoClient(chart *helmv1.HelmChart) (*http.Client, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	roots.AppendCertsFromPEM([]byte(chart.Spec.RepoCA))
+	if caBundle := render.CABundle(chart, render.Options{CABundle: CABundle}); caBundle != nil {
+		configMap, err := c.configMapCache.Get(chart.Namespace, caBundle.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle ConfigMap %s/%s: %w", chart.Namespace, caBundle.Name, err)
+		}
+		key := caBundle.Key
+		if key == "" {
+			key = render.DefaultCABundleKey
+		}
+		roots.AppendCertsFromPEM([]byte(configMap.Data[key]))
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if ref := chart.Spec.ProxySecret; ref != nil && ref.Name != "" {
+		secret, err := c.secretCache.Get(chart.Namespace, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get proxy Secret %s/%s: %w", chart.Namespace, ref.Name, err)
+		}
+		proxy = secretProxy(secret)
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           proxy,
+			TLSClientConfig: &tls.Config{RootCAs: roots},
+		},
+	}, nil
+}
+
+// secretProxy returns a proxy func that uses the proxy env vars held in a proxy Secret, in either case. Hosts that
+// match an entry of NO_PROXY, or a subdomain of it, are not proxied.
+func secretProxy(secret *core.Secret) func(*http.Request) (*url.URL, error) {
+	env := func(key string) string {
+		if value := string(secret.Data[key]); value != "" {
+			return value
+		}
+		return string(secret.Data[strings.ToLower(key)])
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+		for _, entry := range strings.Split(env("NO_PROXY"), ",") {
+			entry = strings.TrimPrefix(strings.TrimSpace(entry), ".")
+			if entry == "*" || (entry != "" && (host == entry || strings.HasSuffix(host, "."+entry))) {
+				return nil, nil
+			}
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(net.ParseIP(host)) {
+				return nil, nil
+			}
+		}
+		proxy := env("ALL_PROXY")
+		if req.URL.Scheme == "https" && env("HTTPS_PROXY") != "" {
+			proxy = env("HTTPS_PROXY")
+		} else if req.URL.Scheme == "http" && env("HTTP_PROXY") != "" {
+			proxy = env("HTTP_PROXY")
+		}
+		if proxy == "" {
+			return nil, nil
+		}
+		return url.Parse(proxy)
+	}
+}
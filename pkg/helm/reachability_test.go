@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRepoReachableCondition(t *testing.T) {
+	assert := assert.New(t)
+	defer func(check bool) { CheckRepoReachability = check }(CheckRepoReachability)
+	CheckRepoReachability = true
+
+	var requested []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.Method+" "+r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "traefik", Repo: server.URL + "/charts/"}})
+	objs, err := render.Objects(chart, nil, render.Options{})
+	assert.NoError(err)
+	jobs := &jobCache{jobs: map[string]*batch.Job{}}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{jobsCache: jobs, recorder: recorder}
+
+	c.setRepoReachableCondition(chart, c.checkRepo(chart, objs))
+	assert.True(ConditionRepoReachable.IsTrue(chart))
+	assert.Equal([]string{"HEAD /charts/index.yaml"}, requested)
+
+	status = http.StatusNotFound
+	c.setRepoReachableCondition(chart, c.checkRepo(chart, objs))
+	c.setRepoReachableCondition(chart, c.checkRepo(chart, objs))
+	assert.True(ConditionRepoReachable.IsFalse(chart))
+	assert.Equal("Unreachable", ConditionRepoReachable.GetReason(chart))
+	assert.Contains(ConditionRepoReachable.GetMessage(chart), "server returned 404 Not Found")
+	assert.Len(recorder.Events, 1)
+
+	// the repo is not checked again once the job is up to date
+	jobs.jobs["kube-system/helm-install-traefik"] = renderedJob(objs)
+	c.setRepoReachableCondition(chart, c.checkRepo(chart, objs))
+	assert.Len(requested, 3)
+	assert.True(ConditionRepoReachable.IsFalse(chart))
+
+	chart.Spec.Repo = "oci://registry.example.com/charts"
+	c.setRepoReachableCondition(chart, c.checkRepo(chart, objs))
+	assert.Empty(ConditionRepoReachable.GetReason(chart))
+}
+
+func TestSecretProxy(t *testing.T) {
+	assert := assert.New(t)
+	proxy := secretProxy(&core.Secret{Data: map[string][]byte{
+		"HTTPS_PROXY": []byte("http://proxy.example.com:3128"),
+		"no_proxy":    []byte("internal.example.com,10.0.0.0/8"),
+	}})
+
+	for target, expected := range map[string]string{
+		"https://charts.example.com/index.yaml":      "http://proxy.example.com:3128",
+		"https://charts.internal.example.com/a.yaml": "",
+		"https://10.1.2.3/index.yaml":                "",
+		"http://charts.example.com/index.yaml":       "",
+	} {
+		u, _ := url.Parse(target)
+		proxyURL, err := proxy(&http.Request{URL: u})
+		assert.NoError(err)
+		if expected == "" {
+			assert.Nil(proxyURL, target)
+		} else if assert.NotNil(proxyURL, target) {
+			assert.Equal(expected, proxyURL.String())
+		}
+	}
+}
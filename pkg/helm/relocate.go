@@ -0,0 +1,137 @@
+package helm
+
+import (
+	"fmt"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	TargetNamespacePolicyIgnore  = render.TargetNamespacePolicyIgnore
+	TargetNamespacePolicyReject  = render.TargetNamespacePolicyReject
+	TargetNamespacePolicyMigrate = render.TargetNamespacePolicyMigrate
+)
+
+// relocateRelease handles a change to the release name or target namespace of a chart that has already been
+// installed, according to the chart's TargetNamespacePolicy. It returns false if the chart should not be installed
+// under its new release name or into its new target namespace yet, along with the updated chart.
+func (c *Controller) relocateRelease(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (*helmv1.HelmChart, bool, error) {
+	previous := previousRelease(chart)
+	current := fmt.Sprintf("%s/%s", render.TargetNamespace(chart), render.ReleaseName(chart))
+	if chart.Status.TargetNamespace == "" || previous == current || chart.DeletionTimestamp != nil {
+		return chart, true, nil
+	}
+
+	switch chart.Spec.TargetNamespacePolicy {
+	case TargetNamespacePolicyReject:
+		chartCopy := chart.DeepCopy()
+		if ConditionReady.GetReason(chartCopy) != "TargetNamespaceChangeRejected" {
+			c.recorder.Eventf(chart, core.EventTypeWarning, "TargetNamespaceChangeRejected", "Not moving release %s to %s: release name and target namespace changes are rejected by the chart's targetNamespacePolicy", previous, current)
+		}
+		ConditionReady.False(chartCopy)
+		ConditionReady.Reason(chartCopy, "TargetNamespaceChangeRejected")
+		ConditionReady.Message(chartCopy, fmt.Sprintf("release cannot be moved from %s to %s", previous, current))
+		updated, err := c.updateStatus(chartCopy)
+		return updated, false, err
+	case TargetNamespacePolicyMigrate:
+		updated, err := c.uninstallPrevious(chart, config)
+		return updated, false, err
+	}
+
+	return chart, true, nil
+}
+
+// previousRelease returns the namespace and name of the release that was last installed for the chart.
+// Charts installed before the release name was recorded in their status were installed under the chart name.
+func previousRelease(chart *helmv1.HelmChart) string {
+	name := chart.Status.ReleaseName
+	if name == "" {
+		name = chart.Name
+	}
+	return fmt.Sprintf("%s/%s", chart.Status.TargetNamespace, name)
+}
+
+// uninstallPrevious removes the chart's previous release, by running the delete job for it or, if the chart
+// keeps resources on relocation, by removing only the release's helm storage. Once the previous release is gone,
+// the chart's status is updated so that the release is installed under its new name and target namespace.
+func (c *Controller) uninstallPrevious(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (*helmv1.HelmChart, error) {
+	previousChart := chart.DeepCopy()
+	previousChart.Spec.TargetNamespace = chart.Status.TargetNamespace
+	previousChart.Spec.ReleaseName = chart.Status.ReleaseName
+	previousChart.DeletionTimestamp = &meta.Time{}
+	previous := previousRelease(chart)
+
+	chartCopy := chart.DeepCopy()
+	if chart.Spec.KeepResourcesOnRelocate {
+		if err := c.deleteReleaseStorage(render.TargetNamespace(previousChart), render.ReleaseName(previousChart)); err != nil {
+			return chart, err
+		}
+		c.recorder.Eventf(chart, core.EventTypeNormal, "ReleaseOrphaned", "Removed previous release %s, keeping its resources", previous)
+		return c.relocated(chartCopy)
+	}
+
+	objs, err := render.Objects(previousChart, config, c.renderOptions())
+	if err != nil {
+		return chart, err
+	}
+	jobName := render.JobName(previousChart)
+	if err := c.apply.WithOwner(chart).Apply(objs); err != nil {
+		return chart, err
+	}
+
+	chartCopy.Status.JobName = jobName
+	if job, err := c.jobsCache.Get(chart.Namespace, jobName); err == nil && job.Status.Succeeded > 0 && jobMatches(job, renderedJob(objs)) {
+		c.recorder.Eventf(chart, core.EventTypeNormal, "ReleaseUninstalled", "Uninstalled previous release %s using Job %s/%s", previous, chart.Namespace, jobName)
+		return c.relocated(chartCopy)
+	}
+
+	ConditionRelocating.True(chartCopy)
+	ConditionRelocating.Reason(chartCopy, "UninstallingPreviousRelease")
+	ConditionRelocating.Message(chartCopy, fmt.Sprintf("uninstalling previous release %s", previous))
+	ConditionReady.False(chartCopy)
+	ConditionReady.Reason(chartCopy, "MigratingRelease")
+	ConditionReady.Message(chartCopy, fmt.Sprintf("uninstalling previous release %s", previous))
+	return c.updateStatus(chartCopy)
+}
+
+// relocated records that the chart's previous release has been removed, so that the release is installed
+// under the chart's current release name and target namespace.
+func (c *Controller) relocated(chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+	chart.Status.TargetNamespace = render.TargetNamespace(chart)
+	chart.Status.ReleaseName = render.ReleaseName(chart)
+	ConditionRelocating.True(chart)
+	ConditionRelocating.Reason(chart, "InstallingRelease")
+	ConditionRelocating.Message(chart, fmt.Sprintf("installing release %s/%s", chart.Status.TargetNamespace, chart.Status.ReleaseName))
+	return c.updateStatus(chart)
+}
+
+// setRelocatedCondition completes the Relocating condition once the relocated release has been installed.
+func setRelocatedCondition(chart *helmv1.HelmChart) {
+	if !ConditionRelocating.IsTrue(chart) || !ConditionReady.IsTrue(chart) {
+		return
+	}
+	ConditionRelocating.False(chart)
+	ConditionRelocating.Reason(chart, "Relocated")
+	ConditionRelocating.Message(chart, fmt.Sprintf("release relocated to %s/%s", chart.Status.TargetNamespace, chart.Status.ReleaseName))
+}
+
+// deleteReleaseStorage removes the Secrets that helm stores the history of a release in, so that helm no
+// longer tracks the release without uninstalling any of the resources that it created.
+func (c *Controller) deleteReleaseStorage(namespace, name string) error {
+	selector := labels.SelectorFromSet(labels.Set{"owner": "helm", "name": name})
+	secrets, err := c.secretController.List(namespace, meta.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if err := c.secretController.Delete(namespace, secret.Name, &meta.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
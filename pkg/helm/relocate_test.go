@@ -0,0 +1,177 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// relocatedChart returns a chart that was installed into kube-system, and has since been moved to traefik.
+func relocatedChart(policy string, keepResources bool) *v1.HelmChart {
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{
+		Chart:                   "stable/traefik",
+		TargetNamespace:         "traefik",
+		TargetNamespacePolicy:   policy,
+		KeepResourcesOnRelocate: keepResources,
+	}})
+	chart.Status.TargetNamespace = "kube-system"
+	chart.Status.ReleaseName = "traefik"
+	return chart
+}
+
+// relocateController returns a controller that holds the release secrets of the chart's previous release, in
+// kube-system, and of an unrelated release in traefik.
+func relocateController() (*Controller, *helmController, *secretController, *applier) {
+	release := func(namespace, name string) core.Secret {
+		return core.Secret{ObjectMeta: meta.ObjectMeta{
+			Namespace: namespace,
+			Name:      "sh.helm.release.v1." + name + ".v1",
+			Labels:    map[string]string{"owner": "helm", "name": name, "version": "1"},
+		}}
+	}
+	helms := &helmController{}
+	secrets := &secretController{secrets: []core.Secret{release("kube-system", "traefik"), release("traefik", "other")}}
+	applies := &applier{}
+	c := &Controller{
+		helmController:   helms,
+		secretController: secrets,
+		secretCache:      &secretCache{},
+		configMapCache:   &configMapCache{},
+		jobsCache:        &jobCache{jobs: map[string]*batch.Job{}},
+		apply:            applies,
+		recorder:         record.NewFakeRecorder(10),
+	}
+	return c, helms, secrets, applies
+}
+
+func TestRelocateReleaseUnchanged(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, secrets, applies := relocateController()
+
+	chart := relocatedChart(TargetNamespacePolicyMigrate, true)
+	chart.Status.TargetNamespace = ""
+	_, install, err := c.relocateRelease(chart, nil)
+	assert.NoError(err)
+	assert.True(install)
+
+	chart = relocatedChart(TargetNamespacePolicyMigrate, true)
+	chart.Spec.TargetNamespace = "kube-system"
+	_, install, err = c.relocateRelease(chart, nil)
+	assert.NoError(err)
+	assert.True(install)
+
+	assert.Empty(helms.updated)
+	assert.Empty(secrets.deleted)
+	assert.Empty(applies.applied)
+}
+
+func TestRelocateReleaseIgnore(t *testing.T) {
+	for _, keepResources := range []bool{false, true} {
+		assert := assert.New(t)
+		c, helms, secrets, applies := relocateController()
+
+		chart := relocatedChart(TargetNamespacePolicyIgnore, keepResources)
+		updated, install, err := c.relocateRelease(chart, nil)
+		assert.NoError(err)
+		assert.True(install)
+		assert.Same(chart, updated)
+		assert.Empty(helms.updated)
+		assert.Empty(secrets.deleted, "keepResourcesOnRelocate %t", keepResources)
+		assert.Empty(applies.applied)
+	}
+}
+
+func TestRelocateReleaseReject(t *testing.T) {
+	for _, keepResources := range []bool{false, true} {
+		assert := assert.New(t)
+		c, helms, secrets, applies := relocateController()
+		recorder := c.recorder.(*record.FakeRecorder)
+
+		chart := relocatedChart(TargetNamespacePolicyReject, keepResources)
+		updated, install, err := c.relocateRelease(chart, nil)
+		assert.NoError(err)
+		assert.False(install)
+		assert.True(ConditionReady.IsFalse(updated))
+		assert.Equal("TargetNamespaceChangeRejected", ConditionReady.GetReason(updated))
+		assert.Equal("release cannot be moved from kube-system/traefik to traefik/traefik", ConditionReady.GetMessage(updated))
+		assert.Equal("kube-system", updated.Status.TargetNamespace)
+		assert.Len(recorder.Events, 1)
+
+		// the change is only reported once
+		_, install, err = c.relocateRelease(updated, nil)
+		assert.NoError(err)
+		assert.False(install)
+		assert.Len(recorder.Events, 1)
+
+		assert.Len(helms.updated, 2)
+		assert.Empty(secrets.deleted, "keepResourcesOnRelocate %t", keepResources)
+		assert.Empty(applies.applied)
+	}
+}
+
+func TestRelocateReleaseMigrateKeepResources(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, secrets, applies := relocateController()
+
+	chart := relocatedChart(TargetNamespacePolicyMigrate, true)
+	updated, install, err := c.relocateRelease(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+
+	// only the release storage of the previous release is removed, without running the delete job
+	assert.Equal([]string{"kube-system/sh.helm.release.v1.traefik.v1"}, secrets.deleted)
+	assert.Empty(applies.applied)
+	assert.Len(helms.updated, 1)
+	assert.Equal("traefik", updated.Status.TargetNamespace)
+	assert.Equal("traefik", updated.Status.ReleaseName)
+	assert.True(ConditionRelocating.IsTrue(updated))
+	assert.Equal("InstallingRelease", ConditionRelocating.GetReason(updated))
+
+	// the release is installed into its new target namespace once the status has been updated
+	_, install, err = c.relocateRelease(updated, nil)
+	assert.NoError(err)
+	assert.True(install)
+}
+
+func TestRelocateReleaseMigrate(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, secrets, applies := relocateController()
+
+	chart := relocatedChart(TargetNamespacePolicyMigrate, false)
+	updated, install, err := c.relocateRelease(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+
+	// the previous release is uninstalled by the delete job, which removes its release storage itself
+	assert.Empty(secrets.deleted)
+	assert.Len(applies.applied, 1)
+	assert.Same(chart, applies.owners[0])
+	job := renderedJob(applies.applied[0])
+	assert.Equal("helm-delete-traefik", job.Name)
+	assert.Equal(job.Name, updated.Status.JobName)
+	assert.Equal("kube-system", updated.Status.TargetNamespace)
+	assert.True(ConditionRelocating.IsTrue(updated))
+	assert.Equal("UninstallingPreviousRelease", ConditionRelocating.GetReason(updated))
+	assert.Equal("MigratingRelease", ConditionReady.GetReason(updated))
+
+	// once the delete job has succeeded, the release is installed into its new target namespace
+	succeeded := job.DeepCopy()
+	succeeded.Status.Succeeded = 1
+	c.jobsCache.(*jobCache).jobs["kube-system/"+succeeded.Name] = succeeded
+	updated, install, err = c.relocateRelease(updated, nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Empty(secrets.deleted)
+	assert.Equal("traefik", updated.Status.TargetNamespace)
+	assert.Equal("InstallingRelease", ConditionRelocating.GetReason(updated))
+	assert.Len(helms.updated, 2)
+
+	_, install, err = c.relocateRelease(updated, nil)
+	assert.NoError(err)
+	assert.True(install)
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// serviceAccountTokenPath is where the token of the job's ServiceAccount is mounted
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// IsArtifactURL returns true if the repo is the URL of a packaged chart, such as a chart artifact served by the
+// Flux source-controller, instead of the URL of a chart repository.
+func IsArtifactURL(repo string) bool {
+	u, err := url.Parse(repo)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && strings.HasSuffix(u.Path, ".tgz")
+}
+
+// setRepoArtifact downloads the chart from the artifact URL in the chart's Repo with an init container, and
+// passes it to the job as chart content, so that charts already fetched by another source, such as Flux, are
+// installed without helm going back to the upstream repository. If RepoServiceAccountAuth is set, the request
+// is authenticated with the token of the job's ServiceAccount, and if ChartChecksum is set, the artifact is verified
+// against it. Charts with ChartContent do not use the Repo.
+func setRepoArtifact(job *batch.Job, chart *helmv1.HelmChart) {
+	if !IsArtifactURL(chart.Spec.Repo) || chart.DeletionTimestamp != nil || chart.Spec.ChartContent != "" || chart.Spec.ChartContentSecret != nil {
+		return
+	}
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	var auth string
+	if chart.Spec.RepoServiceAccountAuth {
+		auth = fmt.Sprintf(`--header "Authorization: Bearer $(cat %s)" `, serviceAccountTokenPath)
+	}
+	job.Spec.Template.Spec.InitContainers = append(job.Spec.Template.Spec.InitContainers, core.Container{
+		Name:            "artifact",
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         []string{"sh", "-c", fmt.Sprintf(`set -e; wget -q -O "/chart/${NAME}.tgz" %s"${ARTIFACT_URL}"; %s`, auth, packageScript(chart))},
+		Env: []core.EnvVar{
+			{
+				Name:  "NAME",
+				Value: ReleaseName(chart),
+			},
+			{
+				Name:  "ARTIFACT_URL",
+				Value: chart.Spec.Repo,
+			},
+		},
+		VolumeMounts: []core.VolumeMount{
+			{
+				MountPath: "/chart",
+				Name:      "content",
+			},
+		},
+	})
+	setFetchedContent(job)
+}
+
+// packageScript verifies the chart archive fetched to /chart against the chart's ChartChecksum, if set, and
+// then encodes it as the chart content file that the job installs charts from.
+func packageScript(chart *helmv1.HelmChart) string {
+	var verify string
+	if chart.Spec.ChartChecksum != "" {
+		verify = fmt.Sprintf(`echo "%s  /chart/${NAME}.tgz" | sha256sum -c -; `, strings.TrimPrefix(chart.Spec.ChartChecksum, "sha256:"))
+	}
+	return verify + `base64 "/chart/${NAME}.tgz" > "/chart/${NAME}.tgz.base64"; rm "/chart/${NAME}.tgz"`
+}
+
+// setFetchedContent shares the content volume that charts are fetched into by init containers with the job, and
+// clears the job's repo, so that the job installs the fetched chart instead of fetching it from the repo itself.
+func setFetchedContent(job *batch.Job) {
+	container := &job.Spec.Template.Spec.Containers[0]
+	for i := range container.Env {
+		if container.Env[i].Name == "REPO" {
+			container.Env[i].Value = ""
+		}
+	}
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name:         "content",
+		VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+		MountPath: "/chart",
+		Name:      "content",
+	})
+}
@@ -0,0 +1,42 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestIsArtifactURL(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsArtifactURL("http://source-controller.flux-system.svc/helmchart/flux-system/traefik/traefik-10.0.0.tgz"))
+	assert.False(IsArtifactURL("https://helm.traefik.io/traefik"))
+	assert.False(IsArtifactURL("oci://registry.example.com/charts/traefik.tgz"))
+	assert.False(IsArtifactURL(""))
+}
+
+func TestRepoArtifact(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Repo = "http://source-controller.flux-system.svc/helmchart/flux-system/traefik/traefik-10.0.0.tgz"
+
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	container := installJob.Spec.Template.Spec.Containers[0]
+	assert.NotContains(container.Args, "--repo")
+	assert.Contains(container.Env, core.EnvVar{Name: "REPO", Value: ""})
+	assert.Contains(container.VolumeMounts, core.VolumeMount{Name: "content", MountPath: "/chart"})
+	assert.NotNil(jobVolume(installJob, "content").EmptyDir)
+	assert.Len(installJob.Spec.Template.Spec.InitContainers, 1)
+	initContainer := installJob.Spec.Template.Spec.InitContainers[0]
+	assert.Contains(initContainer.Env, core.EnvVar{Name: "ARTIFACT_URL", Value: chart.Spec.Repo})
+	assert.NotContains(initContainer.Command[2], "Authorization")
+
+	chart.Spec.RepoServiceAccountAuth = true
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Contains(installJob.Spec.Template.Spec.InitContainers[0].Command[2], serviceAccountTokenPath)
+
+	chart.Spec.Repo = "https://helm.traefik.io/traefik"
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Contains(installJob.Spec.Template.Spec.Containers[0].Args, "--repo")
+	assert.Empty(installJob.Spec.Template.Spec.InitContainers)
+}
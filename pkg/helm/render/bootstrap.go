@@ -0,0 +1,45 @@
+package render
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// apiserverWaitScript waits for the apiserver to accept connections, backing off from one second up to 32 seconds
+// between attempts, and fails once it has waited for apiserverWaitTimeout seconds, so that a job on a node whose
+// apiserver never comes up fails and is retried instead of waiting forever.
+const apiserverWaitScript = `delay=1; waited=0; until nc -z "${KUBERNETES_SERVICE_HOST}" "${KUBERNETES_SERVICE_PORT}"; do ` +
+	`if [ "${waited}" -ge ` + apiserverWaitTimeout + ` ]; then ` +
+	`echo "Timed out waiting for apiserver at ${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}"; exit 1; fi; ` +
+	`echo "Waiting for apiserver at ${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}"; sleep "${delay}"; ` +
+	`waited=$((waited + delay)); if [ "${delay}" -lt 32 ]; then delay=$((delay * 2)); fi; done`
+
+// apiserverWaitTimeout is the number of seconds that the wait-for-apiserver init container waits for
+const apiserverWaitTimeout = "600"
+
+// setBootstrapWait runs an init container ahead of any others in bootstrap jobs that waits for the local apiserver
+// to accept connections. Bootstrap jobs use the host network to reach the apiserver on the node they run on, and
+// may start before it does; without the wait, helm fails to connect and each early failure counts against the
+// job's backoff limit. The wait is skipped for charts with a TargetContext, as they do not use the local apiserver.
+func setBootstrapWait(job *batch.Job, chart *helmv1.HelmChart) {
+	if !chart.Spec.Bootstrap || chart.Spec.TargetContext != "" {
+		return
+	}
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	var env []core.EnvVar
+	for _, e := range container.Env {
+		if e.Name == "KUBERNETES_SERVICE_HOST" || e.Name == "KUBERNETES_SERVICE_PORT" {
+			env = append(env, e)
+		}
+	}
+	wait := core.Container{
+		Name:            "wait-for-apiserver",
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		Command:         []string{"sh", "-c", apiserverWaitScript},
+		Env:             env,
+	}
+	job.Spec.Template.Spec.InitContainers = append([]core.Container{wait}, job.Spec.Template.Spec.InitContainers...)
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestBootstrapWait(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.Empty(installJob.Spec.Template.Spec.InitContainers)
+
+	chart.Spec.Bootstrap = true
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	if assert.Len(installJob.Spec.Template.Spec.InitContainers, 1) {
+		wait := installJob.Spec.Template.Spec.InitContainers[0]
+		assert.Equal("wait-for-apiserver", wait.Name)
+		assert.Equal(DefaultJobImage, wait.Image)
+		assert.Equal([]core.EnvVar{
+			{Name: "KUBERNETES_SERVICE_HOST", Value: "127.0.0.1"},
+			{Name: "KUBERNETES_SERVICE_PORT", Value: "6443"},
+		}, wait.Env)
+		assert.Contains(wait.Command[2], `nc -z "${KUBERNETES_SERVICE_HOST}" "${KUBERNETES_SERVICE_PORT}"`)
+		assert.Contains(wait.Command[2], `if [ "${waited}" -ge 600 ]; then`)
+		assert.Equal(installJob.Spec.Template.Spec.Containers[0].SecurityContext, wait.SecurityContext)
+		if assert.NotNil(wait.SecurityContext) {
+			assert.Equal(true, *wait.SecurityContext.ReadOnlyRootFilesystem)
+		}
+	}
+
+	chart.Spec.TargetContext = "downstream"
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Empty(installJob.Spec.Template.Spec.InitContainers)
+}
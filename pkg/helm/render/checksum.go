@@ -0,0 +1,64 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// ValidateChartChecksum checks that the chart's ChartChecksum is a sha256 checksum, and that it matches the
+// chart's ChartContent, if the chart has any. Charts fetched by the job are checked by the job before install.
+func ValidateChartChecksum(chart *helmv1.HelmChart) error {
+	if chart.Spec.ChartChecksum == "" {
+		return nil
+	}
+	if !sha256Checksum.MatchString(chart.Spec.ChartChecksum) {
+		return fmt.Errorf("spec.chartChecksum must be in sha256:<hex digest> format, not %q", chart.Spec.ChartChecksum)
+	}
+	if chart.Spec.ChartContent == "" {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(chart.Spec.ChartContent), ""))
+	if err != nil {
+		return fmt.Errorf("spec.chartContent is not valid base64: %w", err)
+	}
+	if checksum := fmt.Sprintf("sha256:%x", sha256.Sum256(decoded)); checksum != chart.Spec.ChartChecksum {
+		return fmt.Errorf("spec.chartContent has checksum %s, which does not match spec.chartChecksum %s", checksum, chart.Spec.ChartChecksum)
+	}
+	return nil
+}
+
+// ChartArchiveDigest returns the sha256 digest of the chart archive, in the same format as ChartChecksum. This is
+// the ChartChecksum if set, as the job fails to install archives that do not match it, or the digest of the
+// ChartContent otherwise. Archives that are fetched by the job, or read from a ChartContentSecret, without a
+// ChartChecksum have no known digest.
+func ChartArchiveDigest(chart *helmv1.HelmChart) string {
+	if chart.Spec.ChartChecksum != "" {
+		return chart.Spec.ChartChecksum
+	}
+	if ChartSource(chart) != ChartSourceContent {
+		return ""
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(chart.Spec.ChartContent), ""))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(decoded))
+}
+
+// setChartChecksum passes the chart's ChartChecksum to the job, which verifies the chart archive against it
+// before installing it, and fails without installing the chart if they do not match.
+func setChartChecksum(job *batch.Job, chart *helmv1.HelmChart) {
+	if chart.Spec.ChartChecksum == "" || chart.DeletionTimestamp != nil {
+		return
+	}
+	job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
+		Name:  "CHART_CHECKSUM",
+		Value: strings.TrimPrefix(chart.Spec.ChartChecksum, "sha256:"),
+	})
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestValidateChartChecksum(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidateChartChecksum(chart))
+
+	chart.Spec.ChartChecksum = "md5:1234"
+	assert.Error(ValidateChartChecksum(chart))
+
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00}
+	chart.Spec.ChartContent = base64.StdEncoding.EncodeToString(archive)
+	chart.Spec.ChartChecksum = fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+	assert.NoError(ValidateChartChecksum(chart))
+
+	chart.Spec.ChartChecksum = pluginChecksum
+	assert.Error(ValidateChartChecksum(chart))
+}
+
+func TestChartChecksum(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.ChartChecksum = pluginChecksum
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.Contains(installJob.Spec.Template.Spec.Containers[0].Env, core.EnvVar{Name: "CHART_CHECKSUM", Value: pluginChecksum[len("sha256:"):]})
+
+	chart.Spec.Repo = "https://example.com/charts/traefik-10.0.0.tgz"
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Contains(installJob.Spec.Template.Spec.InitContainers[0].Command[2], "sha256sum -c -")
+}
+
+func TestChartArchiveDigest(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.Empty(ChartArchiveDigest(chart))
+
+	archive := []byte{0x1f, 0x8b, 0x08, 0x00}
+	chart.Spec.ChartContent = base64.StdEncoding.EncodeToString(archive)
+	assert.Equal(fmt.Sprintf("sha256:%x", sha256.Sum256(archive)), ChartArchiveDigest(chart))
+
+	chart.Spec.ChartChecksum = pluginChecksum
+	assert.Equal(pluginChecksum, ChartArchiveDigest(chart))
+}
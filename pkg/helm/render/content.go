@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+)
+
+const (
+	// maxConfigMapSize is the largest amount of data that the apiserver accepts in a single ConfigMap
+	maxConfigMapSize = 1024 * 1024
+	// maxChartContentSize leaves room for the key within the ConfigMap size limit
+	maxChartContentSize = maxConfigMapSize - 1024
+)
+
+// ValidateChartContent checks that the chart's ChartContent is a base64-encoded chart archive, and that it is
+// small enough to be stored in the content ConfigMap, so that charts that cannot be installed are rejected up
+// front instead of failing when their ConfigMaps are applied. Content that is unchanged from the old chart, when
+// one is given, is not checked, so that existing charts can still be updated.
+func ValidateChartContent(chart, oldChart *helmv1.HelmChart) error {
+	if chart.Spec.ChartContent == "" || (oldChart != nil && oldChart.Spec.ChartContent == chart.Spec.ChartContent) {
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(chart.Spec.ChartContent), ""))
+	if err != nil {
+		return fmt.Errorf("spec.chartContent is not valid base64: %w", err)
+	}
+	if !bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		return fmt.Errorf("spec.chartContent must be a base64-encoded chart archive (.tgz)")
+	}
+	return ValidateChartContentSize(chart)
+}
+
+// ValidateChartContentSize checks that the chart's ChartContent is small enough to be stored in the content
+// ConfigMap. The content is not compressed or split to fit, as chart archives are already gzip-compressed, and
+// the HelmChart that holds the content is itself subject to the apiserver's request size limit.
+func ValidateChartContentSize(chart *helmv1.HelmChart) error {
+	if size := len(chart.Spec.ChartContent); size > maxChartContentSize {
+		return fmt.Errorf("spec.chartContent is too large: %d bytes, but at most %d bytes can be stored in a ConfigMap; install larger charts from a repo, or from the URL of the chart archive in spec.repo", size, maxChartContentSize)
+	}
+	return nil
+}
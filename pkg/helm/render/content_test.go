@@ -0,0 +1,34 @@
+package render
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateChartContent(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidateChartContent(chart, nil))
+
+	chart.Spec.ChartContent = "H4sIAAAAAAAA/ypJLS4BBAAA//8AAAAAAAAAAA=="
+	assert.NoError(ValidateChartContent(chart, nil))
+
+	chart.Spec.ChartContent = "not base64!"
+	assert.Error(ValidateChartContent(chart, nil))
+
+	chart.Spec.ChartContent = base64.StdEncoding.EncodeToString([]byte("apiVersion: v2"))
+	assert.EqualError(ValidateChartContent(chart, nil), "spec.chartContent must be a base64-encoded chart archive (.tgz)")
+
+	// unchanged content of an existing chart is let through
+	oldChart := chart.DeepCopy()
+	assert.NoError(ValidateChartContent(chart, oldChart))
+	oldChart.Spec.ChartContent = "H4sIAAAAAAAA/ypJLS4BBAAA//8AAAAAAAAAAA=="
+	assert.Error(ValidateChartContent(chart, oldChart))
+
+	chart.Spec.ChartContent = "H4sI" + strings.Repeat("A", maxChartContentSize)
+	assert.EqualError(ValidateChartContent(chart, nil), "spec.chartContent is too large: 1047556 bytes, but at most 1047552 bytes can be stored in a ConfigMap; install larger charts from a repo, or from the URL of the chart archive in spec.repo")
+	assert.Error(ValidateChartContentSize(chart))
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// fetched returns true if the chart is fetched by the chart's ChartFetcher, instead of by the job itself.
+func fetched(chart *helmv1.HelmChart) bool {
+	return chart.Spec.ChartFetcher != nil && chart.Spec.ChartFetcher.Image != "" && chart.DeletionTimestamp == nil &&
+		chart.Spec.ChartContent == "" && chart.Spec.ChartContentSecret == nil && !IsArtifactURL(chart.Spec.Repo)
+}
+
+// setChartFetcher splits fetching the chart from installing it, for charts with a ChartFetcher. The fetcher runs
+// as an init container with its own image, so that custom fetchers, such as registry CLIs or signature verifiers,
+// replace only the fetch stage. It is passed the chart's NAME, CHART, VERSION and REPO, and must write the chart
+// archive to /chart/${NAME}.tgz. A second init container, using the job image, verifies the archive against the
+// chart's ChartChecksum and passes it to the job as chart content.
+func setChartFetcher(job *batch.Job, chart *helmv1.HelmChart) {
+	if !fetched(chart) {
+		return
+	}
+
+	fetcher := chart.Spec.ChartFetcher
+	container := &job.Spec.Template.Spec.Containers[0]
+	volumeMounts := []core.VolumeMount{
+		{
+			MountPath: "/chart",
+			Name:      "content",
+		},
+	}
+	env := []core.EnvVar{
+		{
+			Name:  "NAME",
+			Value: ReleaseName(chart),
+		},
+		{
+			Name:  "CHART",
+			Value: chart.Spec.Chart,
+		},
+		{
+			Name:  "VERSION",
+			Value: chart.Spec.Version,
+		},
+		{
+			Name:  "REPO",
+			Value: chart.Spec.Repo,
+		},
+	}
+
+	job.Spec.Template.Spec.InitContainers = append(job.Spec.Template.Spec.InitContainers,
+		core.Container{
+			Name:            "fetch",
+			Image:           fetcher.Image,
+			ImagePullPolicy: core.PullIfNotPresent,
+			Command:         fetcher.Command,
+			Args:            fetcher.Args,
+			EnvFrom:         chart.Spec.JobEnvFrom,
+			Env:             append(env, fetcher.Env...),
+			VolumeMounts:    volumeMounts,
+		},
+		core.Container{
+			Name:            "package",
+			Image:           container.Image,
+			ImagePullPolicy: container.ImagePullPolicy,
+			Command:         []string{"sh", "-c", "set -e; " + packageScript(chart)},
+			Env:             env[:1],
+			VolumeMounts:    volumeMounts,
+		})
+	setFetchedContent(job)
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestChartFetcher(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Repo = "https://charts.example.com"
+	chart.Spec.ChartChecksum = pluginChecksum
+	chart.Spec.ChartFetcher = &v1.HelmChartFetcher{
+		Image:   "registry.example.com/fetcher:v1",
+		Command: []string{"fetch-chart"},
+		Env:     []core.EnvVar{{Name: "VERIFY_SIGNATURE", Value: "true"}},
+	}
+
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	container := installJob.Spec.Template.Spec.Containers[0]
+	assert.NotContains(container.Args, "--repo")
+	assert.Contains(container.Env, core.EnvVar{Name: "REPO", Value: ""})
+	assert.NotNil(jobVolume(installJob, "content").EmptyDir)
+
+	initContainers := installJob.Spec.Template.Spec.InitContainers
+	assert.Len(initContainers, 2)
+	assert.Equal("registry.example.com/fetcher:v1", initContainers[0].Image)
+	assert.Equal([]string{"fetch-chart"}, initContainers[0].Command)
+	assert.Contains(initContainers[0].Env, core.EnvVar{Name: "REPO", Value: chart.Spec.Repo})
+	assert.Contains(initContainers[0].Env, core.EnvVar{Name: "VERIFY_SIGNATURE", Value: "true"})
+	assert.Equal(DefaultJobImage, initContainers[1].Image)
+	assert.Contains(initContainers[1].Command[2], "sha256sum -c -")
+
+	chart.Spec.ChartContent = "H4sIAAAAAAAA"
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Empty(installJob.Spec.Template.Spec.InitContainers)
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+)
+
+// fieldRefRE matches the downward API field paths supported by SetFieldRefs, capturing the map and key of
+// label and annotation references.
+var fieldRefRE = regexp.MustCompile(`^metadata\.(labels|annotations)\['([^']*)'\]$`)
+
+// ValidateSetFieldRefs checks that the keys of the chart's SetFieldRefs will be parsed by helm as written, and
+// that each value is a supported field path: metadata.name, metadata.namespace, metadata.uid,
+// metadata.labels['<key>'] or metadata.annotations['<key>'].
+func ValidateSetFieldRefs(chart *helmv1.HelmChart) error {
+	for _, k := range jsonKeys(chart.Spec.SetFieldRefs) {
+		if err := validateSetKey(k); err != nil {
+			return fmt.Errorf("spec.setFieldRefs key %q is invalid: %w", k, err)
+		}
+		if _, err := fieldRefValue(chart, chart.Spec.SetFieldRefs[k]); err != nil {
+			return fmt.Errorf("spec.setFieldRefs value for key %q is invalid: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// fieldRefArgs returns --set-string args setting each of the chart's SetFieldRefs keys to the value of the
+// referenced field of the chart itself. Labels and annotations that are not set are passed as empty strings,
+// matching the downward API. Invalid field paths are skipped; they are reported by ValidateSetFieldRefs.
+func fieldRefArgs(chart *helmv1.HelmChart) []string {
+	var args []string
+	for _, k := range jsonKeys(chart.Spec.SetFieldRefs) {
+		val, err := fieldRefValue(chart, chart.Spec.SetFieldRefs[k])
+		if err != nil {
+			continue
+		}
+		args = append(args, "--set-string", fmt.Sprintf("%s=%s", k, commaRE.ReplaceAllStringFunc(val, escapeComma)))
+	}
+	return args
+}
+
+func fieldRefValue(chart *helmv1.HelmChart, fieldPath string) (string, error) {
+	switch fieldPath {
+	case "metadata.name":
+		return chart.Name, nil
+	case "metadata.namespace":
+		return chart.Namespace, nil
+	case "metadata.uid":
+		return string(chart.UID), nil
+	}
+	match := fieldRefRE.FindStringSubmatch(fieldPath)
+	if match == nil {
+		return "", fmt.Errorf("unsupported field path %q", fieldPath)
+	}
+	if match[2] == "" {
+		return "", fmt.Errorf("field path %q must include a key", fieldPath)
+	}
+	if match[1] == "labels" {
+		return chart.Labels[match[2]], nil
+	}
+	return chart.Annotations[match[2]], nil
+}
@@ -0,0 +1,42 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFieldRefs(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Set = nil
+	chart.Labels = map[string]string{"app.kubernetes.io/instance": "edge"}
+	chart.Annotations = map[string]string{"example.com/regions": "us-east,us-west"}
+	chart.Spec.SetFieldRefs = map[string]string{
+		"global.chartName":      "metadata.name",
+		"global.chartNamespace": "metadata.namespace",
+		"global.instance":       "metadata.labels['app.kubernetes.io/instance']",
+		"global.regions":        "metadata.annotations['example.com/regions']",
+		"global.tier":           "metadata.labels['tier']",
+	}
+	assert.NoError(ValidateSetFieldRefs(chart))
+	assert.Equal([]string{
+		"install",
+		"--set-string", "global.chartName=traefik",
+		"--set-string", "global.chartNamespace=kube-system",
+		"--set-string", "global.instance=edge",
+		"--set-string", `global.regions=us-east\,us-west`,
+		"--set-string", "global.tier=",
+	}, args(chart))
+
+	invalid := map[string]string{
+		"global.chartName": "spec.chart",
+		"global.instance":  "metadata.labels[]",
+		"global.tier":      "metadata.labels['']",
+		"global,name":      "metadata.name",
+	}
+	for k, v := range invalid {
+		chart.Spec.SetFieldRefs = map[string]string{k: v}
+		assert.Error(ValidateSetFieldRefs(chart), "%s: %s", k, v)
+	}
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGolden renders the objects for each chart fixture in testdata/*/chart.yaml, and compares them
+// against the golden files alongside it. Run with -update to regenerate the golden files.
+func TestGolden(t *testing.T) {
+	for _, name := range []string{"all_proxy", "ALL_PROXY", "http_proxy", "HTTP_PROXY", "https_proxy", "HTTPS_PROXY", "no_proxy", "NO_PROXY"} {
+		if value, ok := os.LookupEnv(name); ok {
+			os.Unsetenv(name)
+			defer os.Setenv(name, value)
+		}
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*", "chart.yaml"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		dir := filepath.Dir(fixture)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			assert := assert.New(t)
+			data, err := ioutil.ReadFile(fixture)
+			assert.NoError(err)
+			chart := &v1.HelmChart{}
+			assert.NoError(yaml.Unmarshal(data, chart))
+
+			installJob, valuesConfigMap, contentConfigMap := job(chart, Options{JobImage: DefaultJobImage})
+			objects := map[string]runtime.Object{
+				"job.yaml":              installJob,
+				"values-configmap.yaml": valuesConfigMap,
+				"rolebinding.yaml":      roleBinding(chart),
+				"serviceaccount.yaml":   serviceAccount(chart),
+			}
+			if contentConfigMap != nil {
+				objects["content-configmap.yaml"] = contentConfigMap
+			}
+
+			for file, obj := range objects {
+				actual, err := yaml.Marshal(obj)
+				assert.NoError(err)
+				golden := filepath.Join(dir, file)
+				if *update {
+					assert.NoError(ioutil.WriteFile(golden, actual, 0644))
+					continue
+				}
+				expected, err := ioutil.ReadFile(golden)
+				assert.NoError(err)
+				assert.Equal(string(expected), string(actual), "%s does not match; run make golden to update", golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NameTemplates are the text/template templates used to name the objects generated for charts. Templates are
+// executed with the chart's .Namespace and .Name, and for jobs the .Action, which is either install or delete.
+type NameTemplates struct {
+	Job                 string
+	ServiceAccount      string
+	ClusterRoleBinding  string
+	ValuesConfigMap     string
+	ContentConfigMap    string
+	ProvenanceConfigMap string
+	NetworkPolicy       string
+}
+
+// DefaultNameTemplates are used for any name templates that are not set.
+var DefaultNameTemplates = NameTemplates{
+	Job:                 "helm-{{.Action}}-{{.Name}}",
+	ServiceAccount:      "helm-{{.Name}}",
+	ClusterRoleBinding:  "helm-{{.Namespace}}-{{.Name}}",
+	ValuesConfigMap:     "chart-values-{{.Name}}",
+	ContentConfigMap:    "chart-content-{{.Name}}",
+	ProvenanceConfigMap: "chart-provenance-{{.Name}}",
+	NetworkPolicy:       "helm-{{.Name}}",
+}
+
+var names = mustParseNameTemplates(DefaultNameTemplates)
+
+type nameTemplates struct {
+	job                 *template.Template
+	serviceAccount      *template.Template
+	clusterRoleBinding  *template.Template
+	valuesConfigMap     *template.Template
+	contentConfigMap    *template.Template
+	provenanceConfigMap *template.Template
+	networkPolicy       *template.Template
+}
+
+type nameData struct {
+	Action    string
+	Namespace string
+	Name      string
+}
+
+// SetNameTemplates replaces the templates used to name generated objects, so that distributions embedding the
+// controller can follow their own naming conventions, and multiple controllers can coexist without their objects
+// colliding. Templates that are not set keep their default. An error is returned, and no templates are changed,
+// if any template fails to parse, does not produce a valid name, or does not produce unique names for each chart.
+func SetNameTemplates(templates NameTemplates) error {
+	parsed, err := parseNameTemplates(templates)
+	if err != nil {
+		return err
+	}
+	names = parsed
+	return nil
+}
+
+func mustParseNameTemplates(templates NameTemplates) nameTemplates {
+	parsed, err := parseNameTemplates(templates)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func parseNameTemplates(templates NameTemplates) (nameTemplates, error) {
+	var parsed nameTemplates
+	for _, t := range []struct {
+		name     string
+		text     string
+		fallback string
+		dest     **template.Template
+		// unique lists the fields that must each produce a different name when changed
+		unique []string
+	}{
+		{"job", templates.Job, DefaultNameTemplates.Job, &parsed.job, []string{"Action", "Name"}},
+		{"service account", templates.ServiceAccount, DefaultNameTemplates.ServiceAccount, &parsed.serviceAccount, []string{"Name"}},
+		{"cluster role binding", templates.ClusterRoleBinding, DefaultNameTemplates.ClusterRoleBinding, &parsed.clusterRoleBinding, []string{"Namespace", "Name"}},
+		{"values config map", templates.ValuesConfigMap, DefaultNameTemplates.ValuesConfigMap, &parsed.valuesConfigMap, []string{"Name"}},
+		{"content config map", templates.ContentConfigMap, DefaultNameTemplates.ContentConfigMap, &parsed.contentConfigMap, []string{"Name"}},
+		{"provenance config map", templates.ProvenanceConfigMap, DefaultNameTemplates.ProvenanceConfigMap, &parsed.provenanceConfigMap, []string{"Name"}},
+		{"network policy", templates.NetworkPolicy, DefaultNameTemplates.NetworkPolicy, &parsed.networkPolicy, []string{"Name"}},
+	} {
+		text := t.text
+		if text == "" {
+			text = t.fallback
+		}
+		tmpl, err := template.New(t.name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return parsed, fmt.Errorf("invalid %s name template %q: %w", t.name, text, err)
+		}
+		if err := checkNameTemplate(tmpl, t.unique); err != nil {
+			return parsed, fmt.Errorf("invalid %s name template %q: %w", t.name, text, err)
+		}
+		*t.dest = tmpl
+	}
+	return parsed, nil
+}
+
+// checkNameTemplate executes the template with sample data, to check that it produces valid names, and that
+// the names change along with each of the given fields.
+func checkNameTemplate(tmpl *template.Template, unique []string) error {
+	sample := nameData{Action: "install", Namespace: "kube-system", Name: "traefik"}
+	name, err := executeName(tmpl, sample)
+	if err != nil {
+		return err
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("produces invalid name %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	for _, field := range unique {
+		other := sample
+		switch field {
+		case "Action":
+			other.Action = "delete"
+		case "Namespace":
+			other.Namespace = "default"
+		case "Name":
+			other.Name = "coredns"
+		}
+		otherName, err := executeName(tmpl, other)
+		if err != nil {
+			return err
+		}
+		if otherName == name {
+			return fmt.Errorf("must include {{.%s}}", field)
+		}
+	}
+	return nil
+}
+
+func executeName(tmpl *template.Template, data nameData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderName executes a template that has already been checked by checkNameTemplate.
+func renderName(tmpl *template.Template, chart *helmv1.HelmChart, action string) string {
+	name, _ := executeName(tmpl, nameData{Action: action, Namespace: chart.Namespace, Name: chart.Name})
+	return name
+}
+
+// ServiceAccountName returns the name of the ServiceAccount that is rendered for the chart.
+func ServiceAccountName(chart *helmv1.HelmChart) string {
+	if !CreateRBAC(chart) {
+		return chart.Spec.ServiceAccountName
+	}
+	return renderName(names.serviceAccount, chart, "")
+}
+
+// ClusterRoleBindingName returns the name of the ClusterRoleBinding that is rendered for the chart.
+func ClusterRoleBindingName(chart *helmv1.HelmChart) string {
+	return renderName(names.clusterRoleBinding, chart, "")
+}
+
+func valuesConfigMapName(chart *helmv1.HelmChart) string {
+	return renderName(names.valuesConfigMap, chart, "")
+}
+
+func contentConfigMapName(chart *helmv1.HelmChart) string {
+	return renderName(names.contentConfigMap, chart, "")
+}
+
+// ProvenanceConfigMapName returns the name of the ConfigMap that records the provenance of the chart's release.
+func ProvenanceConfigMapName(chart *helmv1.HelmChart) string {
+	return renderName(names.provenanceConfigMap, chart, "")
+}
+
+// NetworkPolicyName returns the name of the NetworkPolicy that is rendered for the chart's jobs.
+func NetworkPolicyName(chart *helmv1.HelmChart) string {
+	return renderName(names.networkPolicy, chart, "")
+}
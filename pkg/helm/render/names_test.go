@@ -0,0 +1,47 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+)
+
+func TestSetNameTemplates(t *testing.T) {
+	assert := assert.New(t)
+	defer SetNameTemplates(DefaultNameTemplates)
+	chart := NewChart()
+
+	assert.NoError(SetNameTemplates(NameTemplates{
+		Job:                "{{.Action}}-{{.Name}}-acme",
+		ClusterRoleBinding: "acme-{{.Namespace}}.{{.Name}}",
+		NetworkPolicy:      "{{.Name}}-acme",
+	}))
+	assert.Equal("install-traefik-acme", JobName(chart))
+	assert.Equal("acme-kube-system.traefik", ClusterRoleBindingName(chart))
+	assert.Equal("helm-traefik", ServiceAccountName(chart))
+	assert.Equal("traefik-acme", networkPolicy(chart).Name)
+
+	objs, err := Objects(chart, nil, Options{})
+	assert.NoError(err)
+	assert.Equal("install-traefik-acme", objs.All()[len(objs.All())-1].(*batch.Job).Name)
+}
+
+func TestSetNameTemplatesInvalid(t *testing.T) {
+	assert := assert.New(t)
+	defer SetNameTemplates(DefaultNameTemplates)
+	chart := NewChart()
+
+	tests := map[string]NameTemplates{
+		"parse error":        {Job: "helm-{{.Action"},
+		"unknown field":      {ServiceAccount: "helm-{{.Chart}}"},
+		"invalid name":       {ValuesConfigMap: "Values_{{.Name}}"},
+		"missing action":     {Job: "helm-{{.Name}}"},
+		"missing namespace":  {ClusterRoleBinding: "helm-{{.Name}}"},
+		"missing chart name": {ContentConfigMap: "chart-content"},
+	}
+	for name, templates := range tests {
+		assert.Error(SetNameTemplates(templates), name)
+	}
+	assert.Equal("helm-install-traefik", JobName(chart))
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// helmPluginsPath is where plugins are installed for the job, which helm finds through HELM_PLUGINS
+const helmPluginsPath = "/plugins"
+
+var sha256Checksum = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// ValidateHelmPlugins checks that each of the chart's HelmPlugins is an http or https URL, or an oci reference, with
+// a sha256 checksum, so that plugins cannot be changed without changing the chart.
+func ValidateHelmPlugins(chart *helmv1.HelmChart) error {
+	for i, plugin := range chart.Spec.HelmPlugins {
+		if pluginURL(plugin) == "" {
+			return fmt.Errorf("spec.helmPlugins[%d].url must be an http or https URL of a plugin archive, or an oci:// reference, not %q", i, plugin.URL)
+		}
+		if !sha256Checksum.MatchString(plugin.Checksum) {
+			return fmt.Errorf("spec.helmPlugins[%d].checksum must be in sha256:<hex digest> format, not %q", i, plugin.Checksum)
+		}
+	}
+	return nil
+}
+
+// pluginURL returns the URL that the plugin archive is downloaded from, or an empty string if the plugin's URL is
+// not valid. Plugins with an oci:// reference are downloaded from the registry as the blob with the plugin's
+// checksum as its digest, as OCI blobs are addressed by the sha256 digest of their content; the tag or digest of
+// the reference itself is not used.
+func pluginURL(plugin helmv1.HelmPlugin) string {
+	u, err := url.Parse(plugin.URL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return plugin.URL
+	case "oci":
+		repository := strings.Trim(u.Path, "/")
+		if i := strings.Index(repository, "@"); i >= 0 {
+			repository = repository[:i]
+		}
+		if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+			repository = repository[:i]
+		}
+		if repository == "" {
+			return ""
+		}
+		return fmt.Sprintf("https://%s/v2/%s/blobs/%s", u.Host, repository, plugin.Checksum)
+	}
+	return ""
+}
+
+// setHelmPlugins installs the chart's HelmPlugins for the job with an init container. Each plugin archive is
+// downloaded, from the registry for oci references, and checked against its checksum before it is extracted into its own directory under the plugins
+// volume; archives may hold the plugin at their root, or in a single top-level directory. Plugin install hooks
+// are not run, so plugins that download further binaries when installed are not supported.
+func setHelmPlugins(job *batch.Job, chart *helmv1.HelmChart) {
+	if len(chart.Spec.HelmPlugins) == 0 {
+		return
+	}
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	initContainer := core.Container{
+		Name:            "plugins",
+		Image:           container.Image,
+		ImagePullPolicy: container.ImagePullPolicy,
+		VolumeMounts: []core.VolumeMount{
+			{
+				MountPath: helmPluginsPath,
+				Name:      "plugins",
+			},
+		},
+	}
+	script := []string{"set -e"}
+	for i, plugin := range chart.Spec.HelmPlugins {
+		urlVar, checksumVar := fmt.Sprintf("PLUGIN_%d_URL", i), fmt.Sprintf("PLUGIN_%d_SHA256", i)
+		initContainer.Env = append(initContainer.Env,
+			core.EnvVar{Name: urlVar, Value: pluginURL(plugin)},
+			core.EnvVar{Name: checksumVar, Value: strings.TrimPrefix(plugin.Checksum, "sha256:")})
+		dir := fmt.Sprintf("%s/%d", helmPluginsPath, i)
+		script = append(script,
+			fmt.Sprintf(`wget -q -O /tmp/plugin.tgz "${%s}"`, urlVar),
+			fmt.Sprintf(`echo "${%s}  /tmp/plugin.tgz" | sha256sum -c -`, checksumVar),
+			fmt.Sprintf(`mkdir -p %s && tar -xzf /tmp/plugin.tgz -C %s && rm /tmp/plugin.tgz`, dir, dir),
+			fmt.Sprintf(`if [ ! -f %s/plugin.yaml ]; then mv %s/*/* %s/; fi`, dir, dir, dir))
+	}
+	initContainer.Command = []string{"sh", "-c", strings.Join(script, "; ")}
+	job.Spec.Template.Spec.InitContainers = append(job.Spec.Template.Spec.InitContainers, initContainer)
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name:         "plugins",
+		VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+		MountPath: helmPluginsPath,
+		Name:      "plugins",
+	})
+	container.Env = append(container.Env, core.EnvVar{
+		Name:  "HELM_PLUGINS",
+		Value: helmPluginsPath,
+	})
+}
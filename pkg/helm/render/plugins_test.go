@@ -0,0 +1,64 @@
+package render
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+const pluginChecksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestValidateHelmPlugins(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidateHelmPlugins(chart))
+
+	chart.Spec.HelmPlugins = []v1.HelmPlugin{{URL: "https://github.com/jkroepke/helm-secrets/releases/download/v4.5.0/helm-secrets.tar.gz", Checksum: pluginChecksum}}
+	assert.NoError(ValidateHelmPlugins(chart))
+
+	chart.Spec.HelmPlugins[0].Checksum = "0123"
+	assert.Error(ValidateHelmPlugins(chart))
+
+	chart.Spec.HelmPlugins[0] = v1.HelmPlugin{URL: "oci://registry.example.com/plugins/secrets:4.5.0", Checksum: pluginChecksum}
+	assert.NoError(ValidateHelmPlugins(chart))
+
+	chart.Spec.HelmPlugins[0].URL = "oci://registry.example.com"
+	assert.Error(ValidateHelmPlugins(chart))
+
+	chart.Spec.HelmPlugins[0].URL = "ftp://example.com/helm-secrets.tar.gz"
+	assert.Error(ValidateHelmPlugins(chart))
+}
+
+func TestPluginURL(t *testing.T) {
+	assert := assert.New(t)
+	blob := "https://registry.example.com:5000/v2/plugins/secrets/blobs/" + pluginChecksum
+	for _, ref := range []string{
+		"oci://registry.example.com:5000/plugins/secrets",
+		"oci://registry.example.com:5000/plugins/secrets:4.5.0",
+		"oci://registry.example.com:5000/plugins/secrets@sha256:fedcba",
+	} {
+		assert.Equal(blob, pluginURL(v1.HelmPlugin{URL: ref, Checksum: pluginChecksum}), ref)
+	}
+	assert.Equal("https://example.com/helm-secrets.tar.gz", pluginURL(v1.HelmPlugin{URL: "https://example.com/helm-secrets.tar.gz", Checksum: pluginChecksum}))
+	assert.Empty(pluginURL(v1.HelmPlugin{URL: "secrets"}))
+}
+
+func TestHelmPlugins(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.Nil(jobVolume(installJob, "plugins").EmptyDir)
+
+	chart.Spec.HelmPlugins = []v1.HelmPlugin{{URL: "https://example.com/helm-secrets.tar.gz", Checksum: pluginChecksum}}
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	container := installJob.Spec.Template.Spec.Containers[0]
+	assert.Contains(container.Env, core.EnvVar{Name: "HELM_PLUGINS", Value: helmPluginsPath})
+	assert.Contains(container.VolumeMounts, core.VolumeMount{Name: "plugins", MountPath: helmPluginsPath})
+	assert.NotNil(jobVolume(installJob, "plugins").EmptyDir)
+	initContainer := installJob.Spec.Template.Spec.InitContainers[0]
+	assert.Equal("plugins", initContainer.Name)
+	assert.Contains(initContainer.Env, core.EnvVar{Name: "PLUGIN_0_SHA256", Value: pluginChecksum[len("sha256:"):]})
+	assert.Contains(initContainer.Command[2], "sha256sum -c -")
+}
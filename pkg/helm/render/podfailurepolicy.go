@@ -0,0 +1,107 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// PodFailurePolicyAnnotation is set on the job to the chart's PodFailurePolicy, encoded as JSON. The version of
+// the Kubernetes API that the controller is built against has no pod failure policy on the Job, so the policy is
+// carried on the annotation, and copied into the spec of the job when the job is created.
+const PodFailurePolicyAnnotation = "helmcharts.helm.cattle.io/podFailurePolicy"
+
+const (
+	PodFailurePolicyActionFailJob = "FailJob"
+	PodFailurePolicyActionIgnore  = "Ignore"
+	PodFailurePolicyActionCount   = "Count"
+
+	PodFailurePolicyOperatorIn    = "In"
+	PodFailurePolicyOperatorNotIn = "NotIn"
+
+	// maxPodFailurePolicyRules and maxPodFailurePolicyExitCodes match the limits that the apiserver enforces
+	maxPodFailurePolicyRules     = 20
+	maxPodFailurePolicyExitCodes = 255
+)
+
+// ValidatePodFailurePolicy checks that the chart's PodFailurePolicy, if set, will be accepted by the apiserver:
+// the job must not restart failed containers, as pod failure policies only apply to pods that have failed, and
+// each rule must have a known action and either exit codes or pod conditions to match.
+func ValidatePodFailurePolicy(chart *helmv1.HelmChart) error {
+	policy := chart.Spec.PodFailurePolicy
+	if policy == nil {
+		return nil
+	}
+	if core.RestartPolicy(chart.Spec.RestartPolicy) != core.RestartPolicyNever {
+		return fmt.Errorf("spec.podFailurePolicy can only be set when spec.restartPolicy is %s", core.RestartPolicyNever)
+	}
+	if len(policy.Rules) > maxPodFailurePolicyRules {
+		return fmt.Errorf("spec.podFailurePolicy.rules must have at most %d rules, not %d", maxPodFailurePolicyRules, len(policy.Rules))
+	}
+
+	for i, rule := range policy.Rules {
+		path := fmt.Sprintf("spec.podFailurePolicy.rules[%d]", i)
+		switch rule.Action {
+		case PodFailurePolicyActionFailJob, PodFailurePolicyActionIgnore, PodFailurePolicyActionCount:
+		default:
+			return fmt.Errorf("%s.action must be %s, %s or %s, not %q", path, PodFailurePolicyActionFailJob, PodFailurePolicyActionIgnore, PodFailurePolicyActionCount, rule.Action)
+		}
+		if (rule.OnExitCodes == nil) == (len(rule.OnPodConditions) == 0) {
+			return fmt.Errorf("%s must set exactly one of onExitCodes and onPodConditions", path)
+		}
+		if rule.OnExitCodes != nil {
+			if err := validateOnExitCodes(path+".onExitCodes", rule.OnExitCodes); err != nil {
+				return err
+			}
+		}
+		for j, condition := range rule.OnPodConditions {
+			if condition.Type == "" {
+				return fmt.Errorf("%s.onPodConditions[%d].type must be set", path, j)
+			}
+			switch core.ConditionStatus(condition.Status) {
+			case core.ConditionTrue, core.ConditionFalse, core.ConditionUnknown:
+			default:
+				return fmt.Errorf("%s.onPodConditions[%d].status must be %s, %s or %s, not %q", path, j, core.ConditionTrue, core.ConditionFalse, core.ConditionUnknown, condition.Status)
+			}
+		}
+	}
+	return nil
+}
+
+// validateOnExitCodes checks that the exit codes matched by a pod failure policy rule are unique, and that a rule
+// matching specific exit codes does not match 0, which the apiserver rejects as it never indicates a failure.
+func validateOnExitCodes(path string, onExitCodes *helmv1.HelmChartPodFailurePolicyOnExitCodes) error {
+	if onExitCodes.Operator != PodFailurePolicyOperatorIn && onExitCodes.Operator != PodFailurePolicyOperatorNotIn {
+		return fmt.Errorf("%s.operator must be %s or %s, not %q", path, PodFailurePolicyOperatorIn, PodFailurePolicyOperatorNotIn, onExitCodes.Operator)
+	}
+	if len(onExitCodes.Values) == 0 || len(onExitCodes.Values) > maxPodFailurePolicyExitCodes {
+		return fmt.Errorf("%s.values must have between 1 and %d exit codes", path, maxPodFailurePolicyExitCodes)
+	}
+	seen := map[int32]bool{}
+	for _, value := range onExitCodes.Values {
+		if value == 0 && onExitCodes.Operator == PodFailurePolicyOperatorIn {
+			return fmt.Errorf("%s.values must not contain 0 when the operator is %s", path, PodFailurePolicyOperatorIn)
+		}
+		if seen[value] {
+			return fmt.Errorf("%s.values must not contain %d more than once", path, value)
+		}
+		seen[value] = true
+	}
+	return nil
+}
+
+// setPodFailurePolicy records the chart's PodFailurePolicy on the job's PodFailurePolicyAnnotation, which is
+// included in the job hash, so that the job is replaced when the policy changes.
+func setPodFailurePolicy(job *batch.Job, chart *helmv1.HelmChart) {
+	if chart.Spec.PodFailurePolicy == nil {
+		return
+	}
+	policy, _ := json.Marshal(chart.Spec.PodFailurePolicy)
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[PodFailurePolicyAnnotation] = string(policy)
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+)
+
+func TestValidatePodFailurePolicy(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidatePodFailurePolicy(chart))
+
+	chart.Spec.PodFailurePolicy = &v1.HelmChartPodFailurePolicy{Rules: []v1.HelmChartPodFailurePolicyRule{{
+		Action:      PodFailurePolicyActionFailJob,
+		OnExitCodes: &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: PodFailurePolicyOperatorIn, Values: []int32{3}},
+	}}}
+	assert.EqualError(ValidatePodFailurePolicy(chart), "spec.podFailurePolicy can only be set when spec.restartPolicy is Never")
+
+	chart.Spec.RestartPolicy = "Never"
+	assert.NoError(ValidatePodFailurePolicy(chart))
+
+	chart.Spec.PodFailurePolicy.Rules[0].OnExitCodes.Values = []int32{0, 3}
+	assert.EqualError(ValidatePodFailurePolicy(chart), "spec.podFailurePolicy.rules[0].onExitCodes.values must not contain 0 when the operator is In")
+	chart.Spec.PodFailurePolicy.Rules[0].OnExitCodes.Values = []int32{3, 3}
+	assert.EqualError(ValidatePodFailurePolicy(chart), "spec.podFailurePolicy.rules[0].onExitCodes.values must not contain 3 more than once")
+	chart.Spec.PodFailurePolicy.Rules[0].OnExitCodes = &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: "Equals", Values: []int32{3}}
+	assert.EqualError(ValidatePodFailurePolicy(chart), `spec.podFailurePolicy.rules[0].onExitCodes.operator must be In or NotIn, not "Equals"`)
+
+	chart.Spec.PodFailurePolicy.Rules[0] = v1.HelmChartPodFailurePolicyRule{
+		Action:          PodFailurePolicyActionIgnore,
+		OnPodConditions: []v1.HelmChartPodFailurePolicyOnPodCondition{{Type: "DisruptionTarget", Status: "True"}},
+	}
+	assert.NoError(ValidatePodFailurePolicy(chart))
+	chart.Spec.PodFailurePolicy.Rules[0].OnPodConditions[0].Status = "Yes"
+	assert.EqualError(ValidatePodFailurePolicy(chart), `spec.podFailurePolicy.rules[0].onPodConditions[0].status must be True, False or Unknown, not "Yes"`)
+	chart.Spec.PodFailurePolicy.Rules[0].OnExitCodes = &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: PodFailurePolicyOperatorIn, Values: []int32{3}}
+	assert.EqualError(ValidatePodFailurePolicy(chart), "spec.podFailurePolicy.rules[0] must set exactly one of onExitCodes and onPodConditions")
+
+	chart.Spec.PodFailurePolicy.Rules[0] = v1.HelmChartPodFailurePolicyRule{Action: "Retry", OnExitCodes: &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: PodFailurePolicyOperatorIn, Values: []int32{3}}}
+	assert.EqualError(ValidatePodFailurePolicy(chart), `spec.podFailurePolicy.rules[0].action must be FailJob, Ignore or Count, not "Retry"`)
+}
+
+func TestPodFailurePolicy(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	objs, err := Objects(chart, nil, Options{})
+	assert.NoError(err)
+	all := objs.All()
+	job := all[len(all)-1].(*batch.Job)
+	assert.NotContains(job.Annotations, PodFailurePolicyAnnotation)
+	hash := job.Annotations[JobHashAnnotation]
+
+	chart.Spec.PodFailurePolicy = &v1.HelmChartPodFailurePolicy{Rules: []v1.HelmChartPodFailurePolicyRule{{
+		Action:      PodFailurePolicyActionFailJob,
+		OnExitCodes: &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: PodFailurePolicyOperatorIn, Values: []int32{3}},
+	}}}
+	objs, err = Objects(chart, nil, Options{})
+	assert.NoError(err)
+	all = objs.All()
+	job = all[len(all)-1].(*batch.Job)
+	assert.Equal(`{"rules":[{"action":"FailJob","onExitCodes":{"operator":"In","values":[3]}}]}`, job.Annotations[PodFailurePolicyAnnotation])
+	assert.NotEqual(hash, job.Annotations[JobHashAnnotation])
+}
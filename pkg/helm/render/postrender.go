@@ -0,0 +1,108 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// postRenderPath is where the post-renderer and its kustomization are mounted in the job
+	postRenderPath = "/post-render"
+	// postRenderScript passes the manifests rendered by helm through kustomize, along with the kustomization
+	// mounted next to it. It requires kubectl 1.22 or later in the job image.
+	postRenderScript = `#!/bin/sh
+set -e
+dir=$(mktemp -d)
+trap 'rm -rf "$dir"' EXIT
+cp ` + postRenderPath + `/kustomization.yaml "$dir"
+cat > "$dir/resources.yaml"
+kubectl kustomize "$dir"
+`
+)
+
+// ValidateCommonMetadata checks that the chart's CommonLabels and CommonAnnotations are valid labels and
+// annotations, as the job would otherwise fail to install the chart once they were added to its resources.
+func ValidateCommonMetadata(chart *helmv1.HelmChart) error {
+	return validateMetadata("spec.commonLabels", "spec.commonAnnotations", chart.Spec.CommonLabels, chart.Spec.CommonAnnotations)
+}
+
+// ValidateReleaseMetadata checks that the labels and annotations added to the resources of all releases are valid,
+// as the jobs of every chart would otherwise fail.
+func ValidateReleaseMetadata(labels, annotations map[string]string) error {
+	return validateMetadata("release label", "release annotation", labels, annotations)
+}
+
+func validateMetadata(labelsField, annotationsField string, labels, annotations map[string]string) error {
+	for _, k := range jsonKeys(labels) {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("%s key %q is invalid: %s", labelsField, k, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(labels[k]); len(errs) > 0 {
+			return fmt.Errorf("%s value for key %q is invalid: %s", labelsField, k, strings.Join(errs, ", "))
+		}
+	}
+	for _, k := range jsonKeys(annotations) {
+		if errs := validation.IsQualifiedName(strings.ToLower(k)); len(errs) > 0 {
+			return fmt.Errorf("%s key %q is invalid: %s", annotationsField, k, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// setPostRender adds the chart's CommonLabels and CommonAnnotations, along with the ReleaseLabels and
+// ReleaseAnnotations set for all charts, to every resource of the release, by passing a post-renderer to helm that
+// applies them with kustomize. The chart's labels and annotations take precedence. The post-renderer and its
+// kustomization are stored in the values ConfigMap. Labels are not added to selectors, which are immutable on many
+// resources, and so are not added to pod templates either.
+func setPostRender(job *batch.Job, chart *helmv1.HelmChart, configMap *core.ConfigMap, opts Options) {
+	labels := mergeMissing(mergeMissing(nil, chart.Spec.CommonLabels), opts.ReleaseLabels)
+	annotations := mergeMissing(mergeMissing(nil, chart.Spec.CommonAnnotations), opts.ReleaseAnnotations)
+	if (len(labels) == 0 && len(annotations) == 0) || chart.DeletionTimestamp != nil {
+		return
+	}
+
+	kustomization := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  []string{"resources.yaml"},
+	}
+	if len(labels) > 0 {
+		kustomization["labels"] = []map[string]interface{}{{"pairs": labels, "includeSelectors": false}}
+	}
+	if len(annotations) > 0 {
+		kustomization["commonAnnotations"] = annotations
+	}
+	// JSON is valid YAML, and the kustomization only holds strings, so it always marshals
+	data, _ := json.Marshal(kustomization)
+	// the kustomization is not stored with a .yaml extension, so that it is not used as a values file
+	configMap.Data["post-render"] = postRenderScript
+	configMap.Data["post-render-kustomization"] = string(data)
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name: "post-render",
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{
+				LocalObjectReference: core.LocalObjectReference{Name: configMap.Name},
+				Items: []core.KeyToPath{
+					{Key: "post-render", Path: "post-render", Mode: pointer.Int32Ptr(0755)},
+					{Key: "post-render-kustomization", Path: "kustomization.yaml"},
+				},
+			},
+		},
+	})
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+		MountPath: postRenderPath,
+		Name:      "post-render",
+		ReadOnly:  true,
+	})
+	container.Args = append(container.Args, "--post-renderer", path.Join(postRenderPath, "post-render"))
+}
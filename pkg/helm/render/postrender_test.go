@@ -0,0 +1,90 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPostRender(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Set = nil
+	installJob, valuesConfigMap, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.NotContains(installJob.Spec.Template.Spec.Containers[0].Args, "--post-renderer")
+	assert.NotContains(valuesConfigMap.Data, "post-render")
+
+	chart.Spec.CommonLabels = map[string]string{"team": "edge"}
+	chart.Spec.CommonAnnotations = map[string]string{"example.com/owner": "platform"}
+	assert.NoError(ValidateCommonMetadata(chart))
+
+	objs, err := Objects(chart, nil, Options{})
+	assert.NoError(err)
+	all := objs.All()
+	installJob = all[len(all)-1].(*batch.Job)
+	assert.Equal([]string{"install", "--post-renderer", "/post-render/post-render"}, installJob.Spec.Template.Spec.Containers[0].Args)
+	volume := jobVolume(installJob, "post-render")
+	var configMap *core.ConfigMap
+	for _, obj := range all {
+		if cm, ok := obj.(*core.ConfigMap); ok && volume.ConfigMap != nil && cm.Name == volume.ConfigMap.Name {
+			configMap = cm
+		}
+	}
+	if assert.NotNil(configMap) {
+		assert.Equal(postRenderScript, configMap.Data["post-render"])
+		assert.JSONEq(`{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind": "Kustomization",
+			"resources": ["resources.yaml"],
+			"labels": [{"pairs": {"team": "edge"}, "includeSelectors": false}],
+			"commonAnnotations": {"example.com/owner": "platform"}
+		}`, configMap.Data["post-render-kustomization"])
+	}
+
+	deleteTime := v12.NewTime(v12.Now().Time)
+	chart.DeletionTimestamp = &deleteTime
+	deleteJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.NotContains(deleteJob.Spec.Template.Spec.Containers[0].Args, "--post-renderer")
+}
+
+func TestReleaseMetadata(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.CommonLabels = map[string]string{"team": "edge"}
+	opts := Options{
+		JobImage:           DefaultJobImage,
+		ReleaseLabels:      map[string]string{"team": "platform", "io.cattle.field/appId": "traefik"},
+		ReleaseAnnotations: map[string]string{"field.cattle.io/projectId": "c-m-abc:p-xyz"},
+	}
+	installJob, valuesConfigMap, _ := job(chart, opts)
+	assert.Contains(installJob.Spec.Template.Spec.Containers[0].Args, "--post-renderer")
+	assert.JSONEq(`{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind": "Kustomization",
+		"resources": ["resources.yaml"],
+		"labels": [{"pairs": {"team": "edge", "io.cattle.field/appId": "traefik"}, "includeSelectors": false}],
+		"commonAnnotations": {"field.cattle.io/projectId": "c-m-abc:p-xyz"}
+	}`, valuesConfigMap.Data["post-render-kustomization"])
+	assert.Len(chart.Spec.CommonLabels, 1)
+
+	assert.NoError(ValidateReleaseMetadata(opts.ReleaseLabels, opts.ReleaseAnnotations))
+	assert.Error(ValidateReleaseMetadata(map[string]string{"app": "not valid"}, nil))
+	assert.Error(ValidateReleaseMetadata(nil, map[string]string{"-owner": "platform"}))
+}
+
+func TestValidateCommonMetadata(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.CommonLabels = map[string]string{"team": "not a valid value"}
+	assert.Error(ValidateCommonMetadata(chart))
+	chart.Spec.CommonLabels = map[string]string{"-team": "edge"}
+	assert.Error(ValidateCommonMetadata(chart))
+	chart.Spec.CommonLabels = nil
+	chart.Spec.CommonAnnotations = map[string]string{"example.com/Owner": "anything goes"}
+	assert.NoError(ValidateCommonMetadata(chart))
+	chart.Spec.CommonAnnotations = map[string]string{"example.com/owner/extra": "x"}
+	assert.Error(ValidateCommonMetadata(chart))
+}
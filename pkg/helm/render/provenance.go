@@ -0,0 +1,52 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProvenanceLabel is set on provenance ConfigMaps, so that scanning pipelines can select them.
+const ProvenanceLabel = "helmcharts.helm.cattle.io/provenance"
+
+// Provenance describes what the latest deployed release of a chart installed.
+type Provenance struct {
+	// Chart, Version and AppVersion are from the metadata of the chart in the release.
+	Chart      string
+	Version    string
+	AppVersion string
+	// Revision is the revision of the release.
+	Revision int
+	// Images are the container images referenced by the resources of the release, sorted and without duplicates.
+	Images []string
+}
+
+// provenanceConfigMap returns a ConfigMap recording the provenance of the chart's release, with the images one
+// per line, so that vulnerability scanners can be pointed at what was actually deployed.
+func provenanceConfigMap(chart *helmv1.HelmChart, provenance *Provenance) *core.ConfigMap {
+	return &core.ConfigMap{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      ProvenanceConfigMapName(chart),
+			Namespace: chart.Namespace,
+			Labels: map[string]string{
+				Label:           chart.Name,
+				ProvenanceLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			"chart":      provenance.Chart,
+			"version":    provenance.Version,
+			"appVersion": provenance.AppVersion,
+			"release":    TargetNamespace(chart) + "/" + ReleaseName(chart),
+			"revision":   strconv.Itoa(provenance.Revision),
+			"images":     strings.Join(provenance.Images, "\n"),
+		},
+	}
+}
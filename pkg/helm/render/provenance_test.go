@@ -0,0 +1,60 @@
+package render
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+func TestProvenanceConfigMap(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.UID = "1234"
+	chart.Spec.TargetNamespace = "ingress"
+	provenance := &Provenance{
+		Chart:      "traefik",
+		Version:    "10.3.0",
+		AppVersion: "2.5.1",
+		Revision:   3,
+		Images:     []string{"docker.io/traefik:2.5.1", "docker.io/busybox:1.34"},
+	}
+	opts := Options{ProvenanceGetter: func(*v1.HelmChart) (*Provenance, error) { return provenance, nil }}
+
+	objs, err := Objects(chart, nil, opts)
+	assert.NoError(err)
+	all := objs.All()
+	_, ok := all[len(all)-1].(*batch.Job)
+	assert.True(ok)
+
+	var configMap *core.ConfigMap
+	for _, obj := range all {
+		if cm, ok := obj.(*core.ConfigMap); ok && cm.Name == "chart-provenance-traefik" {
+			configMap = cm
+		}
+	}
+	if assert.NotNil(configMap) {
+		assert.Equal(map[string]string{
+			"chart":      "traefik",
+			"version":    "10.3.0",
+			"appVersion": "2.5.1",
+			"release":    "ingress/traefik",
+			"revision":   "3",
+			"images":     "docker.io/traefik:2.5.1\ndocker.io/busybox:1.34",
+		}, configMap.Data)
+		assert.Equal("true", configMap.Labels[ProvenanceLabel])
+		assert.Len(configMap.OwnerReferences, 1)
+	}
+
+	// nothing is recorded until the chart has been installed
+	provenance = nil
+	objs, err = Objects(chart, nil, opts)
+	assert.NoError(err)
+	for _, obj := range objs.All() {
+		if cm, ok := obj.(*core.ConfigMap); ok {
+			assert.NotEqual("chart-provenance-traefik", cm.Name)
+		}
+	}
+}
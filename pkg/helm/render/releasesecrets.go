@@ -0,0 +1,44 @@
+package render
+
+import (
+	"sort"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// setReleaseSecretMetadata passes the labels and annotations to add to the Secrets that helm stores the release
+// in to the job, which applies them once the release is installed, so that backup tools selecting by label include
+// the release history along with the resources of the release. The chart's labels take precedence over those set
+// for all charts.
+func setReleaseSecretMetadata(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	if chart.DeletionTimestamp != nil {
+		return
+	}
+	labels := mergeMissing(mergeMissing(nil, chart.Spec.ReleaseSecretLabels), opts.ReleaseSecretLabels)
+	container := &job.Spec.Template.Spec.Containers[0]
+	if len(labels) > 0 {
+		container.Env = append(container.Env, core.EnvVar{
+			Name:  "RELEASE_SECRET_LABELS",
+			Value: joinMetadata(labels),
+		})
+	}
+	if len(chart.Spec.ReleaseSecretAnnotations) > 0 {
+		container.Env = append(container.Env, core.EnvVar{
+			Name:  "RELEASE_SECRET_ANNOTATIONS",
+			Value: joinMetadata(chart.Spec.ReleaseSecretAnnotations),
+		})
+	}
+}
+
+// joinMetadata formats labels or annotations as a sorted, comma-separated list of key=value pairs.
+func joinMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for k, v := range metadata {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,26 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestReleaseSecretMetadata(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	for _, env := range installJob.Spec.Template.Spec.Containers[0].Env {
+		assert.NotEqual("RELEASE_SECRET_LABELS", env.Name)
+	}
+
+	chart.Spec.ReleaseSecretLabels = map[string]string{"backup": "false", "team": "edge"}
+	chart.Spec.ReleaseSecretAnnotations = map[string]string{"velero.io/exclude-from-backup": "false"}
+	opts := Options{JobImage: DefaultJobImage, ReleaseSecretLabels: map[string]string{"backup": "true", "cluster": "prod"}}
+	installJob, _, _ = job(chart, opts)
+	env := installJob.Spec.Template.Spec.Containers[0].Env
+	assert.Contains(env, core.EnvVar{Name: "RELEASE_SECRET_LABELS", Value: "backup=false,cluster=prod,team=edge"})
+	assert.Contains(env, core.EnvVar{Name: "RELEASE_SECRET_ANNOTATIONS", Value: "velero.io/exclude-from-backup=false"})
+	assert.Len(chart.Spec.ReleaseSecretLabels, 2)
+}
@@ -0,0 +1,1525 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/objectset"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
+)
+
+var (
+	commaRE = regexp.MustCompile(`\\*,`)
+
+	// ActiveDeadlineMargin is added to the time allowed for helm operations when setting the job pod's
+	// active deadline, to allow for pulling the chart and for the job's own setup.
+	ActiveDeadlineMargin = 2 * time.Minute
+)
+
+const (
+	DefaultJobImage      = "rancher/klipper-helm:v0.7.3-build20220613"
+	DefaultFailurePolicy = FailurePolicyReinstall
+
+	Label      = "helmcharts.helm.cattle.io/chart"
+	Annotation = "helmcharts.helm.cattle.io/configHash"
+	// ConfigHashVersion identifies the scheme used to compute the config hash in Annotation.
+	ConfigHashVersion = "v2"
+	// JobHashAnnotation is set on the job to a hash of its rendered spec, so that a job can be
+	// identified as having been created from the chart's current configuration.
+	JobHashAnnotation = "helmcharts.helm.cattle.io/jobHash"
+	// HelmChartConfigHashAnnotation is set on the job to the ConfigHash of the HelmChartConfig it was rendered
+	// with, so that the config can tell whether it has been rolled out to its charts.
+	HelmChartConfigHashAnnotation = "helmcharts.helm.cattle.io/helmChartConfigHash"
+
+	TaintExternalCloudProvider = "node.cloudprovider.kubernetes.io/uninitialized"
+	LabelNodeRolePrefix        = "node-role.kubernetes.io/"
+	LabelControlPlaneSuffix    = "control-plane"
+	LabelEtcdSuffix            = "etcd"
+
+	FailurePolicyReinstall = "reinstall"
+	FailurePolicyAbort     = "abort"
+
+	CredentialsMountModeEnv  = "env"
+	CredentialsMountModeFile = "file"
+
+	// TargetNamespacePolicyIgnore installs the release under its new name or target namespace, leaving the old release behind
+	TargetNamespacePolicyIgnore = "ignore"
+	// TargetNamespacePolicyReject refuses to change the release name or target namespace of an installed chart
+	TargetNamespacePolicyReject = "reject"
+	// TargetNamespacePolicyMigrate uninstalls the old release before installing it under its new name or target namespace
+	TargetNamespacePolicyMigrate = "migrate"
+
+	// DefaultCABundleKey is the ConfigMap key that the CA bundle is read from if none is specified
+	DefaultCABundleKey = "ca.crt"
+	caBundlePath       = "/etc/helm-controller/ca-bundle"
+	caBundleFile       = "ca.crt"
+
+	// jobUser is the uid and gid of the klipper-helm user in the job image
+	jobUser = 1000
+	// jobHome is the home directory of the klipper-helm user, where helm keeps its cache and config
+	jobHome = "/home/klipper-helm"
+)
+
+// SafeToEvictAnnotation tells the cluster autoscaler whether it may evict a pod when scaling down its node.
+const SafeToEvictAnnotation = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+
+// SidecarAnnotations are added to the job pod template to prevent service meshes from injecting sidecars,
+// which would otherwise keep the pod running after helm exits and prevent the job from ever completing.
+var SidecarAnnotations = map[string]string{
+	"sidecar.istio.io/inject":   "false",
+	"linkerd.io/inject":         "disabled",
+	"kuma.io/sidecar-injection": "disabled",
+}
+
+// Options holds the controller-level settings used when rendering a HelmChart.
+// Zero values fall back to the package defaults.
+type Options struct {
+	// JobImage is the image used by jobs for charts that do not specify one.
+	JobImage string
+	// FailurePolicy is used for charts when neither the chart nor its config specify one.
+	FailurePolicy string
+	// CommonLabels are added to every generated object, and may be overridden by the chart's GeneratedLabels.
+	CommonLabels map[string]string
+	// SecretGetter is used to retrieve Secrets referenced by the chart whose content is included in the
+	// config hash. If nil, referenced Secrets are still mounted but changes to them will not trigger a new job.
+	SecretGetter func(namespace, name string) (*core.Secret, error)
+	// ConfigMapGetter is used to retrieve ConfigMaps referenced by the chart whose content is included in the
+	// config hash. If nil, referenced ConfigMaps are still mounted but changes to them will not trigger a new job.
+	ConfigMapGetter func(namespace, name string) (*core.ConfigMap, error)
+	// DisableSidecars adds SidecarAnnotations to jobs for charts that do not set DisableSidecars themselves.
+	DisableSidecars bool
+	// SafeToEvict sets SafeToEvictAnnotation on jobs for charts that do not set SafeToEvict themselves. If nil,
+	// the annotation is not set, and the autoscaler applies its own rules.
+	SafeToEvict *bool
+	// DisableSecurityContext runs the job container without a restrictive security context, for charts that do
+	// not set DisableSecurityContext themselves.
+	DisableSecurityContext bool
+	// TolerateUnschedulable allows jobs to run on cordoned nodes, for charts that do not set TolerateUnschedulable themselves.
+	TolerateUnschedulable bool
+	// RepoMirrors maps upstream repo URL prefixes to the mirror URL prefixes that they should be replaced with.
+	RepoMirrors map[string]string
+	// RepoMirrorAuthSecret is the name of the Secret in the chart's namespace holding credentials for the
+	// mirror. It is used for charts whose repo is rewritten to a mirror and that do not set an AuthSecret.
+	RepoMirrorAuthSecret string
+	// CABundle references a key of a ConfigMap in the chart's namespace holding a CA bundle that is used by
+	// jobs for charts that do not set CABundle themselves.
+	CABundle *core.ConfigMapKeySelector
+	// SpreadJobs prefers scheduling jobs on nodes not already running other jobs, for charts that do not set SpreadJobs themselves.
+	SpreadJobs bool
+	// NodeSelector is added to the node selector of jobs for charts that are not bootstrap charts. Keys set in
+	// the chart's JobNodeSelector take precedence.
+	NodeSelector map[string]string
+	// Tolerations are added to jobs for charts that are not bootstrap charts, along with the chart's JobTolerations.
+	Tolerations []core.Toleration
+	// ReleaseSecretLabels are added to the Secrets that helm stores releases in, and may be overridden by the
+	// chart's ReleaseSecretLabels.
+	ReleaseSecretLabels map[string]string
+	// ReleaseLabels and ReleaseAnnotations are added to every resource that charts install, for example to group
+	// them under an app in the Rancher UI, and may be overridden by the chart's CommonLabels and CommonAnnotations.
+	ReleaseLabels      map[string]string
+	ReleaseAnnotations map[string]string
+	// DeleteJobTTL is how long a finished delete job is kept before it is removed by the TTL controller, in case
+	// the chart's finalizer is removed without the controller; zero keeps the job until the controller removes it.
+	DeleteJobTTL time.Duration
+	// ProjectedServiceAccountToken mounts a projected ServiceAccount token into jobs, instead of relying on the
+	// token being automounted.
+	ProjectedServiceAccountToken bool
+	// ValuesTransformers are passed the merged values of the chart and its config in turn, and may modify them
+	// before they are stored in the values ConfigMap.
+	ValuesTransformers []ValuesTransformer
+	// TargetContextGetter is used to find the target context, in the chart's namespace, that a chart with a
+	// TargetContext is installed into. If nil, charts that set a TargetContext cannot be rendered.
+	TargetContextGetter func(namespace, name string) (*TargetContext, error)
+	// ProvenanceGetter is used to find the provenance of the chart's deployed release, which is recorded in a
+	// ConfigMap. If nil, or if it returns nil, no provenance ConfigMap is rendered.
+	ProvenanceGetter func(chart *helmv1.HelmChart) (*Provenance, error)
+}
+
+// Objects renders the Job, ConfigMaps, ServiceAccount, and ClusterRoleBinding that the controller
+// creates for a HelmChart, taking into account the HelmChartConfig for the chart if one is provided.
+func Objects(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig, opts Options) (*objectset.ObjectSet, error) {
+	if opts.JobImage == "" {
+		opts.JobImage = DefaultJobImage
+	}
+	if opts.FailurePolicy == "" {
+		opts.FailurePolicy = DefaultFailurePolicy
+	}
+
+	chart = mirrorRepo(chart, opts)
+
+	failurePolicy := opts.FailurePolicy
+	objs := objectset.NewObjectSet()
+	job, valuesConfigMap, contentConfigMap := job(chart, opts)
+	if CreateRBAC(chart) {
+		objs.Add(serviceAccount(chart))
+		objs.Add(roleBinding(chart))
+	}
+	if chart.Spec.GenerateNetworkPolicy {
+		objs.Add(networkPolicy(chart))
+	}
+
+	if chart.Spec.FailurePolicy != "" {
+		failurePolicy = chart.Spec.FailurePolicy
+	}
+
+	if config != nil {
+		valuesConfigMapAddConfig(valuesConfigMap, config)
+		if config.Spec.FailurePolicy != "" {
+			failurePolicy = config.Spec.FailurePolicy
+		}
+	}
+
+	if err := transformValues(valuesConfigMap, chart, config, opts.ValuesTransformers); err != nil {
+		return nil, err
+	}
+
+	setFailurePolicy(job, failurePolicy)
+	if err := setTargetContext(job, chart, opts); err != nil {
+		return nil, err
+	}
+
+	hashMaps := []*core.ConfigMap{contentConfigMap, valuesConfigMap}
+	if chartContentSecret, err := chartContentSecret(chart, opts); err != nil {
+		return nil, err
+	} else if chartContentSecret != nil {
+		// include the secret content in the hash in the same way as content stored in the ConfigMap
+		hashMaps = append(hashMaps, &core.ConfigMap{BinaryData: chartContentSecret.Data})
+	}
+	if caBundleContent, err := caBundleContent(chart, opts); err != nil {
+		return nil, err
+	} else if caBundleContent != nil {
+		hashMaps = append(hashMaps, caBundleContent)
+	}
+	if setFilesContent, err := setFilesContent(chart, opts); err != nil {
+		return nil, err
+	} else if len(setFilesContent.BinaryData) > 0 {
+		hashMaps = append(hashMaps, setFilesContent)
+	}
+	hashConfigMaps(job, hashMaps...)
+	setConfigMapRevisions(job, contentConfigMap, valuesConfigMap)
+
+	objs.Add(contentConfigMap)
+	objs.Add(valuesConfigMap)
+	if opts.ProvenanceGetter != nil && chart.DeletionTimestamp == nil {
+		provenance, err := opts.ProvenanceGetter(chart)
+		if err != nil {
+			return nil, err
+		}
+		if provenance != nil {
+			objs.Add(provenanceConfigMap(chart, provenance))
+		}
+	}
+	objs.Add(job)
+
+	if err := setGeneratedMetadata(objs, chart, opts); err != nil {
+		return nil, err
+	}
+	if err := setOwnerReferences(objs, chart); err != nil {
+		return nil, err
+	}
+	if err := setJobHash(job); err != nil {
+		return nil, err
+	}
+	if config != nil {
+		job.Annotations[HelmChartConfigHashAnnotation] = ConfigHash(config)
+	}
+
+	return objs, nil
+}
+
+// ConfigHash returns a hash of the settings that the HelmChartConfig contributes to the jobs of its charts.
+func ConfigHash(config *helmv1.HelmChartConfig) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d:%s%d:%s", len(config.Spec.ValuesContent), config.Spec.ValuesContent, len(config.Spec.FailurePolicy), config.Spec.FailurePolicy)
+	return fmt.Sprintf("SHA256=%X", hash.Sum(nil))
+}
+
+// JobName returns the name of the Job that is rendered for the chart in its current state.
+func JobName(chart *helmv1.HelmChart) string {
+	action := "install"
+	if chart.DeletionTimestamp != nil {
+		action = "delete"
+	}
+	return renderName(names.job, chart, action)
+}
+
+// RollbackJob renders a Job that rolls the chart's release back to the given revision. The job is based on the
+// chart's install job, so that it runs with the same image, credentials, and placement, but does not need the
+// chart content, so the install job's init containers are not included.
+func RollbackJob(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig, opts Options, revision int) (*batch.Job, error) {
+	objs, err := Objects(chart, config, opts)
+	if err != nil {
+		return nil, err
+	}
+	var job *batch.Job
+	for _, obj := range objs.All() {
+		if rendered, ok := obj.(*batch.Job); ok {
+			job = rendered.DeepCopy()
+			break
+		}
+	}
+	if job == nil {
+		return nil, fmt.Errorf("no job rendered for HelmChart %s/%s", chart.Namespace, chart.Name)
+	}
+	job.Name = renderName(names.job, chart, "rollback")
+	delete(job.Annotations, JobHashAnnotation)
+	job.Spec.Template.Spec.InitContainers = nil
+	job.Spec.Template.Spec.Containers[0].Args = []string{"rollback", strconv.Itoa(revision)}
+	return job, nil
+}
+
+// TargetNamespace returns the namespace that the chart's release is installed into.
+func TargetNamespace(chart *helmv1.HelmChart) string {
+	if len(chart.Spec.TargetNamespace) != 0 {
+		return chart.Spec.TargetNamespace
+	}
+	return chart.Namespace
+}
+
+// ReleaseName returns the name of the chart's release, which defaults to the name of the chart.
+func ReleaseName(chart *helmv1.HelmChart) string {
+	if len(chart.Spec.ReleaseName) != 0 {
+		return chart.Spec.ReleaseName
+	}
+	return chart.Name
+}
+
+func job(chart *helmv1.HelmChart, opts Options) (*batch.Job, *core.ConfigMap, *core.ConfigMap) {
+	jobImage := strings.TrimSpace(chart.Spec.JobImage)
+	if jobImage == "" {
+		jobImage = opts.JobImage
+	}
+
+	job := &batch.Job{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      JobName(chart),
+			Namespace: chart.Namespace,
+			Labels: map[string]string{
+				Label: chart.Name,
+			},
+		},
+		Spec: batch.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(1000),
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: map[string]string{},
+					Labels: map[string]string{
+						Label: chart.Name,
+					},
+				},
+				Spec: core.PodSpec{
+					RestartPolicy: core.RestartPolicyOnFailure,
+					Containers: []core.Container{
+						{
+							Name:            "helm",
+							Image:           jobImage,
+							ImagePullPolicy: core.PullIfNotPresent,
+							Args:            args(chart),
+							EnvFrom:         chart.Spec.JobEnvFrom,
+							Env: []core.EnvVar{
+								{
+									Name:  "NAME",
+									Value: ReleaseName(chart),
+								},
+								{
+									Name:  "VERSION",
+									Value: chart.Spec.Version,
+								},
+								{
+									Name:  "REPO",
+									Value: chart.Spec.Repo,
+								},
+								{
+									Name:  "HELM_DRIVER",
+									Value: "secret",
+								},
+								{
+									Name:  "CHART_NAMESPACE",
+									Value: chart.Namespace,
+								},
+								{
+									Name:  "CHART",
+									Value: chart.Spec.Chart,
+								},
+								{
+									Name:  "HELM_VERSION",
+									Value: chart.Spec.HelmVersion,
+								},
+								{
+									Name:  "TARGET_NAMESPACE",
+									Value: TargetNamespace(chart),
+								},
+							},
+						},
+					},
+					ServiceAccountName: ServiceAccountName(chart),
+					HostAliases:        chart.Spec.HostAliases,
+				},
+			},
+		},
+	}
+
+	// helm applies its timeout to each operation that it waits on, such as each hook, so a separate helm timeout
+	// replaces the timeout passed to helm, while the chart's timeout still bounds the job as a whole
+	if timeout := helmTimeout(chart); timeout != nil {
+		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
+			Name:  "TIMEOUT",
+			Value: timeout.Duration.String(),
+		})
+	}
+	if chart.DeletionTimestamp != nil && chart.Spec.UninstallTimeout != nil {
+		job.Spec.Template.Spec.ActiveDeadlineSeconds = pointer.Int64Ptr(int64((chart.Spec.UninstallTimeout.Duration + ActiveDeadlineMargin).Seconds()))
+	} else if chart.Spec.Timeout != nil {
+		job.Spec.Template.Spec.ActiveDeadlineSeconds = activeDeadlineSeconds(chart.Spec.Timeout.Duration)
+	}
+
+	// with Never, a new pod is created for each retry, so that the logs of failed attempts are kept
+	if chart.Spec.RestartPolicy != "" {
+		job.Spec.Template.Spec.RestartPolicy = core.RestartPolicy(chart.Spec.RestartPolicy)
+	}
+
+	if chart.Spec.FailurePolicyRetries != nil {
+		// restarts of the failed container count against the backoff limit, so once the limit is
+		// reached the job fails instead of reinstalling the chart indefinitely
+		job.Spec.BackoffLimit = pointer.Int32Ptr(*chart.Spec.FailurePolicyRetries)
+	}
+
+	job.Spec.Template.Spec.NodeSelector = make(map[string]string)
+	job.Spec.Template.Spec.NodeSelector[core.LabelOSStable] = "linux"
+
+	if chart.Spec.Bootstrap {
+		job.Spec.Template.Spec.NodeSelector[LabelNodeRolePrefix+LabelControlPlaneSuffix] = "true"
+		job.Spec.Template.Spec.HostNetwork = true
+		job.Spec.Template.Spec.Tolerations = []core.Toleration{
+			{
+				Key:    core.TaintNodeNotReady,
+				Effect: core.TaintEffectNoSchedule,
+			},
+			{
+				Key:      TaintExternalCloudProvider,
+				Operator: core.TolerationOpEqual,
+				Value:    "true",
+				Effect:   core.TaintEffectNoSchedule,
+			},
+			{
+				Key:      "CriticalAddonsOnly",
+				Operator: core.TolerationOpExists,
+			},
+			{
+				Key:      LabelNodeRolePrefix + LabelEtcdSuffix,
+				Operator: core.TolerationOpExists,
+				Effect:   core.TaintEffectNoExecute,
+			},
+			{
+				Key:      LabelNodeRolePrefix + LabelControlPlaneSuffix,
+				Operator: core.TolerationOpExists,
+				Effect:   core.TaintEffectNoSchedule,
+			},
+		}
+		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, []core.EnvVar{
+			{
+				Name:  "KUBERNETES_SERVICE_HOST",
+				Value: "127.0.0.1"},
+			{
+				Name:  "KUBERNETES_SERVICE_PORT",
+				Value: "6443"},
+			{
+				Name:  "BOOTSTRAP",
+				Value: "true"},
+		}...)
+	}
+
+	setProxyEnv(job, chart)
+	setSidecarAnnotations(job, chart, opts)
+	setSafeToEvict(job, chart, opts)
+	setJobPlacement(job, chart, opts)
+	setUnschedulableToleration(job, chart, opts)
+	setJobSpread(job, chart, opts)
+	setAuthSecret(job, chart)
+	setSetFiles(job, chart)
+	setRepoArtifact(job, chart)
+	setChartFetcher(job, chart)
+	setRepoStorage(job, chart)
+	setChartChecksum(job, chart)
+	setReleaseSecretMetadata(job, chart, opts)
+	setHelmPlugins(job, chart)
+	setCABundle(job, chart, opts)
+	valueConfigMap := setValuesConfigMap(job, chart)
+	setPostRender(job, chart, valueConfigMap, opts)
+	contentConfigMap := setContentConfigMap(job, chart)
+	setBootstrapWait(job, chart)
+	setSecurityContext(job, chart, opts)
+	setDeleteJobTTL(job, chart, opts)
+	setJobSuspend(job, chart)
+	setPodFailurePolicy(job, chart)
+	setServiceAccountToken(job, opts)
+
+	return job, valueConfigMap, contentConfigMap
+}
+
+func valuesConfigMap(chart *helmv1.HelmChart) *core.ConfigMap {
+	var configMap = &core.ConfigMap{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      valuesConfigMapName(chart),
+			Namespace: chart.Namespace,
+		},
+		Data: map[string]string{},
+	}
+
+	if chart.Spec.ValuesContent != "" {
+		configMap.Data["values-01_HelmChart.yaml"] = chart.Spec.ValuesContent
+	}
+	if chart.Spec.RepoCA != "" {
+		configMap.Data["ca-file.pem"] = chart.Spec.RepoCA
+	}
+
+	return configMap
+}
+
+func valuesConfigMapAddConfig(configMap *core.ConfigMap, config *helmv1.HelmChartConfig) {
+	if config.Spec.ValuesContent != "" {
+		configMap.Data["values-10_HelmChartConfig.yaml"] = config.Spec.ValuesContent
+	}
+}
+
+// networkPolicy allows the job pod egress to DNS, the chart repo, and the Kubernetes API server, so that
+// charts can be installed into namespaces with a default-deny policy. NetworkPolicies cannot select
+// destinations by hostname, so egress is allowed by port.
+func networkPolicy(chart *helmv1.HelmChart) *networking.NetworkPolicy {
+	tcp := core.ProtocolTCP
+	udp := core.ProtocolUDP
+	port := func(protocol *core.Protocol, port int) networking.NetworkPolicyPort {
+		p := intstr.FromInt(port)
+		return networking.NetworkPolicyPort{Protocol: protocol, Port: &p}
+	}
+
+	egress := []networking.NetworkPolicyEgressRule{
+		{
+			Ports: []networking.NetworkPolicyPort{port(&udp, 53), port(&tcp, 53)},
+		},
+		{
+			Ports: []networking.NetworkPolicyPort{port(&tcp, 443), port(&tcp, 6443)},
+		},
+	}
+	if repoPort := repoPort(chart.Spec.Repo); repoPort != 0 && repoPort != 443 {
+		egress = append(egress, networking.NetworkPolicyEgressRule{
+			Ports: []networking.NetworkPolicyPort{port(&tcp, repoPort)},
+		})
+	}
+
+	return &networking.NetworkPolicy{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      NetworkPolicyName(chart),
+			Namespace: chart.Namespace,
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: meta.LabelSelector{
+				MatchLabels: map[string]string{
+					Label: chart.Name,
+				},
+			},
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
+// repoPort returns the port used to reach the repo, or 0 if the chart does not use a repo.
+func repoPort(repo string) int {
+	if repo == "" {
+		return 0
+	}
+	u, err := url.Parse(repo)
+	if err != nil {
+		return 0
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	if u.Scheme == "http" {
+		return 80
+	}
+	return 443
+}
+
+// CreateRBAC returns true unless the chart opts out of the generated ServiceAccount and ClusterRoleBinding, for
+// charts that only need namespace-scoped permissions, or none at all. Such charts use their own ServiceAccountName.
+func CreateRBAC(chart *helmv1.HelmChart) bool {
+	return chart.Spec.CreateRBAC == nil || *chart.Spec.CreateRBAC
+}
+
+// ValidateServiceAccount checks that charts that opt out of the generated ServiceAccount name their own, and that
+// charts that use the generated ServiceAccount do not.
+func ValidateServiceAccount(chart *helmv1.HelmChart) error {
+	if CreateRBAC(chart) && chart.Spec.ServiceAccountName != "" {
+		return fmt.Errorf("spec.serviceAccountName can only be set when spec.createRBAC is false")
+	}
+	if !CreateRBAC(chart) && chart.Spec.ServiceAccountName == "" {
+		return fmt.Errorf("spec.serviceAccountName must be set when spec.createRBAC is false")
+	}
+	return nil
+}
+
+// ValidateRestartPolicy checks that the chart's RestartPolicy, if set, is one that jobs support.
+func ValidateRestartPolicy(chart *helmv1.HelmChart) error {
+	switch core.RestartPolicy(chart.Spec.RestartPolicy) {
+	case "", core.RestartPolicyOnFailure, core.RestartPolicyNever:
+		return nil
+	}
+	return fmt.Errorf("spec.restartPolicy must be %s or %s, not %q", core.RestartPolicyOnFailure, core.RestartPolicyNever, chart.Spec.RestartPolicy)
+}
+
+// ValidateCredentialsMountMode checks that the chart's CredentialsMountMode, if set, is one that the job supports,
+// so that a typo does not silently expose the credentials in the job's environment.
+func ValidateCredentialsMountMode(chart *helmv1.HelmChart) error {
+	switch chart.Spec.CredentialsMountMode {
+	case "", CredentialsMountModeEnv, CredentialsMountModeFile:
+		return nil
+	}
+	return fmt.Errorf("spec.credentialsMountMode must be %s or %s, not %q", CredentialsMountModeEnv, CredentialsMountModeFile, chart.Spec.CredentialsMountMode)
+}
+
+// ValidateTargetNamespacePolicy checks that the chart's TargetNamespacePolicy, if set, is one that the controller
+// supports, so that a typo does not silently leave the release behind when the target namespace is changed.
+func ValidateTargetNamespacePolicy(chart *helmv1.HelmChart) error {
+	switch chart.Spec.TargetNamespacePolicy {
+	case "", TargetNamespacePolicyIgnore, TargetNamespacePolicyReject, TargetNamespacePolicyMigrate:
+		return nil
+	}
+	return fmt.Errorf("spec.targetNamespacePolicy must be %s, %s or %s, not %q", TargetNamespacePolicyIgnore, TargetNamespacePolicyReject, TargetNamespacePolicyMigrate, chart.Spec.TargetNamespacePolicy)
+}
+
+// ValidateFailurePolicyRetries checks that the chart's FailurePolicyRetries, if set, is not negative, as it is used
+// as the backoff limit of the job.
+func ValidateFailurePolicyRetries(chart *helmv1.HelmChart) error {
+	if chart.Spec.FailurePolicyRetries != nil && *chart.Spec.FailurePolicyRetries < 0 {
+		return fmt.Errorf("spec.failurePolicyRetries must not be negative, not %d", *chart.Spec.FailurePolicyRetries)
+	}
+	return nil
+}
+
+// setDeleteJobTTL sets the TTL of the delete job, so that it is removed even if the chart is removed without the
+// controller, which otherwise removes the job along with the chart's other objects once the job has succeeded.
+func setDeleteJobTTL(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	if chart.DeletionTimestamp == nil || opts.DeleteJobTTL <= 0 {
+		return
+	}
+	job.Spec.TTLSecondsAfterFinished = pointer.Int32Ptr(int32(opts.DeleteJobTTL.Seconds()))
+}
+
+// setJobSuspend creates the install job suspended, so that an external orchestrator can hold the install until a
+// gate, such as the readiness of a dependency, is satisfied, and then resume it by clearing the chart's JobSuspend.
+// Delete jobs are never suspended, so that the chart's finalizer is not held. Requires the SuspendJob feature gate
+// on clusters older than 1.22.
+func setJobSuspend(job *batch.Job, chart *helmv1.HelmChart) {
+	if !chart.Spec.JobSuspend || chart.DeletionTimestamp != nil {
+		return
+	}
+	job.Spec.Suspend = pointer.BoolPtr(true)
+}
+
+// ValidateNodeName checks that the chart's NodeName, if set, is a valid node name.
+func ValidateNodeName(chart *helmv1.HelmChart) error {
+	if chart.Spec.NodeName == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(chart.Spec.NodeName); len(errs) > 0 {
+		return fmt.Errorf("spec.nodeName %q is invalid: %s", chart.Spec.NodeName, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func roleBinding(chart *helmv1.HelmChart) *rbac.ClusterRoleBinding {
+	return &rbac.ClusterRoleBinding{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name: ClusterRoleBindingName(chart),
+		},
+		RoleRef: rbac.RoleRef{
+			Kind:     "ClusterRole",
+			APIGroup: "rbac.authorization.k8s.io",
+			Name:     "cluster-admin",
+		},
+		Subjects: []rbac.Subject{
+			{
+				Name:      ServiceAccountName(chart),
+				Kind:      "ServiceAccount",
+				Namespace: chart.Namespace,
+			},
+		},
+	}
+}
+
+func serviceAccount(chart *helmv1.HelmChart) *core.ServiceAccount {
+	return &core.ServiceAccount{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      ServiceAccountName(chart),
+			Namespace: chart.Namespace,
+		},
+		AutomountServiceAccountToken: pointer.BoolPtr(true),
+	}
+}
+
+func args(chart *helmv1.HelmChart) []string {
+	if chart.DeletionTimestamp != nil {
+		args := []string{
+			"delete",
+		}
+		if chart.Spec.DisableHooks {
+			args = append(args, "--no-hooks")
+		}
+		// waits for the resources of the release to be deleted; requires helm 3.7 or later in the job image
+		if chart.Spec.UninstallWait {
+			args = append(args, "--wait")
+		}
+		return args
+	}
+
+	spec := chart.Spec
+	args := []string{
+		"install",
+	}
+	if spec.DisableHooks {
+		args = append(args, "--no-hooks")
+	}
+	if spec.TargetNamespace != "" {
+		args = append(args, "--namespace", spec.TargetNamespace)
+	}
+	if spec.CreateNamespace {
+		args = append(args, "--create-namespace")
+	}
+	// re-creates resources that cannot be patched, such as Services whose immutable fields have changed
+	if spec.Force {
+		args = append(args, "--force")
+	}
+	if spec.Repo != "" && !IsArtifactURL(spec.Repo) && !fetched(chart) {
+		args = append(args, "--repo", spec.Repo)
+	}
+	if spec.Version != "" {
+		args = append(args, "--version", spec.Version)
+	}
+	if spec.Devel {
+		args = append(args, "--devel")
+	}
+	// recorded in the release history, and shown by helm history
+	if spec.Description != "" {
+		args = append(args, "--description", spec.Description)
+	}
+
+	for _, k := range keys(spec.Set) {
+		val := spec.Set[k]
+		if typedVal(val) {
+			args = append(args, "--set", fmt.Sprintf("%s=%s", k, val.String()))
+		} else {
+			args = append(args, "--set-string", fmt.Sprintf("%s=%s", k, commaRE.ReplaceAllStringFunc(val.String(), escapeComma)))
+		}
+	}
+	for _, k := range jsonKeys(spec.SetJSON) {
+		args = append(args, "--set-json", fmt.Sprintf("%s=%s", k, spec.SetJSON[k]))
+	}
+	args = append(args, fieldRefArgs(chart)...)
+
+	return args
+}
+
+func keys(val map[string]intstr.IntOrString) []string {
+	var keys []string
+	for k := range val {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typedVal is a modified version of helm's typedVal function that operates on kubernetes IntOrString types.
+// Things that look like an integer, boolean, or null should use --set; everything else should use --set-string.
+// Ref: https://github.com/helm/helm/blob/v3.5.4/pkg/strvals/parser.go#L415
+func typedVal(val intstr.IntOrString) bool {
+	if intstr.Int == val.Type {
+		return true
+	}
+	switch strings.ToLower(val.StrVal) {
+	case "true", "false", "null":
+		return true
+	default:
+		return false
+	}
+}
+
+// escapeComma should be passed a string consisting of zero or more backslashes, followed by a comma.
+// If there are an even number of characters (such as `\,` or `\\\,`) then the comma is escaped.
+// If there are an uneven number of characters (such as `,` or `\\,` then the comma is not escaped,
+// and we need to escape it by adding an additional backslash.
+// This logic is difficult if not impossible to accomplish with a simple regex submatch replace.
+func escapeComma(match string) string {
+	if len(match)%2 == 1 {
+		match = `\` + match
+	}
+	return match
+}
+
+// setProxyEnv passes the proxy configuration to the job. If the chart references a ProxySecret,
+// the proxy env vars are sourced from keys of the same name in the Secret, and the controller's
+// own proxy configuration is not passed to the job.
+func setProxyEnv(job *batch.Job, chart *helmv1.HelmChart) {
+	proxySysEnv := []string{
+		"all_proxy",
+		"ALL_PROXY",
+		"http_proxy",
+		"HTTP_PROXY",
+		"https_proxy",
+		"HTTPS_PROXY",
+		"no_proxy",
+		"NO_PROXY",
+	}
+
+	if chart.Spec.ProxySecret != nil && chart.Spec.ProxySecret.Name != "" {
+		for _, proxyEnv := range proxySysEnv {
+			job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
+				Name: proxyEnv,
+				ValueFrom: &core.EnvVarSource{
+					SecretKeyRef: &core.SecretKeySelector{
+						LocalObjectReference: *chart.Spec.ProxySecret,
+						Key:                  proxyEnv,
+						Optional:             pointer.BoolPtr(true),
+					},
+				},
+			})
+		}
+		return
+	}
+
+	for _, proxyEnv := range proxySysEnv {
+		proxyEnvValue := os.Getenv(proxyEnv)
+		if len(proxyEnvValue) == 0 {
+			continue
+		}
+		envar := core.EnvVar{
+			Name:  proxyEnv,
+			Value: proxyEnvValue,
+		}
+		job.Spec.Template.Spec.Containers[0].Env = append(
+			job.Spec.Template.Spec.Containers[0].Env,
+			envar)
+	}
+}
+
+func contentConfigMap(chart *helmv1.HelmChart) *core.ConfigMap {
+	configMap := &core.ConfigMap{
+		TypeMeta: meta.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      contentConfigMapName(chart),
+			Namespace: chart.Namespace,
+		},
+		Data: map[string]string{},
+	}
+
+	if chart.Spec.ChartContent != "" {
+		key := fmt.Sprintf("%s.tgz.base64", ReleaseName(chart))
+		configMap.Data[key] = chart.Spec.ChartContent
+	}
+
+	return configMap
+}
+
+// chartContentSecret returns the Secret holding the chart content, if the chart references one
+// and the Secret can be retrieved. The Secret is expected to hold the chart archive under the
+// same key as the content ConfigMap.
+func chartContentSecret(chart *helmv1.HelmChart, opts Options) (*core.Secret, error) {
+	if chart.Spec.ChartContentSecret == nil || chart.Spec.ChartContentSecret.Name == "" || opts.SecretGetter == nil || chart.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	secret, err := opts.SecretGetter(chart.Namespace, chart.Spec.ChartContentSecret.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chart content secret %s/%s: %w", chart.Namespace, chart.Spec.ChartContentSecret.Name, err)
+	}
+	return secret, nil
+}
+
+func setValuesConfigMap(job *batch.Job, chart *helmv1.HelmChart) *core.ConfigMap {
+	configMap := valuesConfigMap(chart)
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name: "values",
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{
+				LocalObjectReference: core.LocalObjectReference{
+					Name: configMap.Name,
+				},
+			},
+		},
+	})
+
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
+		MountPath: "/config",
+		Name:      "values",
+	})
+
+	return configMap
+}
+
+func setContentConfigMap(job *batch.Job, chart *helmv1.HelmChart) *core.ConfigMap {
+	configMap := contentConfigMap(chart)
+	if configMap == nil {
+		return nil
+	}
+
+	volumeSource := core.VolumeSource{
+		ConfigMap: &core.ConfigMapVolumeSource{
+			LocalObjectReference: core.LocalObjectReference{
+				Name: configMap.Name,
+			},
+		},
+	}
+	if secret := chart.Spec.ChartContentSecret; secret != nil && secret.Name != "" {
+		volumeSource = core.VolumeSource{
+			Secret: &core.SecretVolumeSource{
+				SecretName: secret.Name,
+			},
+		}
+		// the chart is not needed to uninstall the release, and the secret may already have been deleted
+		if chart.DeletionTimestamp != nil {
+			volumeSource.Secret.Optional = pointer.BoolPtr(true)
+		}
+	}
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name:         "content",
+		VolumeSource: volumeSource,
+	})
+
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
+		MountPath: "/chart",
+		Name:      "content",
+	})
+
+	return configMap
+}
+
+func setSidecarAnnotations(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	disable := opts.DisableSidecars
+	if chart.Spec.DisableSidecars != nil {
+		disable = *chart.Spec.DisableSidecars
+	}
+	if !disable {
+		return
+	}
+	for k, v := range SidecarAnnotations {
+		job.Spec.Template.Annotations[k] = v
+	}
+}
+
+// setSafeToEvict tells the cluster autoscaler whether the job pod may be evicted when scaling down its node. Job
+// pods use emptyDir volumes, which the autoscaler otherwise treats as blocking scale-down. Setting it to false
+// keeps in-flight installs from being interrupted; setting it to true lets nodes running long jobs scale down.
+func setSafeToEvict(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	safe := opts.SafeToEvict
+	if chart.Spec.SafeToEvict != nil {
+		safe = chart.Spec.SafeToEvict
+	}
+	if safe == nil {
+		return
+	}
+	job.Spec.Template.Annotations[SafeToEvictAnnotation] = strconv.FormatBool(*safe)
+}
+
+// setSecurityContext runs the job's containers, including its init containers, as the unprivileged klipper-helm
+// user, with a read-only root filesystem and all capabilities dropped. Helm writes its cache, config and temporary
+// files to an emptyDir mounted over the home and temp directories instead. Job images that need to run as root, or
+// to write elsewhere, must set DisableSecurityContext.
+func setSecurityContext(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	disable := opts.DisableSecurityContext
+	if chart.Spec.DisableSecurityContext != nil {
+		disable = *chart.Spec.DisableSecurityContext
+	}
+	if disable {
+		return
+	}
+
+	spec := &job.Spec.Template.Spec
+	for i := range spec.InitContainers {
+		setContainerSecurityContext(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		setContainerSecurityContext(&spec.Containers[i])
+	}
+	spec.Volumes = append(spec.Volumes, core.Volume{
+		Name: "scratch",
+		VolumeSource: core.VolumeSource{
+			EmptyDir: &core.EmptyDirVolumeSource{},
+		},
+	})
+}
+
+func setContainerSecurityContext(container *core.Container) {
+	container.SecurityContext = &core.SecurityContext{
+		RunAsUser:                pointer.Int64Ptr(jobUser),
+		RunAsGroup:               pointer.Int64Ptr(jobUser),
+		RunAsNonRoot:             pointer.BoolPtr(true),
+		ReadOnlyRootFilesystem:   pointer.BoolPtr(true),
+		AllowPrivilegeEscalation: pointer.BoolPtr(false),
+		Capabilities: &core.Capabilities{
+			Drop: []core.Capability{"ALL"},
+		},
+	}
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+		Name:      "scratch",
+		MountPath: jobHome,
+		SubPath:   "home",
+	}, core.VolumeMount{
+		Name:      "scratch",
+		MountPath: "/tmp",
+		SubPath:   "tmp",
+	})
+}
+
+// mirrorRepo returns a copy of the chart with its repo, and chart if it is a URL, rewritten to point at the
+// mirror configured for the longest matching upstream prefix. The chart is returned unmodified if no mirror matches.
+func mirrorRepo(chart *helmv1.HelmChart, opts Options) *helmv1.HelmChart {
+	repo, repoMirrored := mirrorURL(chart.Spec.Repo, opts.RepoMirrors)
+	chartURL, chartMirrored := mirrorURL(chart.Spec.Chart, opts.RepoMirrors)
+	if !repoMirrored && !chartMirrored {
+		return chart
+	}
+
+	chart = chart.DeepCopy()
+	chart.Spec.Repo = repo
+	chart.Spec.Chart = chartURL
+	if chart.Spec.AuthSecret == nil && opts.RepoMirrorAuthSecret != "" {
+		chart.Spec.AuthSecret = &core.LocalObjectReference{Name: opts.RepoMirrorAuthSecret}
+	}
+	return chart
+}
+
+// mirrorURL replaces the longest upstream prefix of url found in mirrors with the corresponding mirror.
+func mirrorURL(url string, mirrors map[string]string) (string, bool) {
+	var upstream string
+	for prefix := range mirrors {
+		if len(prefix) > len(upstream) && strings.HasPrefix(url, prefix) {
+			upstream = prefix
+		}
+	}
+	if upstream == "" {
+		return url, false
+	}
+	return mirrors[upstream] + strings.TrimPrefix(url, upstream), true
+}
+
+// ValidateTimeout checks that the chart's Timeout, HelmTimeout and UninstallTimeout are positive and, if max is
+// non-zero, no longer than max, and that the HelmTimeout is no longer than the Timeout. Very long timeouts
+// effectively disable failure handling, as a hung job is not retried until it times out.
+func ValidateTimeout(chart *helmv1.HelmChart, max time.Duration) error {
+	if chart.Spec.Timeout != nil {
+		if timeout := chart.Spec.Timeout.Duration; timeout <= 0 {
+			return fmt.Errorf("spec.timeout must be positive, not %s", timeout)
+		} else if max > 0 && timeout > max {
+			return fmt.Errorf("spec.timeout %s exceeds the maximum of %s", timeout, max)
+		}
+	}
+	if chart.Spec.HelmTimeout != nil {
+		if timeout := chart.Spec.HelmTimeout.Duration; timeout <= 0 {
+			return fmt.Errorf("spec.helmTimeout must be positive, not %s", timeout)
+		} else if max > 0 && timeout > max {
+			return fmt.Errorf("spec.helmTimeout %s exceeds the maximum of %s", timeout, max)
+		} else if chart.Spec.Timeout != nil && timeout > chart.Spec.Timeout.Duration {
+			return fmt.Errorf("spec.helmTimeout %s exceeds spec.timeout %s", timeout, chart.Spec.Timeout.Duration)
+		}
+	}
+	if chart.Spec.UninstallTimeout != nil {
+		if timeout := chart.Spec.UninstallTimeout.Duration; timeout <= 0 {
+			return fmt.Errorf("spec.uninstallTimeout must be positive, not %s", timeout)
+		} else if max > 0 && timeout > max {
+			return fmt.Errorf("spec.uninstallTimeout %s exceeds the maximum of %s", timeout, max)
+		}
+	}
+	return nil
+}
+
+// helmTimeout returns the timeout passed to helm, which is the UninstallTimeout if set for the delete job, or
+// otherwise the HelmTimeout if set, or otherwise the Timeout.
+func helmTimeout(chart *helmv1.HelmChart) *meta.Duration {
+	if chart.DeletionTimestamp != nil && chart.Spec.UninstallTimeout != nil {
+		return chart.Spec.UninstallTimeout
+	}
+	if chart.Spec.HelmTimeout != nil {
+		return chart.Spec.HelmTimeout
+	}
+	return chart.Spec.Timeout
+}
+
+// activeDeadlineSeconds returns the active deadline for the job pod, so that a hung helm process is
+// terminated and the job retried under the failure policy. The reinstall failure policy may uninstall
+// and install again within a single run, so the deadline allows for two helm operations.
+func activeDeadlineSeconds(timeout time.Duration) *int64 {
+	return pointer.Int64Ptr(int64((2*timeout + ActiveDeadlineMargin).Seconds()))
+}
+
+// setUnschedulableToleration allows the job to be scheduled on cordoned nodes. Without this, cordoning the
+// only node in a single-node cluster for an upgrade blocks all chart jobs until the node is uncordoned.
+func setUnschedulableToleration(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	tolerate := opts.TolerateUnschedulable
+	if chart.Spec.TolerateUnschedulable != nil {
+		tolerate = *chart.Spec.TolerateUnschedulable
+	}
+	if !tolerate {
+		return
+	}
+	job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, core.Toleration{
+		Key:      core.TaintNodeUnschedulable,
+		Operator: core.TolerationOpExists,
+		Effect:   core.TaintEffectNoSchedule,
+	})
+}
+
+// setJobPlacement adds the default node selector and tolerations, followed by those set by the chart, to the job,
+// so that fleet-wide scheduling policy, such as confining jobs to infra nodes, applies to every chart. Bootstrap
+// charts must run on control-plane nodes before the cluster is ready, so they only get the chart's own settings.
+func setJobPlacement(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	if !chart.Spec.Bootstrap {
+		for k, v := range opts.NodeSelector {
+			job.Spec.Template.Spec.NodeSelector[k] = v
+		}
+		job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, opts.Tolerations...)
+	}
+	for k, v := range chart.Spec.JobNodeSelector {
+		job.Spec.Template.Spec.NodeSelector[k] = v
+	}
+	job.Spec.Template.Spec.Tolerations = append(job.Spec.Template.Spec.Tolerations, chart.Spec.JobTolerations...)
+	// bypasses the scheduler; the kubelet still rejects the pod if the node does not match the node selector
+	job.Spec.Template.Spec.NodeName = chart.Spec.NodeName
+}
+
+// ParseToleration parses a toleration in the same key[=value][:effect] format that kubectl uses for taints.
+// Tolerations without a value tolerate any value of the key, and tolerations without an effect tolerate all effects.
+func ParseToleration(s string) (core.Toleration, error) {
+	toleration := core.Toleration{Operator: core.TolerationOpExists}
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		toleration.Effect = core.TaintEffect(s[i+1:])
+		s = s[:i]
+		switch toleration.Effect {
+		case core.TaintEffectNoSchedule, core.TaintEffectPreferNoSchedule, core.TaintEffectNoExecute:
+		default:
+			return toleration, fmt.Errorf("invalid toleration effect %q: must be %s, %s or %s", toleration.Effect,
+				core.TaintEffectNoSchedule, core.TaintEffectPreferNoSchedule, core.TaintEffectNoExecute)
+		}
+	}
+	if i := strings.Index(s, "="); i >= 0 {
+		toleration.Operator = core.TolerationOpEqual
+		toleration.Value = s[i+1:]
+		s = s[:i]
+	}
+	if errs := validation.IsQualifiedName(s); len(errs) > 0 {
+		return toleration, fmt.Errorf("invalid toleration key %q: %s", s, strings.Join(errs, ", "))
+	}
+	toleration.Key = s
+	return toleration, nil
+}
+
+// CABundle returns the ConfigMap key holding the CA bundle used by the chart's jobs, or nil if the job uses
+// the CA bundle from its image. Charts may set a CABundle with an empty name to not use the default CA bundle.
+func CABundle(chart *helmv1.HelmChart, opts Options) *core.ConfigMapKeySelector {
+	caBundle := opts.CABundle
+	if chart.Spec.CABundle != nil {
+		caBundle = chart.Spec.CABundle
+	}
+	if caBundle == nil || caBundle.Name == "" {
+		return nil
+	}
+	return caBundle
+}
+
+// setCABundle mounts the chart's CA bundle into the job, and points SSL_CERT_FILE at it so that it is used
+// in place of the CA bundle from the job image. This allows charts to be pulled from repos with certificates
+// signed by private CAs without setting RepoCA on every chart. The bundle must also include any public CAs
+// that are needed, as it replaces those from the image. The bundle is not mounted into delete jobs, which do not
+// pull the chart, so that uninstalling is not blocked if the CA bundle ConfigMap has already been deleted.
+func setCABundle(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	caBundle := CABundle(chart, opts)
+	if caBundle == nil || chart.DeletionTimestamp != nil {
+		return
+	}
+
+	key := caBundle.Key
+	if key == "" {
+		key = DefaultCABundleKey
+	}
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name: "ca-bundle",
+		VolumeSource: core.VolumeSource{
+			ConfigMap: &core.ConfigMapVolumeSource{
+				LocalObjectReference: caBundle.LocalObjectReference,
+				Items:                []core.KeyToPath{{Key: key, Path: caBundleFile}},
+			},
+		},
+	})
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
+		MountPath: caBundlePath,
+		Name:      "ca-bundle",
+		ReadOnly:  true,
+	})
+	job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
+		Name:  "SSL_CERT_FILE",
+		Value: caBundlePath + "/" + caBundleFile,
+	})
+}
+
+// caBundleContent returns the content of the chart's CA bundle, so that it can be included in the config hash.
+// Nothing is returned for charts that are being deleted, as the bundle is not mounted into delete jobs.
+func caBundleContent(chart *helmv1.HelmChart, opts Options) (*core.ConfigMap, error) {
+	caBundle := CABundle(chart, opts)
+	if caBundle == nil || opts.ConfigMapGetter == nil || chart.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	configMap, err := opts.ConfigMapGetter(chart.Namespace, caBundle.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA bundle ConfigMap %s/%s: %w", chart.Namespace, caBundle.Name, err)
+	}
+	key := caBundle.Key
+	if key == "" {
+		key = DefaultCABundleKey
+	}
+	return &core.ConfigMap{Data: map[string]string{caBundleFile: configMap.Data[key]}}, nil
+}
+
+// setJobSpread adds a preferred pod anti-affinity and topology spread constraint against the pods of other
+// jobs in the chart's namespace, so that the jobs for many charts reconciled at once, such as after a controller
+// restart, are spread across nodes instead of all landing on the same one. Both are only preferences, so jobs
+// are still scheduled when there are fewer nodes than jobs.
+func setJobSpread(job *batch.Job, chart *helmv1.HelmChart, opts Options) {
+	spread := opts.SpreadJobs
+	if chart.Spec.SpreadJobs != nil {
+		spread = *chart.Spec.SpreadJobs
+	}
+	if !spread {
+		return
+	}
+
+	selector := &meta.LabelSelector{
+		MatchExpressions: []meta.LabelSelectorRequirement{
+			{
+				Key:      Label,
+				Operator: meta.LabelSelectorOpExists,
+			},
+		},
+	}
+	job.Spec.Template.Spec.Affinity = &core.Affinity{
+		PodAntiAffinity: &core.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []core.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: core.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   core.LabelHostname,
+					},
+				},
+			},
+		},
+	}
+	job.Spec.Template.Spec.TopologySpreadConstraints = []core.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       core.LabelHostname,
+			WhenUnsatisfiable: core.ScheduleAnyway,
+			LabelSelector:     selector,
+		},
+	}
+}
+
+// setAuthSecret passes the repo credentials from the chart's AuthSecret to the job.
+// By default the credentials are referenced from env vars; when the file mount mode
+// is selected the secret is instead mounted at /auth so that the credentials do not
+// show up in the pod's environment.
+func setAuthSecret(job *batch.Job, chart *helmv1.HelmChart) {
+	if chart.Spec.AuthSecret == nil || chart.Spec.AuthSecret.Name == "" {
+		return
+	}
+
+	if chart.Spec.CredentialsMountMode == CredentialsMountModeFile {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+			Name: "auth",
+			VolumeSource: core.VolumeSource{
+				Secret: &core.SecretVolumeSource{
+					SecretName: chart.Spec.AuthSecret.Name,
+				},
+			},
+		})
+		job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
+			MountPath: "/auth",
+			Name:      "auth",
+			ReadOnly:  true,
+		})
+		return
+	}
+
+	job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, []core.EnvVar{
+		{
+			Name: "AUTH_USERNAME",
+			ValueFrom: &core.EnvVarSource{
+				SecretKeyRef: &core.SecretKeySelector{
+					LocalObjectReference: *chart.Spec.AuthSecret,
+					Key:                  core.BasicAuthUsernameKey,
+				},
+			},
+		},
+		{
+			Name: "AUTH_PASSWORD",
+			ValueFrom: &core.EnvVarSource{
+				SecretKeyRef: &core.SecretKeySelector{
+					LocalObjectReference: *chart.Spec.AuthSecret,
+					Key:                  core.BasicAuthPasswordKey,
+				},
+			},
+		},
+	}...)
+}
+
+// setGeneratedMetadata adds the controller's common labels, and the labels and annotations requested by the chart,
+// to all generated objects and the job's pod template, so that admission policies and cost tools can attribute them.
+// Labels and annotations set by the controller itself are never overridden.
+func setGeneratedMetadata(objs *objectset.ObjectSet, chart *helmv1.HelmChart, opts Options) error {
+	labels := map[string]string{}
+	for k, v := range opts.CommonLabels {
+		labels[k] = v
+	}
+	for k, v := range chart.Spec.GeneratedLabels {
+		labels[k] = v
+	}
+	annotations := chart.Spec.GeneratedAnnotations
+
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+
+	for _, obj := range objs.All() {
+		metadata, err := apimeta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		metadata.SetLabels(mergeMissing(metadata.GetLabels(), labels))
+		metadata.SetAnnotations(mergeMissing(metadata.GetAnnotations(), annotations))
+
+		if job, ok := obj.(*batch.Job); ok {
+			job.Spec.Template.Labels = mergeMissing(job.Spec.Template.Labels, labels)
+			job.Spec.Template.Annotations = mergeMissing(job.Spec.Template.Annotations, annotations)
+		}
+	}
+
+	return nil
+}
+
+// setOwnerReferences makes the chart the owner of the objects rendered in its namespace, so that they are removed
+// by garbage collection when the chart is deleted, even if the apply bookkeeping that normally removes them has
+// been lost. Objects rendered for a chart that is being deleted, such as the delete job, are not owned by it, as
+// foreground deletion of the chart would otherwise delete them before they have run. For the same reason, the
+// ServiceAccount, ConfigMaps and Secrets that the job uses are never owned, as the delete job uses them too.
+func setOwnerReferences(objs *objectset.ObjectSet, chart *helmv1.HelmChart) error {
+	if chart.UID == "" || chart.DeletionTimestamp != nil {
+		return nil
+	}
+	used := map[string]bool{}
+	for _, obj := range objs.All() {
+		if job, ok := obj.(*batch.Job); ok {
+			used = jobDependencies(job)
+		}
+	}
+
+	owner := meta.OwnerReference{
+		APIVersion: helmv1.SchemeGroupVersion.String(),
+		Kind:       "HelmChart",
+		Name:       chart.Name,
+		UID:        chart.UID,
+		Controller: pointer.BoolPtr(true),
+	}
+	for _, obj := range objs.All() {
+		metadata, err := apimeta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		// owners of namespaced objects must be in the same namespace, and cluster-scoped objects
+		// cannot be owned by namespaced objects
+		if metadata.GetNamespace() != chart.Namespace {
+			continue
+		}
+		if used[obj.GetObjectKind().GroupVersionKind().Kind+"/"+metadata.GetName()] {
+			continue
+		}
+		metadata.SetOwnerReferences([]meta.OwnerReference{owner})
+	}
+	return nil
+}
+
+// jobDependencies returns the kind and name of the ServiceAccount, ConfigMaps and Secrets that the job's pods use.
+func jobDependencies(job *batch.Job) map[string]bool {
+	used := map[string]bool{}
+	if name := job.Spec.Template.Spec.ServiceAccountName; name != "" {
+		used["ServiceAccount/"+name] = true
+	}
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.ConfigMap != nil {
+			used["ConfigMap/"+volume.ConfigMap.Name] = true
+		}
+		if volume.Secret != nil {
+			used["Secret/"+volume.Secret.SecretName] = true
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.ConfigMap != nil {
+				used["ConfigMap/"+source.ConfigMap.Name] = true
+			}
+			if source.Secret != nil {
+				used["Secret/"+source.Secret.Name] = true
+			}
+		}
+	}
+	return used
+}
+
+// mergeMissing adds the entries from src that are not already set in dst.
+func mergeMissing(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+func setFailurePolicy(job *batch.Job, failurePolicy string) {
+	job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env, core.EnvVar{
+		Name:  "FAILURE_POLICY",
+		Value: failurePolicy,
+	})
+}
+
+// hashConfigMaps annotates the job's pod template with a hash of the content of the ConfigMaps. The content is
+// written in a canonical form, with keys sorted and every key and value length-prefixed, so that the hash does not
+// depend on map iteration order and different content cannot produce the same input. The hash is tagged with
+// ConfigHashVersion, so that any future change to the scheme is distinguishable from a change to the content.
+// Jobs created with the older, untagged hash never match, so every chart is re-run once when the controller is
+// upgraded from a version that recorded it.
+func hashConfigMaps(job *batch.Job, maps ...*core.ConfigMap) {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "%d:", len(maps))
+	for _, configMap := range maps {
+		fmt.Fprintf(hash, "%d:%d:", len(configMap.Data), len(configMap.BinaryData))
+		writeConfigMapContent(hash, configMap)
+	}
+
+	job.Spec.Template.ObjectMeta.Annotations[Annotation] = fmt.Sprintf("%s:SHA256=%X", ConfigHashVersion, hash.Sum(nil))
+}
+
+// writeConfigMapContent writes the ConfigMap's Data and BinaryData, sorted by key, with each key and value
+// prefixed by its length.
+func writeConfigMapContent(w io.Writer, configMap *core.ConfigMap) {
+	keys := make([]string, 0, len(configMap.Data))
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%d:%s%d:%s", len(k), k, len(configMap.Data[k]), configMap.Data[k])
+	}
+
+	keys = keys[:0]
+	for k := range configMap.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%d:%s%d:", len(k), k, len(configMap.BinaryData[k]))
+		w.Write(configMap.BinaryData[k])
+	}
+}
+
+// setJobHash annotates the job with a hash of its spec. Whether the job is suspended is not included, so that
+// the job is resumed in place, instead of being replaced, when its chart's JobSuspend is cleared. The pod failure
+// policy, which is carried on an annotation until it is copied into the spec, is included.
+func setJobHash(job *batch.Job) error {
+	jobSpec := job.Spec
+	jobSpec.Suspend = nil
+	spec, err := json.Marshal(jobSpec)
+	if err != nil {
+		return err
+	}
+	if policy := job.Annotations[PodFailurePolicyAnnotation]; policy != "" {
+		spec = append(spec, policy...)
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[JobHashAnnotation] = fmt.Sprintf("SHA256=%X", sha256.Sum256(spec))
+	return nil
+}
+
+// setConfigMapRevisions suffixes the ConfigMap names with a hash of their content, and points the job's
+// volumes at the suffixed names. This ensures that a running job never sees the content of its mounted
+// ConfigMaps change when the chart is modified; a new revision is created and mounted by a new job instead.
+func setConfigMapRevisions(job *batch.Job, maps ...*core.ConfigMap) {
+	for _, configMap := range maps {
+		name := configMap.Name
+		configMap.Name = fmt.Sprintf("%s-%s", name, configMapRevision(configMap))
+		for _, volume := range job.Spec.Template.Spec.Volumes {
+			if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+				volume.ConfigMap.Name = configMap.Name
+			}
+		}
+	}
+}
+
+// configMapRevision returns a short hash of the ConfigMap's content, iterating keys in sorted order so
+// that the result is stable for a given set of data.
+func configMapRevision(configMap *core.ConfigMap) string {
+	hash := sha256.New()
+	writeConfigMapContent(hash, configMap)
+
+	return fmt.Sprintf("%x", hash.Sum(nil))[:10]
+}
@@ -0,0 +1,309 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+// setFilesPath is where the files for the chart's SetFiles are mounted in the job
+const setFilesPath = "/set-files"
+
+// maxSetIndex matches the largest list index that helm accepts in --set keys.
+// Ref: https://github.com/helm/helm/blob/v3.10.0/pkg/strvals/parser.go#L38
+const maxSetIndex = 65536
+
+// ValidateSet checks that the keys of the chart's Set, SetJSON, SetFiles and SetFrom values, and the SetJSON
+// values themselves, will be parsed by helm as written, that each of the SetFiles references a single ConfigMap
+// or Secret key, and that each of the SetFrom references a Secret key. Keys containing unescaped commas or equals
+// signs, or malformed list indexes, would otherwise produce --set args that helm fails to parse or that set
+// different values.
+func ValidateSet(chart *helmv1.HelmChart) error {
+	for _, k := range keys(chart.Spec.Set) {
+		if err := validateSetKey(k); err != nil {
+			return fmt.Errorf("spec.set key %q is invalid: %w", k, err)
+		}
+	}
+	for _, k := range jsonKeys(chart.Spec.SetJSON) {
+		if err := validateSetKey(k); err != nil {
+			return fmt.Errorf("spec.setJSON key %q is invalid: %w", k, err)
+		}
+		if !json.Valid([]byte(chart.Spec.SetJSON[k])) {
+			return fmt.Errorf("spec.setJSON value for key %q is not valid JSON", k)
+		}
+	}
+	for _, file := range chart.Spec.SetFiles {
+		if err := validateSetKey(file.Key); err != nil {
+			return fmt.Errorf("spec.setFiles key %q is invalid: %w", file.Key, err)
+		}
+		if (file.ConfigMapKeyRef == nil) == (file.SecretKeyRef == nil) {
+			return fmt.Errorf("spec.setFiles key %q must reference exactly one of configMapKeyRef or secretKeyRef", file.Key)
+		}
+		if ref := file.ConfigMapKeyRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+			return fmt.Errorf("spec.setFiles key %q configMapKeyRef must set name and key", file.Key)
+		}
+		if ref := file.SecretKeyRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+			return fmt.Errorf("spec.setFiles key %q secretKeyRef must set name and key", file.Key)
+		}
+	}
+	for _, from := range chart.Spec.SetFrom {
+		if err := validateSetKey(from.Key); err != nil {
+			return fmt.Errorf("spec.setFrom key %q is invalid: %w", from.Key, err)
+		}
+		if ref := from.ValueFrom.SecretKeyRef; ref == nil || ref.Name == "" || ref.Key == "" {
+			return fmt.Errorf("spec.setFrom key %q valueFrom.secretKeyRef must set name and key", from.Key)
+		}
+	}
+	return nil
+}
+
+// SetFiles returns the chart's SetFiles, followed by its SetFrom values as the equivalent SetFiles. Values are
+// read by helm from the mounted Secret key, so they are not included in the job's args or in the chart.
+func SetFiles(chart *helmv1.HelmChart) []helmv1.HelmChartSetFile {
+	if len(chart.Spec.SetFrom) == 0 {
+		return chart.Spec.SetFiles
+	}
+	files := append([]helmv1.HelmChartSetFile{}, chart.Spec.SetFiles...)
+	for _, from := range chart.Spec.SetFrom {
+		files = append(files, helmv1.HelmChartSetFile{Key: from.Key, SecretKeyRef: from.ValueFrom.SecretKeyRef})
+	}
+	return files
+}
+
+// setSetFiles mounts the ConfigMap and Secret keys referenced by the chart's SetFiles and SetFrom into the job,
+// and passes them to helm with --set-file. The files are projected into a single volume, named by their position
+// in SetFiles, so that keys do not need to be valid file names.
+func setSetFiles(job *batch.Job, chart *helmv1.HelmChart) {
+	files := SetFiles(chart)
+	if len(files) == 0 || chart.DeletionTimestamp != nil {
+		return
+	}
+
+	var sources []core.VolumeProjection
+	for i, file := range files {
+		name := strconv.Itoa(i)
+		switch {
+		case file.ConfigMapKeyRef != nil:
+			sources = append(sources, core.VolumeProjection{
+				ConfigMap: &core.ConfigMapProjection{
+					LocalObjectReference: file.ConfigMapKeyRef.LocalObjectReference,
+					Items:                []core.KeyToPath{{Key: file.ConfigMapKeyRef.Key, Path: name}},
+				},
+			})
+		case file.SecretKeyRef != nil:
+			sources = append(sources, core.VolumeProjection{
+				Secret: &core.SecretProjection{
+					LocalObjectReference: file.SecretKeyRef.LocalObjectReference,
+					Items:                []core.KeyToPath{{Key: file.SecretKeyRef.Key, Path: name}},
+				},
+			})
+		default:
+			continue
+		}
+		job.Spec.Template.Spec.Containers[0].Args = append(job.Spec.Template.Spec.Containers[0].Args,
+			"--set-file", fmt.Sprintf("%s=%s", file.Key, path.Join(setFilesPath, name)))
+	}
+
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name: "set-files",
+		VolumeSource: core.VolumeSource{
+			Projected: &core.ProjectedVolumeSource{
+				Sources: sources,
+			},
+		},
+	})
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, core.VolumeMount{
+		MountPath: setFilesPath,
+		Name:      "set-files",
+		ReadOnly:  true,
+	})
+}
+
+// setFilesContent returns the content of the ConfigMap and Secret keys referenced by the chart's SetFiles and
+// SetFrom, so that it can be included in the config hash. Keys are skipped if there is no getter for their type.
+// Nothing is returned for charts that are being deleted, as the delete job does not use values, and the referenced
+// ConfigMaps and Secrets may already have been deleted.
+func setFilesContent(chart *helmv1.HelmChart, opts Options) (*core.ConfigMap, error) {
+	content := &core.ConfigMap{BinaryData: map[string][]byte{}}
+	if chart.DeletionTimestamp != nil {
+		return content, nil
+	}
+	for _, file := range SetFiles(chart) {
+		switch {
+		case file.ConfigMapKeyRef != nil && opts.ConfigMapGetter != nil:
+			configMap, err := opts.ConfigMapGetter(chart.Namespace, file.ConfigMapKeyRef.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ConfigMap %s/%s for spec.setFiles key %s: %w", chart.Namespace, file.ConfigMapKeyRef.Name, file.Key, err)
+			}
+			content.BinaryData[file.Key] = []byte(configMap.Data[file.ConfigMapKeyRef.Key])
+			if data, ok := configMap.BinaryData[file.ConfigMapKeyRef.Key]; ok {
+				content.BinaryData[file.Key] = data
+			}
+		case file.SecretKeyRef != nil && opts.SecretGetter != nil:
+			secret, err := opts.SecretGetter(chart.Namespace, file.SecretKeyRef.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Secret %s/%s for spec.setFiles key %s: %w", chart.Namespace, file.SecretKeyRef.Name, file.Key, err)
+			}
+			content.BinaryData[file.Key] = secret.Data[file.SecretKeyRef.Key]
+		}
+	}
+	return content, nil
+}
+
+// SetOverrides returns the keys of the chart's Set, SetJSON, SetFiles and SetFrom values that are also set by the
+// values content of the chart or its config, each prefixed with the field it is set by. Helm applies --set values
+// after values files, so these keys silently take precedence over the values content. Keys are not returned if
+// the values content cannot be parsed, as that is reported by ValidateValuesContent.
+func SetOverrides(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) []string {
+	values, err := MergeValues(chart, config, nil)
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+
+	var overrides []string
+	add := func(field string, keys []string) {
+		for _, k := range keys {
+			if hasValue(values, splitSetKey(k)) {
+				overrides = append(overrides, fmt.Sprintf("%s key %q", field, k))
+			}
+		}
+	}
+	add("spec.set", keys(chart.Spec.Set))
+	add("spec.setJSON", jsonKeys(chart.Spec.SetJSON))
+	for _, file := range chart.Spec.SetFiles {
+		add("spec.setFiles", []string{file.Key})
+	}
+	for _, from := range chart.Spec.SetFrom {
+		add("spec.setFrom", []string{from.Key})
+	}
+	return overrides
+}
+
+// splitSetKey splits a key that has passed validateSetKey into its unescaped names, as strings, and list indexes,
+// as ints.
+func splitSetKey(key string) []interface{} {
+	var path []interface{}
+	var name []byte
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '\\':
+			i++
+			name = append(name, key[i])
+		case '.':
+			if name != nil {
+				path = append(path, string(name))
+			}
+			name = nil
+		case '[':
+			if name != nil {
+				path = append(path, string(name))
+			}
+			name = nil
+			end := i + 1
+			for end < len(key) && key[end] != ']' {
+				end++
+			}
+			index, _ := strconv.Atoi(key[i+1 : end])
+			path = append(path, index)
+			i = end
+		default:
+			name = append(name, c)
+		}
+	}
+	if name != nil {
+		path = append(path, string(name))
+	}
+	return path
+}
+
+// hasValue returns true if values holds a value at the path returned by splitSetKey.
+func hasValue(values interface{}, path []interface{}) bool {
+	for _, elem := range path {
+		switch elem := elem.(type) {
+		case string:
+			m, ok := values.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			if values, ok = m[elem]; !ok {
+				return false
+			}
+		case int:
+			list, ok := values.([]interface{})
+			if !ok || elem >= len(list) {
+				return false
+			}
+			values = list[elem]
+		}
+	}
+	return true
+}
+
+// validateSetKey checks a key using the same rules as helm's strvals parser. Keys are made up of names separated
+// by dots, each optionally followed by one or more list indexes in brackets. Any character, including dots, commas,
+// equals signs and brackets, may be included in a name by escaping it with a backslash.
+func validateSetKey(key string) error {
+	// name is the length of the current name; indexed is set if the current name is followed by a list index
+	name, indexed := 0, false
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '\\':
+			if i+1 == len(key) {
+				return fmt.Errorf("ends with an unescaped backslash")
+			}
+			if indexed {
+				return fmt.Errorf("unexpected character after list index at position %d", i)
+			}
+			i++
+			name++
+		case ',', '=', ']':
+			return fmt.Errorf("unescaped %q at position %d; escape it with a backslash", c, i)
+		case '.':
+			if name == 0 {
+				return fmt.Errorf("empty name at position %d", i)
+			}
+			name, indexed = 0, false
+		case '[':
+			if name == 0 {
+				return fmt.Errorf("list index at position %d does not follow a name", i)
+			}
+			end := i + 1
+			for end < len(key) && key[end] != ']' {
+				end++
+			}
+			if end == len(key) {
+				return fmt.Errorf("unterminated list index at position %d", i)
+			}
+			index, err := strconv.Atoi(key[i+1 : end])
+			if err != nil || index < 0 || index > maxSetIndex {
+				return fmt.Errorf("list index %q at position %d must be a number between 0 and %d", key[i+1:end], i, maxSetIndex)
+			}
+			i = end
+			indexed = true
+		default:
+			if indexed {
+				return fmt.Errorf("unexpected character after list index at position %d", i)
+			}
+			name++
+		}
+	}
+	if name == 0 {
+		return fmt.Errorf("empty name at end of key")
+	}
+	return nil
+}
+
+func jsonKeys(val map[string]string) []string {
+	var keys []string
+	for k := range val {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
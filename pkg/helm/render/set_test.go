@@ -0,0 +1,199 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestValidateSetKey(t *testing.T) {
+	assert := assert.New(t)
+	tests := map[string]bool{
+		"rbac.enabled":                   true,
+		"ingress.hosts[0]":               true,
+		"ingress.hosts[0].paths[1][2]":   true,
+		`nodeSelector.kubernetes\.io/os`: true,
+		`annotations.a\,b\=c`:            true,
+		"":                               false,
+		"rbac..enabled":                  false,
+		"rbac.":                          false,
+		".rbac":                          false,
+		"a,b":                            false,
+		"a=b":                            false,
+		"a]":                             false,
+		"[0]":                            false,
+		"hosts[]":                        false,
+		"hosts[-1]":                      false,
+		"hosts[x]":                       false,
+		"hosts[65537]":                   false,
+		"hosts[0":                        false,
+		"hosts[0]name":                   false,
+		`trailing\`:                      false,
+	}
+	for key, valid := range tests {
+		err := validateSetKey(key)
+		assert.Equal(valid, err == nil, "expected validateSetKey(%q) valid = %t, got %v", key, valid, err)
+	}
+}
+
+func TestValidateSet(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidateSet(chart))
+
+	chart.Spec.SetJSON = map[string]string{"podAnnotations": `{"a.b/c": "d,e"}`}
+	assert.NoError(ValidateSet(chart))
+
+	chart.Spec.SetJSON["tolerations"] = "[{"
+	assert.EqualError(ValidateSet(chart), `spec.setJSON value for key "tolerations" is not valid JSON`)
+
+	chart.Spec.Set["a,b"] = intstr.FromString("c")
+	assert.Error(ValidateSet(chart))
+}
+
+func TestValidateSetFiles(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.SetFiles = []v1.HelmChartSetFile{
+		{
+			Key:          "tls.crt",
+			SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "tls"}, Key: "tls.crt"},
+		},
+	}
+	assert.NoError(ValidateSet(chart))
+
+	chart.Spec.SetFiles[0].ConfigMapKeyRef = &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "tls"}, Key: "tls.crt"}
+	assert.Error(ValidateSet(chart))
+
+	chart.Spec.SetFiles[0].SecretKeyRef = nil
+	chart.Spec.SetFiles[0].ConfigMapKeyRef.Key = ""
+	assert.Error(ValidateSet(chart))
+}
+
+func TestSetFiles(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Set = nil
+	chart.Spec.SetFiles = []v1.HelmChartSetFile{
+		{
+			Key:             "scripts.init",
+			ConfigMapKeyRef: &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "scripts"}, Key: "init.sh"},
+		},
+		{
+			Key:          "tls.crt",
+			SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "tls"}, Key: "tls.crt"},
+		},
+	}
+	configMap := &core.ConfigMap{Data: map[string]string{"init.sh": "#!/bin/sh"}}
+	opts := Options{
+		ConfigMapGetter: func(namespace, name string) (*core.ConfigMap, error) { return configMap, nil },
+		SecretGetter: func(namespace, name string) (*core.Secret, error) {
+			return &core.Secret{Data: map[string][]byte{"tls.crt": []byte("certificate")}}, nil
+		},
+	}
+
+	objs, err := Objects(chart, nil, opts)
+	assert.NoError(err)
+	installJob := objs.All()[len(objs.All())-1].(*batch.Job)
+	assert.Equal([]string{"install", "--set-file", "scripts.init=/set-files/0", "--set-file", "tls.crt=/set-files/1"}, installJob.Spec.Template.Spec.Containers[0].Args)
+
+	var volume *core.Volume
+	for i := range installJob.Spec.Template.Spec.Volumes {
+		if installJob.Spec.Template.Spec.Volumes[i].Name == "set-files" {
+			volume = &installJob.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	if assert.NotNil(volume) {
+		sources := volume.Projected.Sources
+		assert.Len(sources, 2)
+		assert.Equal("scripts", sources[0].ConfigMap.Name)
+		assert.Equal([]core.KeyToPath{{Key: "init.sh", Path: "0"}}, sources[0].ConfigMap.Items)
+		assert.Equal("tls", sources[1].Secret.Name)
+		assert.Equal([]core.KeyToPath{{Key: "tls.crt", Path: "1"}}, sources[1].Secret.Items)
+	}
+
+	// changes to the referenced content result in a new config hash
+	hash := installJob.Spec.Template.Annotations[Annotation]
+	configMap.Data["init.sh"] = "#!/bin/bash"
+	objs, err = Objects(chart, nil, opts)
+	assert.NoError(err)
+	assert.NotEqual(hash, objs.All()[len(objs.All())-1].(*batch.Job).Spec.Template.Annotations[Annotation])
+
+	// the referenced content is not needed to uninstall the chart, and may already be gone
+	opts.ConfigMapGetter = func(namespace, name string) (*core.ConfigMap, error) {
+		return nil, errors.NewNotFound(core.Resource("configmaps"), name)
+	}
+	_, err = Objects(chart, nil, opts)
+	assert.Error(err)
+	deleteTime := meta.NewTime(time.Now())
+	chart.DeletionTimestamp = &deleteTime
+	_, err = Objects(chart, nil, opts)
+	assert.NoError(err)
+}
+
+func TestSetFrom(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Set = nil
+	chart.Spec.SetFiles = []v1.HelmChartSetFile{
+		{
+			Key:             "scripts.init",
+			ConfigMapKeyRef: &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "scripts"}, Key: "init.sh"},
+		},
+	}
+	chart.Spec.SetFrom = []v1.HelmChartSetFrom{
+		{
+			Key:       "auth.password",
+			ValueFrom: v1.HelmChartSetValueSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "db"}, Key: "password"}},
+		},
+	}
+	assert.NoError(ValidateSet(chart))
+
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.Equal([]string{"install", "--set-file", "scripts.init=/set-files/0", "--set-file", "auth.password=/set-files/1"}, installJob.Spec.Template.Spec.Containers[0].Args)
+	sources := jobVolume(installJob, "set-files").Projected.Sources
+	if assert.Len(sources, 2) {
+		assert.Equal("db", sources[1].Secret.Name)
+		assert.Equal([]core.KeyToPath{{Key: "password", Path: "1"}}, sources[1].Secret.Items)
+	}
+	assert.Len(chart.Spec.SetFiles, 1)
+
+	chart.Spec.SetFrom[0].ValueFrom.SecretKeyRef.Key = ""
+	assert.EqualError(ValidateSet(chart), `spec.setFrom key "auth.password" valueFrom.secretKeyRef must set name and key`)
+	chart.Spec.SetFrom[0].ValueFrom.SecretKeyRef = nil
+	assert.Error(ValidateSet(chart))
+}
+
+func TestSetOverrides(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.ValuesContent = "image:\n  tag: v1\ningress:\n  hosts:\n  - name: a\nlabels:\n  app.kubernetes.io/name: traefik\n"
+	chart.Spec.Set = map[string]intstr.IntOrString{
+		"image.tag":                       intstr.FromString("v2"),
+		"image.registry":                  intstr.FromString("docker.io"),
+		"ingress.hosts[0].name":           intstr.FromString("b"),
+		"ingress.hosts[1].name":           intstr.FromString("c"),
+		`labels.app\.kubernetes\.io/name`: intstr.FromString("other"),
+	}
+	chart.Spec.SetJSON = map[string]string{"image": `{"tag":"v3"}`}
+	config := &v1.HelmChartConfig{Spec: v1.HelmChartConfigSpec{ValuesContent: "replicas: 2\n"}}
+	chart.Spec.SetFrom = []v1.HelmChartSetFrom{{Key: "replicas"}}
+
+	assert.Equal([]string{
+		`spec.set key "image.tag"`,
+		`spec.set key "ingress.hosts[0].name"`,
+		`spec.set key "labels.app\\.kubernetes\\.io/name"`,
+		`spec.setJSON key "image"`,
+		`spec.setFrom key "replicas"`,
+	}, SetOverrides(chart, config))
+
+	chart.Spec.ValuesContent = ""
+	assert.Empty(SetOverrides(chart, nil))
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+)
+
+const (
+	// ChartSourceContentSecret installs the chart archive from the chart's ChartContentSecret
+	ChartSourceContentSecret = "chartContentSecret"
+	// ChartSourceContent installs the chart archive from the chart's ChartContent
+	ChartSourceContent = "chartContent"
+	// ChartSourceRepo installs the chart from its Repo, or by its Chart reference if it has no Repo
+	ChartSourceRepo = "repo"
+)
+
+// ChartSource returns where the chart is installed from. ChartContentSecret takes precedence over ChartContent,
+// which takes precedence over Repo and Chart: the job installs the content that it is given, and any other
+// source is ignored.
+func ChartSource(chart *helmv1.HelmChart) string {
+	if secret := chart.Spec.ChartContentSecret; secret != nil && secret.Name != "" {
+		return ChartSourceContentSecret
+	}
+	if chart.Spec.ChartContent != "" {
+		return ChartSourceContent
+	}
+	return ChartSourceRepo
+}
+
+// RepoSource returns the repo and chart that the job installs the chart from, after any repo mirror has been
+// applied. Both are empty if the chart is not installed from its Repo.
+func RepoSource(chart *helmv1.HelmChart, opts Options) (string, string) {
+	if ChartSource(chart) != ChartSourceRepo {
+		return "", ""
+	}
+	chart = mirrorRepo(chart, opts)
+	return chart.Spec.Repo, chart.Spec.Chart
+}
+
+// IgnoredSourceFields returns the fields of the chart that select where the chart is installed from, but are
+// ignored because a source with higher precedence is set.
+func IgnoredSourceFields(chart *helmv1.HelmChart) []string {
+	source := ChartSource(chart)
+	if source == ChartSourceRepo {
+		return nil
+	}
+
+	var ignored []string
+	if source == ChartSourceContentSecret && chart.Spec.ChartContent != "" {
+		ignored = append(ignored, "spec.chartContent")
+	}
+	if chart.Spec.Chart != "" {
+		ignored = append(ignored, "spec.chart")
+	}
+	if chart.Spec.Repo != "" {
+		ignored = append(ignored, "spec.repo")
+	}
+	if chart.Spec.Version != "" {
+		ignored = append(ignored, "spec.version")
+	}
+	if chart.Spec.ChartFetcher != nil {
+		ignored = append(ignored, "spec.chartFetcher")
+	}
+	return ignored
+}
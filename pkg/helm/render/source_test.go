@@ -0,0 +1,54 @@
+package render
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+func TestChartSource(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Repo = "https://helm.traefik.io/traefik"
+	chart.Spec.Version = "10.0.0"
+	assert.Equal(ChartSourceRepo, ChartSource(chart))
+	assert.Empty(IgnoredSourceFields(chart))
+
+	chart.Spec.ChartContent = "H4sIAAAAAAAA"
+	chart.Spec.ChartFetcher = &v1.HelmChartFetcher{Image: "registry.example.com/fetcher:v1"}
+	assert.Equal(ChartSourceContent, ChartSource(chart))
+	assert.Equal([]string{"spec.chart", "spec.repo", "spec.version", "spec.chartFetcher"}, IgnoredSourceFields(chart))
+
+	// the job is given the content, and no init containers that fetch the chart from elsewhere
+	objs, err := Objects(chart, nil, Options{})
+	assert.NoError(err)
+	all := objs.All()
+	installJob := all[len(all)-1].(*batch.Job)
+	assert.Empty(installJob.Spec.Template.Spec.InitContainers)
+	assert.NotNil(jobVolume(installJob, "content").ConfigMap)
+
+	chart.Spec.ChartContentSecret = &core.LocalObjectReference{Name: "traefik-chart"}
+	assert.Equal(ChartSourceContentSecret, ChartSource(chart))
+	assert.Contains(IgnoredSourceFields(chart), "spec.chartContent")
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	assert.Equal("traefik-chart", jobVolume(installJob, "content").Secret.SecretName)
+}
+
+func TestRepoSource(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Repo = "https://helm.traefik.io/traefik"
+	opts := Options{RepoMirrors: map[string]string{"https://helm.traefik.io": "https://mirror.local"}}
+	repo, chartRef := RepoSource(chart, opts)
+	assert.Equal("https://mirror.local/traefik", repo)
+	assert.Equal(chart.Spec.Chart, chartRef)
+	assert.Equal("https://helm.traefik.io/traefik", chart.Spec.Repo)
+
+	chart.Spec.ChartContent = "H4sIAAAAAAAA"
+	repo, chartRef = RepoSource(chart, opts)
+	assert.Empty(repo)
+	assert.Empty(chartRef)
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"net/url"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+)
+
+const (
+	// repoStoragePath is where the chart's RepoStorageSecret is mounted
+	repoStoragePath = "/repo-storage"
+	// repoStorageGCSCredentials is the key of the RepoStorageSecret that holds a GCS service account key
+	repoStorageGCSCredentials = "credentials.json"
+)
+
+// RepoStorage returns the object storage scheme of the chart's repo, for repos hosted in S3, GCS or Azure blob
+// storage, or an empty string for any other repo.
+func RepoStorage(repo string) string {
+	u, err := url.Parse(repo)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "s3", "gs", "azblob":
+		return u.Scheme
+	}
+	return ""
+}
+
+// setRepoStorage tells the job that the chart's repo is hosted in object storage, so that it is fetched with the
+// job image's downloader plugin for the scheme, and passes it the credentials in the chart's RepoStorageSecret.
+// The Secret's keys are set as environment variables, which the S3 and Azure plugins read access keys from, and
+// the Secret is mounted so that a GCS service account key can be read from a file. Without the Secret, the
+// plugins fall back to workload identity, which is configured through the chart's generated labels and annotations.
+func setRepoStorage(job *batch.Job, chart *helmv1.HelmChart) {
+	scheme := RepoStorage(chart.Spec.Repo)
+	if scheme == "" {
+		return
+	}
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, core.EnvVar{
+		Name:  "REPO_STORAGE",
+		Value: scheme,
+	})
+
+	secret := chart.Spec.RepoStorageSecret
+	if secret == nil || secret.Name == "" {
+		return
+	}
+	container.EnvFrom = append(container.EnvFrom, core.EnvFromSource{
+		SecretRef: &core.SecretEnvSource{
+			LocalObjectReference: *secret,
+		},
+	})
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, core.Volume{
+		Name: "repo-storage",
+		VolumeSource: core.VolumeSource{
+			Secret: &core.SecretVolumeSource{
+				SecretName: secret.Name,
+			},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+		MountPath: repoStoragePath,
+		Name:      "repo-storage",
+		ReadOnly:  true,
+	})
+	if scheme == "gs" {
+		container.Env = append(container.Env, core.EnvVar{
+			Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+			Value: repoStoragePath + "/" + repoStorageGCSCredentials,
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestRepoStorage(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("s3", RepoStorage("s3://charts-bucket/stable"))
+	assert.Equal("gs", RepoStorage("gs://charts-bucket"))
+	assert.Equal("azblob", RepoStorage("azblob://charts"))
+	assert.Empty(RepoStorage("https://helm.traefik.io/traefik"))
+	assert.Empty(RepoStorage("s3:///no-bucket"))
+}
+
+func TestSetRepoStorage(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.Repo = "gs://charts-bucket"
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	container := installJob.Spec.Template.Spec.Containers[0]
+	assert.Contains(container.Env, core.EnvVar{Name: "REPO_STORAGE", Value: "gs"})
+	assert.Nil(jobVolume(installJob, "repo-storage").Secret)
+	assert.Contains(container.Args, "--repo")
+
+	chart.Spec.RepoStorageSecret = &core.LocalObjectReference{Name: "gcs-credentials"}
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage})
+	container = installJob.Spec.Template.Spec.Containers[0]
+	assert.Equal("gcs-credentials", jobVolume(installJob, "repo-storage").Secret.SecretName)
+	assert.Contains(container.Env, core.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/repo-storage/credentials.json"})
+	assert.Equal("gcs-credentials", container.EnvFrom[len(container.EnvFrom)-1].SecretRef.Name)
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// TargetContextAnnotation sets the name of the target context of a kubeconfig Secret. Secrets without it are
+	// named after the Secret, without the "vc-" prefix that vcluster gives the kubeconfig Secrets it creates.
+	TargetContextAnnotation = "helm.cattle.io/target-context"
+	// TargetContextKeyAnnotation sets the key of a kubeconfig Secret that holds the kubeconfig.
+	TargetContextKeyAnnotation = "helm.cattle.io/target-context-key"
+	// DefaultTargetContextKey is the key that vcluster stores the kubeconfig in.
+	DefaultTargetContextKey = "config"
+
+	targetKubeconfigPath = "/target-context/kubeconfig"
+)
+
+// TargetContext is a cluster other than the one that the controller runs in, such as a virtual cluster running
+// within it, that charts can be installed into.
+type TargetContext struct {
+	// Name is the name that charts refer to the target context by.
+	Name string
+	// KubeconfigSecret references the key of a Secret in the chart's namespace holding the kubeconfig that the job
+	// uses to reach the target context.
+	KubeconfigSecret core.SecretKeySelector
+}
+
+// TargetContextFromSecret returns the target context for a Secret holding a kubeconfig.
+func TargetContextFromSecret(secret *core.Secret) TargetContext {
+	name := secret.Annotations[TargetContextAnnotation]
+	if name == "" {
+		name = strings.TrimPrefix(secret.Name, "vc-")
+	}
+	key := secret.Annotations[TargetContextKeyAnnotation]
+	if key == "" {
+		key = DefaultTargetContextKey
+	}
+	return TargetContext{
+		Name: name,
+		KubeconfigSecret: core.SecretKeySelector{
+			LocalObjectReference: core.LocalObjectReference{Name: secret.Name},
+			Key:                  key,
+		},
+	}
+}
+
+// ValidateTargetContext checks that the chart's TargetContext, if set, is a valid name.
+func ValidateTargetContext(chart *helmv1.HelmChart) error {
+	if chart.Spec.TargetContext == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(chart.Spec.TargetContext); len(errs) > 0 {
+		return fmt.Errorf("spec.targetContext %q is invalid: %s", chart.Spec.TargetContext, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// setTargetContext mounts the kubeconfig of the chart's target context into the job, and points helm at it, so
+// that the release is installed into the target context instead of the cluster that the job runs in. The job
+// still runs in, and is managed from, the chart's namespace.
+func setTargetContext(job *batch.Job, chart *helmv1.HelmChart, opts Options) error {
+	if chart.Spec.TargetContext == "" {
+		return nil
+	}
+	if opts.TargetContextGetter == nil {
+		return fmt.Errorf("spec.targetContext %q cannot be used: target contexts are not enabled", chart.Spec.TargetContext)
+	}
+	target, err := opts.TargetContextGetter(chart.Namespace, chart.Spec.TargetContext)
+	if err != nil {
+		return err
+	}
+
+	spec := &job.Spec.Template.Spec
+	spec.Volumes = append(spec.Volumes, core.Volume{
+		Name: "target-context",
+		VolumeSource: core.VolumeSource{
+			Secret: &core.SecretVolumeSource{
+				SecretName: target.KubeconfigSecret.Name,
+				Items:      []core.KeyToPath{{Key: target.KubeconfigSecret.Key, Path: path.Base(targetKubeconfigPath)}},
+			},
+		},
+	})
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+			Name:      "target-context",
+			MountPath: path.Dir(targetKubeconfigPath),
+			ReadOnly:  true,
+		})
+		container.Env = append(container.Env, core.EnvVar{Name: "KUBECONFIG", Value: targetKubeconfigPath})
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTargetContextFromSecret(t *testing.T) {
+	assert := assert.New(t)
+	secret := &core.Secret{ObjectMeta: meta.ObjectMeta{Name: "vc-tenant-a"}}
+	target := TargetContextFromSecret(secret)
+	assert.Equal("tenant-a", target.Name)
+	assert.Equal("vc-tenant-a", target.KubeconfigSecret.Name)
+	assert.Equal(DefaultTargetContextKey, target.KubeconfigSecret.Key)
+
+	secret.Annotations = map[string]string{TargetContextAnnotation: "staging", TargetContextKeyAnnotation: "kubeconfig"}
+	target = TargetContextFromSecret(secret)
+	assert.Equal("staging", target.Name)
+	assert.Equal("kubeconfig", target.KubeconfigSecret.Key)
+}
+
+func TestTargetContext(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	assert.NoError(ValidateTargetContext(chart))
+	chart.Spec.TargetContext = "Tenant_A"
+	assert.Error(ValidateTargetContext(chart))
+	chart.Spec.TargetContext = "tenant-a"
+	assert.NoError(ValidateTargetContext(chart))
+
+	_, err := Objects(chart, nil, Options{})
+	assert.EqualError(err, `spec.targetContext "tenant-a" cannot be used: target contexts are not enabled`)
+
+	var lookedUp string
+	objs, err := Objects(chart, nil, Options{TargetContextGetter: func(namespace, name string) (*TargetContext, error) {
+		lookedUp = namespace + "/" + name
+		target := TargetContextFromSecret(&core.Secret{ObjectMeta: meta.ObjectMeta{Name: "vc-" + name}})
+		return &target, nil
+	}})
+	assert.NoError(err)
+	assert.Equal("kube-system/tenant-a", lookedUp)
+	all := objs.All()
+	installJob := all[len(all)-1].(*batch.Job)
+	volume := jobVolume(installJob, "target-context")
+	if assert.NotNil(volume.Secret) {
+		assert.Equal("vc-tenant-a", volume.Secret.SecretName)
+		assert.Equal([]core.KeyToPath{{Key: "config", Path: "kubeconfig"}}, volume.Secret.Items)
+	}
+	assert.Contains(installJob.Spec.Template.Spec.Containers[0].Env, core.EnvVar{Name: "KUBECONFIG", Value: targetKubeconfigPath})
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"path"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// serviceAccountTokenExpiration is the lifetime of projected ServiceAccount tokens; the kubelet refreshes them
+	// before they expire
+	serviceAccountTokenExpiration = 3600
+	// rootCAConfigMap is published in every namespace by the kube-controller-manager, and holds the CA bundle of the
+	// API server
+	rootCAConfigMap = "kube-root-ca.crt"
+)
+
+// setServiceAccountToken mounts a projected token for the job's ServiceAccount, along with the API server's CA
+// bundle and the namespace, at the same path as the automounted token, in place of automounting. Job images that
+// read the token files directly then work the same way regardless of whether the cluster creates token Secrets
+// for ServiceAccounts, or automounts tokens for the ServiceAccount the chart uses.
+func setServiceAccountToken(job *batch.Job, opts Options) {
+	if !opts.ProjectedServiceAccountToken {
+		return
+	}
+
+	spec := &job.Spec.Template.Spec
+	spec.AutomountServiceAccountToken = pointer.BoolPtr(false)
+	spec.Volumes = append(spec.Volumes, core.Volume{
+		Name: "service-account-token",
+		VolumeSource: core.VolumeSource{
+			Projected: &core.ProjectedVolumeSource{
+				Sources: []core.VolumeProjection{
+					{
+						ServiceAccountToken: &core.ServiceAccountTokenProjection{
+							Path:              path.Base(serviceAccountTokenPath),
+							ExpirationSeconds: pointer.Int64Ptr(serviceAccountTokenExpiration),
+						},
+					},
+					{
+						ConfigMap: &core.ConfigMapProjection{
+							LocalObjectReference: core.LocalObjectReference{Name: rootCAConfigMap},
+							Items:                []core.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+						},
+					},
+					{
+						DownwardAPI: &core.DownwardAPIProjection{
+							Items: []core.DownwardAPIVolumeFile{{
+								Path:     "namespace",
+								FieldRef: &core.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"},
+							}},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	mount := core.VolumeMount{
+		Name:      "service-account-token",
+		MountPath: path.Dir(serviceAccountTokenPath),
+		ReadOnly:  true,
+	}
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].VolumeMounts = append(spec.InitContainers[i].VolumeMounts, mount)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, mount)
+	}
+}
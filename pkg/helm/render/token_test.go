@@ -0,0 +1,46 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+func TestProjectedServiceAccountToken(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	installJob, _, _ := job(chart, Options{JobImage: DefaultJobImage})
+	assert.Nil(installJob.Spec.Template.Spec.AutomountServiceAccountToken)
+
+	chart.Spec.Repo = "http://source-controller.flux-system.svc/helmchart/kube-system/traefik/traefik-1.0.0.tgz"
+	chart.Spec.RepoServiceAccountAuth = true
+	installJob, _, _ = job(chart, Options{JobImage: DefaultJobImage, ProjectedServiceAccountToken: true})
+	spec := installJob.Spec.Template.Spec
+	if assert.NotNil(spec.AutomountServiceAccountToken) {
+		assert.False(*spec.AutomountServiceAccountToken)
+	}
+
+	var volume *core.Volume
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == "service-account-token" {
+			volume = &spec.Volumes[i]
+		}
+	}
+	if assert.NotNil(volume) && assert.NotNil(volume.Projected) && assert.Len(volume.Projected.Sources, 3) {
+		assert.Equal("token", volume.Projected.Sources[0].ServiceAccountToken.Path)
+		assert.Equal("kube-root-ca.crt", volume.Projected.Sources[1].ConfigMap.Name)
+		assert.Equal("metadata.namespace", volume.Projected.Sources[2].DownwardAPI.Items[0].FieldRef.FieldPath)
+	}
+
+	// the artifact init container authenticates with the token, so it is mounted there too
+	containers := append(spec.InitContainers, spec.Containers...)
+	assert.Len(containers, 2)
+	for _, container := range containers {
+		assert.Contains(container.VolumeMounts, core.VolumeMount{
+			Name:      "service-account-token",
+			MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
+			ReadOnly:  true,
+		}, container.Name)
+	}
+}
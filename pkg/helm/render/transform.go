@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// transformedValuesKey holds the transformed values in the values ConfigMap, in place of the values of the
+	// chart and its config
+	transformedValuesKey = "values-01_Transformed.yaml"
+	// execTransformerTimeout is how long an exec values transformer may run for
+	execTransformerTimeout = 30 * time.Second
+)
+
+// ValuesTransformer modifies the values of a chart before they are stored in the values ConfigMap for its job.
+type ValuesTransformer interface {
+	// TransformValues is passed the values content of the chart merged with that of its config, and returns the
+	// values to install the chart with. The chart must not be modified. Errors are returned from rendering, so
+	// that the chart is retried.
+	TransformValues(chart *helmv1.HelmChart, values map[string]interface{}) (map[string]interface{}, error)
+}
+
+// ValuesTransformerFunc adapts a function to the ValuesTransformer interface.
+type ValuesTransformerFunc func(chart *helmv1.HelmChart, values map[string]interface{}) (map[string]interface{}, error)
+
+func (f ValuesTransformerFunc) TransformValues(chart *helmv1.HelmChart, values map[string]interface{}) (map[string]interface{}, error) {
+	return f(chart, values)
+}
+
+// NewExecValuesTransformer returns a ValuesTransformer that runs the executable at path, with the values as YAML on
+// stdin, and reads the transformed values as YAML from stdout. The name and namespace of the chart are passed in
+// the HELM_CHART_NAME and HELM_CHART_NAMESPACE env vars.
+func NewExecValuesTransformer(path string) ValuesTransformer {
+	return ValuesTransformerFunc(func(chart *helmv1.HelmChart, values map[string]interface{}) (map[string]interface{}, error) {
+		input, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), execTransformerTimeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Env = append(os.Environ(), "HELM_CHART_NAME="+chart.Name, "HELM_CHART_NAMESPACE="+chart.Namespace)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("values transformer %s failed: %w: %s", path, err, bytes.TrimSpace(stderr.Bytes()))
+		}
+
+		transformed := map[string]interface{}{}
+		if err := yaml.Unmarshal(stdout.Bytes(), &transformed); err != nil {
+			return nil, fmt.Errorf("values transformer %s returned invalid values: %w", path, err)
+		}
+		return transformed, nil
+	})
+}
+
+// transformValues replaces the values of the chart and its config in the values ConfigMap with a single values
+// file, holding the values returned by MergeValues. Values set by the chart's Set, SetJSON, SetFiles, SetFrom
+// and SetFieldRefs are passed to helm as args, and so are not seen by transformers. Transformers are not run for
+// charts that are being deleted, as the delete job does not use values, and a failing transformer would otherwise
+// block the chart from being uninstalled.
+func transformValues(configMap *core.ConfigMap, chart *helmv1.HelmChart, config *helmv1.HelmChartConfig, transformers []ValuesTransformer) error {
+	if len(transformers) == 0 || chart.DeletionTimestamp != nil {
+		return nil
+	}
+	values, err := MergeValues(chart, config, transformers)
+	if err != nil {
+		return err
+	}
+
+	delete(configMap.Data, "values-01_HelmChart.yaml")
+	delete(configMap.Data, "values-10_HelmChartConfig.yaml")
+	if len(values) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	configMap.Data[transformedValuesKey] = string(data)
+	return nil
+}
+
+// MergeValues returns the values content of the chart and its config, if any, merged in the same way as helm
+// merges values files, and then passed through each of the transformers in turn.
+func MergeValues(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig, transformers []ValuesTransformer) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, content := range []string{chart.Spec.ValuesContent, valuesContent(config)} {
+		current, err := parseValuesContent(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse values: %w", err)
+		}
+		values = mergeValues(values, current)
+	}
+
+	for _, transformer := range transformers {
+		transformed, err := transformer.TransformValues(chart, values)
+		if err != nil {
+			return nil, err
+		}
+		if transformed == nil {
+			transformed = map[string]interface{}{}
+		}
+		values = transformed
+	}
+	return values, nil
+}
+
+func valuesContent(config *helmv1.HelmChartConfig) string {
+	if config == nil {
+		return ""
+	}
+	return config.Spec.ValuesContent
+}
+
+// mergeValues merges src into dst, in the same way as helm merges values files: maps are merged recursively, and
+// all other values in src replace those in dst.
+// Ref: https://github.com/helm/helm/blob/v3.10.0/pkg/cli/values/options.go#L107
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		if v, ok := v.(map[string]interface{}); ok {
+			if dv, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = mergeValues(dv, v)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
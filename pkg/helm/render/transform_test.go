@@ -0,0 +1,76 @@
+package render
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransformValues(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	chart.Spec.ValuesContent = "image:\n  registry: docker.io\n  tag: v1\nreplicas: 1\n"
+	config := &v1.HelmChartConfig{Spec: v1.HelmChartConfigSpec{ValuesContent: "image:\n  tag: v2\n"}}
+
+	var seen map[string]interface{}
+	rewrite := ValuesTransformerFunc(func(chart *v1.HelmChart, values map[string]interface{}) (map[string]interface{}, error) {
+		seen = values
+		values["image"].(map[string]interface{})["registry"] = "registry.example.com"
+		values["clusterName"] = chart.Namespace
+		return values, nil
+	})
+
+	objs, err := Objects(chart, config, Options{ValuesTransformers: []ValuesTransformer{rewrite}})
+	assert.NoError(err)
+	var configMap *core.ConfigMap
+	for _, obj := range objs.All() {
+		if cm, ok := obj.(*core.ConfigMap); ok && strings.HasPrefix(cm.Name, valuesConfigMapName(chart)+"-") {
+			configMap = cm
+		}
+	}
+	if assert.NotNil(configMap) {
+		assert.Equal(map[string]string{
+			transformedValuesKey: "clusterName: kube-system\nimage:\n  registry: registry.example.com\n  tag: v2\nreplicas: 1\n",
+		}, configMap.Data)
+	}
+	assert.Equal(map[string]interface{}{"registry": "registry.example.com", "tag": "v2"}, seen["image"])
+
+	errNoRegistry := errors.New("no registry configured")
+	_, err = Objects(chart, config, Options{ValuesTransformers: []ValuesTransformer{ValuesTransformerFunc(func(*v1.HelmChart, map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errNoRegistry
+	})}})
+	assert.Equal(errNoRegistry, err)
+
+	deleteTime := meta.NewTime(time.Now())
+	chart.DeletionTimestamp = &deleteTime
+	_, err = Objects(chart, config, Options{ValuesTransformers: []ValuesTransformer{ValuesTransformerFunc(func(*v1.HelmChart, map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errNoRegistry
+	})}})
+	assert.NoError(err)
+}
+
+func TestExecValuesTransformer(t *testing.T) {
+	assert := assert.New(t)
+	script := filepath.Join(t.TempDir(), "transform")
+	assert.NoError(os.WriteFile(script, []byte("#!/bin/sh\ncat\necho \"chart: $HELM_CHART_NAMESPACE/$HELM_CHART_NAME\"\n"), 0700))
+
+	chart := NewChart()
+	values, err := NewExecValuesTransformer(script).TransformValues(chart, map[string]interface{}{"replicas": 2})
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"replicas": float64(2), "chart": "kube-system/traefik"}, values)
+
+	failing := filepath.Join(t.TempDir(), "fail")
+	assert.NoError(os.WriteFile(failing, []byte("#!/bin/sh\necho no registry configured >&2\nexit 1\n"), 0700))
+	_, err = NewExecValuesTransformer(failing).TransformValues(chart, nil)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "no registry configured")
+	}
+}
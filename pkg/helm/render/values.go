@@ -0,0 +1,114 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// maxValidatedValues bounds the number of validation results that are remembered
+const maxValidatedValues = 1024
+
+var (
+	validatedValuesLock sync.Mutex
+	// validatedValues holds the result of validating values content, keyed by the hash of the content. A
+	// HelmChartConfig referenced by many charts is validated once when it changes, instead of once per chart.
+	validatedValues = map[[sha256.Size]byte]error{}
+	// parsedValues holds parsed values content, keyed by the hash of the content, so that the values of a
+	// HelmChartConfig referenced by many charts are parsed once when it changes, instead of once per chart.
+	parsedValues = map[[sha256.Size]byte]map[string]interface{}{}
+)
+
+// ValidateValuesContent checks that the ValuesContent of the chart and of its config, if any, parse as strict
+// YAML mappings. Duplicate keys, which helm silently resolves by using the last value, and tabs used for
+// indentation, are rejected with the line they are on, instead of producing an obscure failure in the job.
+func ValidateValuesContent(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) error {
+	if err := validateValues(chart.Spec.ValuesContent); err != nil {
+		return fmt.Errorf("spec.valuesContent is invalid: %w", err)
+	}
+	if config != nil {
+		if err := validateValues(config.Spec.ValuesContent); err != nil {
+			return fmt.Errorf("spec.valuesContent of HelmChartConfig %s/%s is invalid: %w", config.Namespace, config.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateConfigValuesContent checks that the ValuesContent of the config parses as a strict YAML mapping, in the
+// same way as ValidateValuesContent, so that the config can be reported as invalid by itself.
+func ValidateConfigValuesContent(config *helmv1.HelmChartConfig) error {
+	if err := validateValues(config.Spec.ValuesContent); err != nil {
+		return fmt.Errorf("spec.valuesContent is invalid: %w", err)
+	}
+	return nil
+}
+
+func validateValues(values string) error {
+	if values == "" {
+		return nil
+	}
+	key := sha256.Sum256([]byte(values))
+	validatedValuesLock.Lock()
+	err, ok := validatedValues[key]
+	validatedValuesLock.Unlock()
+	if ok {
+		return err
+	}
+
+	err = parseValues(values)
+	validatedValuesLock.Lock()
+	defer validatedValuesLock.Unlock()
+	if len(validatedValues) >= maxValidatedValues {
+		validatedValues = map[[sha256.Size]byte]error{}
+	}
+	validatedValues[key] = err
+	return err
+}
+
+func parseValues(values string) error {
+	json, err := yaml.YAMLToJSONStrict([]byte(values))
+	if err != nil {
+		message := strings.TrimPrefix(err.Error(), "yaml: unmarshal errors:\n")
+		return fmt.Errorf("%s", strings.Join(strings.Fields(strings.ReplaceAll(message, "\n", ";")), " "))
+	}
+	if json = bytes.TrimSpace(json); len(json) > 0 && !bytes.Equal(json, []byte("null")) && json[0] != '{' {
+		return fmt.Errorf("values must be a mapping of keys to values")
+	}
+	return nil
+}
+
+// parseValuesContent returns the values content parsed as YAML. Parsed values are remembered, and a copy is
+// returned, so that callers may modify it.
+func parseValuesContent(content string) (map[string]interface{}, error) {
+	if content == "" {
+		return map[string]interface{}{}, nil
+	}
+	key := sha256.Sum256([]byte(content))
+	validatedValuesLock.Lock()
+	values, ok := parsedValues[key]
+	validatedValuesLock.Unlock()
+	if ok {
+		return runtime.DeepCopyJSON(values), nil
+	}
+
+	values = map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(content), &values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	validatedValuesLock.Lock()
+	defer validatedValuesLock.Unlock()
+	if len(parsedValues) >= maxValidatedValues {
+		parsedValues = map[[sha256.Size]byte]map[string]interface{}{}
+	}
+	parsedValues[key] = values
+	return runtime.DeepCopyJSON(values), nil
+}
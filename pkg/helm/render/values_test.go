@@ -0,0 +1,136 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValuesContent(t *testing.T) {
+	assert := assert.New(t)
+	chart := NewChart()
+	config := &helmv1.HelmChartConfig{}
+	config.Namespace, config.Name = "kube-system", "traefik"
+	assert.NoError(ValidateValuesContent(chart, nil))
+
+	chart.Spec.ValuesContent = "defaults: &defaults\n  replicas: 1\nweb:\n  <<: *defaults\n  port: 8000\n"
+	assert.NoError(ValidateValuesContent(chart, config))
+
+	chart.Spec.ValuesContent = "ports:\n  web: 8000\n  web: 8080\n"
+	assert.EqualError(ValidateValuesContent(chart, config), `spec.valuesContent is invalid: line 3: key "web" already set in map`)
+
+	chart.Spec.ValuesContent = "ports:\n\tweb: 8000\n"
+	assert.EqualError(ValidateValuesContent(chart, config), "spec.valuesContent is invalid: yaml: line 2: found character that cannot start any token")
+
+	// tabs are allowed in block scalars, such as scripts and Makefiles
+	chart.Spec.ValuesContent = "script: |\n  all:\n  \tmake build\nrows: >\n  a\tb\n"
+	assert.NoError(ValidateValuesContent(chart, config))
+
+	chart.Spec.ValuesContent = "- web\n"
+	assert.Error(ValidateValuesContent(chart, config))
+
+	chart.Spec.ValuesContent = ""
+	config.Spec.ValuesContent = "web: *ports\n"
+	assert.EqualError(ValidateValuesContent(chart, config), "spec.valuesContent of HelmChartConfig kube-system/traefik is invalid: yaml: unknown anchor 'ports' referenced")
+}
+
+func TestParseValuesContent(t *testing.T) {
+	assert := assert.New(t)
+	values, err := parseValuesContent("image:\n  tag: v1\nports: [80, 443]\n")
+	assert.NoError(err)
+	values["image"].(map[string]interface{})["tag"] = "v2"
+	values["ports"] = nil
+
+	// callers may modify the values without affecting other charts sharing the same content
+	values, err = parseValuesContent("image:\n  tag: v1\nports: [80, 443]\n")
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"image": map[string]interface{}{"tag": "v1"}, "ports": []interface{}{float64(80), float64(443)}}, values)
+
+	values, err = parseValuesContent("")
+	assert.NoError(err)
+	assert.Empty(values)
+	_, err = parseValuesContent("- a\n")
+	assert.Error(err)
+}
+
+// sharedConfig returns a HelmChartConfig with large values, as used to configure many charts at once.
+func sharedConfig() *helmv1.HelmChartConfig {
+	var values strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&values, "key%d:\n  nested: value-%d\n  list: [a, b, c]\n", i, i)
+	}
+	config := &helmv1.HelmChartConfig{}
+	config.Namespace, config.Name = "kube-system", "shared"
+	config.Spec.ValuesContent = values.String()
+	return config
+}
+
+// BenchmarkSharedConfig reconciles a fleet of 500 charts that reference the same HelmChartConfig, validating and
+// rendering each as the controller does after the config is changed.
+func BenchmarkSharedConfig(b *testing.B) {
+	config := sharedConfig()
+	charts := make([]*helmv1.HelmChart, 500)
+	for i := range charts {
+		charts[i] = NewChart()
+		charts[i].Name = fmt.Sprintf("chart-%d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// each iteration sees a new revision of the config
+		config.Spec.ValuesContent += fmt.Sprintf("revision%d: true\n", i)
+		for _, chart := range charts {
+			if err := ValidateValuesContent(chart, config); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := Objects(chart, config, Options{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkSharedConfigUncached is BenchmarkSharedConfig without reusing the validation of the config's values
+// across charts, for comparison.
+func BenchmarkSharedConfigUncached(b *testing.B) {
+	config := sharedConfig()
+	charts := make([]*helmv1.HelmChart, 500)
+	for i := range charts {
+		charts[i] = NewChart()
+		charts[i].Name = fmt.Sprintf("chart-%d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		config.Spec.ValuesContent += fmt.Sprintf("revision%d: true\n", i)
+		for _, chart := range charts {
+			if err := parseValues(config.Spec.ValuesContent); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := Objects(chart, config, Options{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkSharedConfigMergeValues merges the values of a fleet of 500 charts with the HelmChartConfig that they
+// share, as the controller does to find set values that override the values content after the config is changed.
+func BenchmarkSharedConfigMergeValues(b *testing.B) {
+	config := sharedConfig()
+	charts := make([]*helmv1.HelmChart, 500)
+	for i := range charts {
+		charts[i] = NewChart()
+		charts[i].Name = fmt.Sprintf("chart-%d", i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		config.Spec.ValuesContent += fmt.Sprintf("revision%d: true\n", i)
+		for _, chart := range charts {
+			if _, err := MergeValues(chart, config, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/sirupsen/logrus"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rollback handles a request to roll the chart's release back to a previous revision, made by setting the
+// RollbackAnnotation. A rollback job is run, and once it has finished, its result is recorded in the RolledBack
+// condition, and the job and annotation are removed. It returns false while the rollback is in progress, so that
+// the install job does not upgrade the release at the same time. The install job is not re-run afterwards, as its
+// configuration has not changed; the release is upgraded again the next time the chart is changed.
+func (c *Controller) rollback(chart *helmv1.HelmChart, config *helmv1.HelmChartConfig) (*helmv1.HelmChart, bool, error) {
+	value, ok := chart.Annotations[RollbackAnnotation]
+	if !ok || chart.DeletionTimestamp != nil {
+		return chart, true, nil
+	}
+
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "RollbackInvalid", "Ignoring %s annotation: %q is not a release revision", RollbackAnnotation, value)
+		updated, err := c.finishRollback(chart, false, "InvalidRevision", fmt.Sprintf("%q is not a release revision", value))
+		return updated, false, err
+	}
+
+	job, err := render.RollbackJob(chart, config, c.renderOptions(), revision)
+	if err != nil {
+		return chart, false, err
+	}
+	existing, err := c.jobsCache.Get(job.Namespace, job.Name)
+	if errors.IsNotFound(err) {
+		if _, err := c.jobs.Create(job); err != nil && !errors.IsAlreadyExists(err) {
+			return chart, false, err
+		}
+		c.recorder.Eventf(chart, core.EventTypeNormal, "RollbackJobCreated", "Rolling back release %s/%s to revision %d using Job %s/%s", render.TargetNamespace(chart), render.ReleaseName(chart), revision, job.Namespace, job.Name)
+		return chart, false, nil
+	} else if err != nil {
+		return chart, false, err
+	}
+
+	// the annotation was changed to another revision since the job was created
+	if !reflect.DeepEqual(existing.Spec.Template.Spec.Containers[0].Args, job.Spec.Template.Spec.Containers[0].Args) {
+		return chart, false, c.deleteRollbackJob(existing)
+	}
+	if !jobFinished(existing) {
+		return chart, false, nil
+	}
+
+	if err := c.deleteRollbackJob(existing); err != nil {
+		return chart, false, err
+	}
+	if failure := jobFailure(existing); failure != nil {
+		c.recorder.Eventf(chart, core.EventTypeWarning, "RollbackFailed", "Job %s/%s failed to roll back release to revision %d: %s", existing.Namespace, existing.Name, revision, failure.Message)
+		updated, err := c.finishRollback(chart, false, "JobFailed", fmt.Sprintf("rollback to revision %d failed: %s", revision, failure.Message))
+		return updated, false, err
+	}
+	c.recorder.Eventf(chart, core.EventTypeNormal, "RollbackSucceeded", "Rolled back release %s/%s to revision %d", render.TargetNamespace(chart), render.ReleaseName(chart), revision)
+	updated, err := c.finishRollback(chart, true, "JobSucceeded", fmt.Sprintf("rolled back to revision %d", revision))
+	return updated, false, err
+}
+
+// finishRollback removes the RollbackAnnotation from the chart, and records the result of the rollback.
+func (c *Controller) finishRollback(chart *helmv1.HelmChart, succeeded bool, reason, message string) (*helmv1.HelmChart, error) {
+	chartCopy := chart.DeepCopy()
+	delete(chartCopy.Annotations, RollbackAnnotation)
+	if succeeded {
+		ConditionRolledBack.True(chartCopy)
+		// the rolled back release is now the one that is expected to be installed
+		if err := c.setReleaseStatus(chartCopy); err != nil {
+			logrus.Warnf("Failed to get rolled back release of HelmChart %s/%s: %v", chart.Namespace, chart.Name, err)
+		}
+	} else {
+		ConditionRolledBack.False(chartCopy)
+	}
+	ConditionRolledBack.Reason(chartCopy, reason)
+	ConditionRolledBack.Message(chartCopy, message)
+	return c.updateStatus(chartCopy)
+}
+
+func (c *Controller) deleteRollbackJob(job *batch.Job) error {
+	err := c.jobs.Delete(job.Namespace, job.Name, &meta.DeleteOptions{PropagationPolicy: &deletePolicy})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
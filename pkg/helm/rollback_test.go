@@ -0,0 +1,168 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	batchcontroller "github.com/rancher/wrangler/pkg/generated/controllers/batch/v1"
+	"github.com/stretchr/testify/assert"
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type jobController struct {
+	batchcontroller.JobController
+	created []*batch.Job
+	deleted []string
+}
+
+func (c *jobController) Create(job *batch.Job) (*batch.Job, error) {
+	c.created = append(c.created, job)
+	return job, nil
+}
+
+func (c *jobController) Delete(namespace, name string, opts *meta.DeleteOptions) error {
+	c.deleted = append(c.deleted, namespace+"/"+name)
+	return nil
+}
+
+// rollbackController returns a controller that holds the release secret of revision 2 of the traefik release.
+func rollbackController() (*Controller, *helmController, *jobController, *jobCache) {
+	data, _ := json.Marshal(map[string]interface{}{"name": "traefik", "chart": map[string]interface{}{"metadata": map[string]string{"name": "traefik", "version": "1.0.0"}}})
+	helms := &helmController{}
+	jobs := &jobController{}
+	cache := &jobCache{jobs: map[string]*batch.Job{}}
+	c := &Controller{
+		helmController: helms,
+		jobs:           jobs,
+		jobsCache:      cache,
+		secretController: &secretController{secrets: []core.Secret{{
+			ObjectMeta: meta.ObjectMeta{
+				Namespace: "kube-system",
+				Name:      "sh.helm.release.v1.traefik.v2",
+				Labels:    map[string]string{"owner": "helm", "name": "traefik", "version": "2"},
+			},
+			Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(data))},
+		}}},
+		secretCache:    &secretCache{},
+		configMapCache: &configMapCache{},
+		recorder:       record.NewFakeRecorder(10),
+	}
+	return c, helms, jobs, cache
+}
+
+func rollbackChart(revision string) *v1.HelmChart {
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	chart.Annotations = map[string]string{RollbackAnnotation: revision}
+	chart.Status.ReleaseRevision = 3
+	return chart
+}
+
+func TestRollback(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, jobs, cache := rollbackController()
+
+	_, install, err := c.rollback(v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{}), nil)
+	assert.NoError(err)
+	assert.True(install)
+	assert.Empty(jobs.created)
+
+	// the rollback job is created for the requested revision
+	chart := rollbackChart("2")
+	_, install, err = c.rollback(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Len(jobs.created, 1)
+	job := jobs.created[0]
+	assert.Equal("helm-rollback-traefik", job.Name)
+	assert.Equal([]string{"rollback", "2"}, job.Spec.Template.Spec.Containers[0].Args)
+	assert.Empty(helms.updated)
+
+	// nothing is done while the job is running
+	cache.jobs["kube-system/"+job.Name] = job
+	_, install, err = c.rollback(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Len(jobs.created, 1)
+	assert.Empty(jobs.deleted)
+	assert.Empty(helms.updated)
+
+	// once the job has completed, it is removed, and the rollback is recorded on the chart
+	job = job.DeepCopy()
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batch.JobCondition{{Type: batch.JobComplete, Status: core.ConditionTrue}}
+	cache.jobs["kube-system/"+job.Name] = job
+	updated, install, err := c.rollback(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Equal([]string{"kube-system/helm-rollback-traefik"}, jobs.deleted)
+	assert.Len(helms.updated, 1)
+	assert.NotContains(updated.Annotations, RollbackAnnotation)
+	assert.True(ConditionRolledBack.IsTrue(updated))
+	assert.Equal("JobSucceeded", ConditionRolledBack.GetReason(updated))
+	assert.Equal("rolled back to revision 2", ConditionRolledBack.GetMessage(updated))
+	assert.Equal(2, updated.Status.ReleaseRevision)
+	assert.Contains(chart.Annotations, RollbackAnnotation)
+
+	// the chart is installed as usual once the annotation has been removed
+	_, install, err = c.rollback(updated, nil)
+	assert.NoError(err)
+	assert.True(install)
+}
+
+func TestRollbackChangedRevision(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, jobs, cache := rollbackController()
+
+	_, _, err := c.rollback(rollbackChart("2"), nil)
+	assert.NoError(err)
+	cache.jobs["kube-system/helm-rollback-traefik"] = jobs.created[0]
+
+	// the job for the previous revision is removed, so that a job for the new revision is created
+	_, install, err := c.rollback(rollbackChart("1"), nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Equal([]string{"kube-system/helm-rollback-traefik"}, jobs.deleted)
+	assert.Empty(helms.updated)
+}
+
+func TestRollbackFailed(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, jobs, cache := rollbackController()
+
+	chart := rollbackChart("2")
+	_, _, err := c.rollback(chart, nil)
+	assert.NoError(err)
+	job := jobs.created[0].DeepCopy()
+	job.Status.Conditions = []batch.JobCondition{{Type: batch.JobFailed, Status: core.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	cache.jobs["kube-system/"+job.Name] = job
+
+	updated, install, err := c.rollback(chart, nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Equal([]string{"kube-system/helm-rollback-traefik"}, jobs.deleted)
+	assert.Len(helms.updated, 1)
+	assert.NotContains(updated.Annotations, RollbackAnnotation)
+	assert.True(ConditionRolledBack.IsFalse(updated))
+	assert.Equal("JobFailed", ConditionRolledBack.GetReason(updated))
+	assert.Equal("rollback to revision 2 failed: BackoffLimitExceeded", ConditionRolledBack.GetMessage(updated))
+	assert.Equal(3, updated.Status.ReleaseRevision)
+}
+
+func TestRollbackInvalidRevision(t *testing.T) {
+	assert := assert.New(t)
+	c, helms, jobs, _ := rollbackController()
+
+	updated, install, err := c.rollback(rollbackChart("latest"), nil)
+	assert.NoError(err)
+	assert.False(install)
+	assert.Empty(jobs.created)
+	assert.Len(helms.updated, 1)
+	assert.NotContains(updated.Annotations, RollbackAnnotation)
+	assert.True(ConditionRolledBack.IsFalse(updated))
+	assert.Equal("InvalidRevision", ConditionRolledBack.GetReason(updated))
+}
@@ -0,0 +1,40 @@
+package helm
+
+import (
+	"fmt"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+)
+
+// StatusWriter persists the status of charts to an external system, such as a management cluster or fleet
+// database, in addition to the HelmChart resource itself.
+type StatusWriter interface {
+	// WriteStatus is called with a copy of the chart each time the controller updates the chart. Errors are
+	// returned from the reconcile, so that the chart is requeued and the status written again.
+	WriteStatus(chart *helmv1.HelmChart) error
+}
+
+// StatusWriterFunc adapts a function to the StatusWriter interface.
+type StatusWriterFunc func(chart *helmv1.HelmChart) error
+
+func (f StatusWriterFunc) WriteStatus(chart *helmv1.HelmChart) error {
+	return f(chart)
+}
+
+// updateStatus updates the chart, and then passes the updated chart to each of the StatusWriters.
+func (c *Controller) updateStatus(chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+	updated, err := c.helmController.Update(chart)
+	if err != nil {
+		return updated, err
+	}
+	return updated, writeStatus(updated)
+}
+
+func writeStatus(chart *helmv1.HelmChart) error {
+	for _, writer := range StatusWriters {
+		if err := writer.WriteStatus(chart.DeepCopy()); err != nil {
+			return fmt.Errorf("failed to write status of HelmChart %s/%s: %w", chart.Namespace, chart.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteStatus(t *testing.T) {
+	assert := assert.New(t)
+	defer func(writers []StatusWriter) { StatusWriters = writers }(StatusWriters)
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	chart.Status.JobName = "helm-install-traefik"
+
+	var written []*v1.HelmChart
+	StatusWriters = []StatusWriter{StatusWriterFunc(func(chart *v1.HelmChart) error {
+		written = append(written, chart)
+		chart.Status.JobName = ""
+		return nil
+	})}
+	assert.NoError(writeStatus(chart))
+	assert.Len(written, 1)
+	assert.Equal("helm-install-traefik", chart.Status.JobName)
+
+	StatusWriters = append(StatusWriters, StatusWriterFunc(func(*v1.HelmChart) error {
+		return errors.New("unavailable")
+	}))
+	assert.EqualError(writeStatus(chart), "failed to write status of HelmChart kube-system/traefik: unavailable")
+}
@@ -0,0 +1,95 @@
+package helm
+
+import (
+	"sort"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// OnChartSummaryChange enqueues the summary whenever a chart is changed or removed.
+func (c *Controller) OnChartSummaryChange(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+	c.summaryController.Enqueue(SummaryName)
+	return chart, nil
+}
+
+// OnSummaryChange creates or updates the HelmChartSummary with the current state of all charts.
+func (c *Controller) OnSummaryChange(key string, summary *helmv1.HelmChartSummary) (*helmv1.HelmChartSummary, error) {
+	if key != SummaryName {
+		return summary, nil
+	}
+
+	charts, err := c.helmController.Cache().List("", labels.Everything())
+	if err != nil {
+		return summary, err
+	}
+	status := summarize(charts)
+
+	if summary == nil {
+		if DryRun {
+			return nil, nil
+		}
+		return c.summaryController.Create(&helmv1.HelmChartSummary{
+			ObjectMeta: meta.ObjectMeta{Name: SummaryName},
+			Status:     status,
+		})
+	}
+	if equality.Semantic.DeepEqual(summary.Status, status) {
+		return summary, nil
+	}
+
+	summaryCopy := summary.DeepCopy()
+	summaryCopy.Status = status
+	return c.summaryController.UpdateStatus(summaryCopy)
+}
+
+// summarize counts charts by namespace and state, and lists the most recent failures in each namespace.
+func summarize(charts []*helmv1.HelmChart) helmv1.HelmChartSummaryStatus {
+	status := helmv1.HelmChartSummaryStatus{}
+	namespaces := map[string]*helmv1.HelmChartNamespaceSummary{}
+
+	for _, chart := range charts {
+		ns, ok := namespaces[chart.Namespace]
+		if !ok {
+			ns = &helmv1.HelmChartNamespaceSummary{Namespace: chart.Namespace}
+			namespaces[chart.Namespace] = ns
+		}
+
+		ns.Charts++
+		status.Charts++
+		if ConditionReady.IsTrue(chart) {
+			ns.Ready++
+			status.Ready++
+		}
+		if ConditionFailed.IsTrue(chart) {
+			ns.Failed++
+			status.Failed++
+			ns.LastFailures = append(ns.LastFailures, helmv1.HelmChartFailure{
+				Name:           chart.Name,
+				Reason:         ConditionFailed.GetReason(chart),
+				Message:        ConditionFailed.GetMessage(chart),
+				LastUpdateTime: ConditionFailed.GetLastUpdated(chart),
+			})
+		}
+	}
+
+	for _, ns := range namespaces {
+		sort.Slice(ns.LastFailures, func(i, j int) bool {
+			if ns.LastFailures[i].LastUpdateTime != ns.LastFailures[j].LastUpdateTime {
+				return ns.LastFailures[i].LastUpdateTime > ns.LastFailures[j].LastUpdateTime
+			}
+			return ns.LastFailures[i].Name < ns.LastFailures[j].Name
+		})
+		if len(ns.LastFailures) > SummaryMaxFailures {
+			ns.LastFailures = ns.LastFailures[:SummaryMaxFailures]
+		}
+		status.Namespaces = append(status.Namespaces, *ns)
+	}
+	sort.Slice(status.Namespaces, func(i, j int) bool {
+		return status.Namespaces[i].Namespace < status.Namespaces[j].Namespace
+	})
+
+	return status
+}
@@ -0,0 +1,35 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	assert := assert.New(t)
+	ready := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{})
+	ConditionReady.True(ready)
+	failed := v1.NewHelmChart("kube-system", "metrics-server", v1.HelmChart{})
+	ConditionReady.False(failed)
+	ConditionFailed.True(failed)
+	ConditionFailed.Reason(failed, "BackoffLimitExceeded")
+	pending := v1.NewHelmChart("default", "nginx", v1.HelmChart{})
+
+	status := summarize([]*v1.HelmChart{ready, failed, pending})
+	assert.Equal(3, status.Charts)
+	assert.Equal(1, status.Ready)
+	assert.Equal(1, status.Failed)
+	assert.Len(status.Namespaces, 2)
+
+	assert.Equal("default", status.Namespaces[0].Namespace)
+	assert.Equal(1, status.Namespaces[0].Charts)
+	assert.Empty(status.Namespaces[0].LastFailures)
+
+	assert.Equal("kube-system", status.Namespaces[1].Namespace)
+	assert.Equal(2, status.Namespaces[1].Charts)
+	assert.Len(status.Namespaces[1].LastFailures, 1)
+	assert.Equal("metrics-server", status.Namespaces[1].LastFailures[0].Name)
+	assert.Equal("BackoffLimitExceeded", status.Namespaces[1].LastFailures[0].Reason)
+}
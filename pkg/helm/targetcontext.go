@@ -0,0 +1,72 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// targetContext returns the target context with the given name, from the kubeconfig Secrets in the namespace
+// that match the TargetContextSelector. Secrets are checked in name order, so that the same Secret is used if
+// more than one names the same target context.
+func (c *Controller) targetContext(namespace, name string) (*render.TargetContext, error) {
+	if TargetContextSelector == nil {
+		return nil, fmt.Errorf("spec.targetContext %q cannot be used: no target context selector is configured", name)
+	}
+	target, err := c.findTargetContext(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target context %s not found in namespace %s", name, namespace)
+	}
+	return target, nil
+}
+
+// findTargetContext returns the target context with the given name, or nil if there is none.
+func (c *Controller) findTargetContext(namespace, name string) (*render.TargetContext, error) {
+	if TargetContextSelector == nil {
+		return nil, nil
+	}
+	secrets, err := c.secretCache.List(namespace, TargetContextSelector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	for _, secret := range secrets {
+		if target := render.TargetContextFromSecret(secret); target.Name == name {
+			return &target, nil
+		}
+	}
+	return nil, nil
+}
+
+// targetContextGone returns true if the chart is installed into a target context that no longer exists, such as a
+// virtual cluster that was deleted before the chart. The release cannot be uninstalled without its target context,
+// and would otherwise keep the chart's finalizer forever.
+func (c *Controller) targetContextGone(chart *helmv1.HelmChart) (bool, error) {
+	if localRelease(chart) {
+		return false, nil
+	}
+	target, err := c.findTargetContext(chart.Namespace, chart.Spec.TargetContext)
+	return target == nil, err
+}
+
+// targetContextSecret returns true if the Secret is a kubeconfig Secret for the chart's target context, so that
+// charts waiting for a virtual cluster to be created are retried once its kubeconfig Secret exists.
+func targetContextSecret(chart *helmv1.HelmChart, secret *core.Secret) bool {
+	if chart.Spec.TargetContext == "" || TargetContextSelector == nil || !TargetContextSelector.Matches(labels.Set(secret.Labels)) {
+		return false
+	}
+	return render.TargetContextFromSecret(secret).Name == chart.Spec.TargetContext
+}
+
+// localRelease returns false for charts installed into a target context. Their release Secrets, target namespace
+// and release resources are in the target context, not in this cluster, so they cannot be checked by the controller.
+func localRelease(chart *helmv1.HelmChart) bool {
+	return chart.Spec.TargetContext == ""
+}
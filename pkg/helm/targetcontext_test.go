@@ -0,0 +1,92 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	corecontroller "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type secretCache struct {
+	corecontroller.SecretCache
+	secrets []*core.Secret
+}
+
+func (c *secretCache) List(namespace string, selector labels.Selector) ([]*core.Secret, error) {
+	var secrets []*core.Secret
+	for _, secret := range c.secrets {
+		if secret.Namespace == namespace && selector.Matches(labels.Set(secret.Labels)) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
+}
+
+func (c *secretCache) Get(namespace, name string) (*core.Secret, error) {
+	for _, secret := range c.secrets {
+		if secret.Namespace == namespace && secret.Name == name {
+			return secret, nil
+		}
+	}
+	return nil, errors.NewNotFound(core.Resource("secrets"), name)
+}
+
+func TestTargetContext(t *testing.T) {
+	assert := assert.New(t)
+	vcluster := map[string]string{"app": "vcluster"}
+	c := &Controller{secretCache: &secretCache{secrets: []*core.Secret{
+		{ObjectMeta: meta.ObjectMeta{Namespace: "tenants", Name: "vc-tenant-a", Labels: vcluster}},
+		{ObjectMeta: meta.ObjectMeta{Namespace: "tenants", Name: "vc-tenant-b"}},
+		{ObjectMeta: meta.ObjectMeta{Namespace: "other", Name: "vc-tenant-c", Labels: vcluster}},
+	}}}
+
+	_, err := c.targetContext("tenants", "tenant-a")
+	assert.Error(err)
+
+	defer func() { TargetContextSelector = nil }()
+	TargetContextSelector = labels.SelectorFromSet(vcluster)
+	target, err := c.targetContext("tenants", "tenant-a")
+	assert.NoError(err)
+	assert.Equal("vc-tenant-a", target.KubeconfigSecret.Name)
+
+	_, err = c.targetContext("tenants", "tenant-b")
+	assert.EqualError(err, "target context tenant-b not found in namespace tenants")
+	_, err = c.targetContext("tenants", "tenant-c")
+	assert.Error(err)
+
+	chart := v1.NewHelmChart("tenants", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{TargetContext: "tenant-a"}})
+	assert.True(targetContextSecret(chart, c.secretCache.(*secretCache).secrets[0]))
+	assert.False(targetContextSecret(chart, c.secretCache.(*secretCache).secrets[1]))
+	assert.False(localRelease(chart))
+}
+
+func TestTargetContextGone(t *testing.T) {
+	assert := assert.New(t)
+	vcluster := map[string]string{"app": "vcluster"}
+	secrets := &secretCache{secrets: []*core.Secret{
+		{ObjectMeta: meta.ObjectMeta{Namespace: "tenants", Name: "vc-tenant-a", Labels: vcluster}},
+	}}
+	c := &Controller{secretCache: secrets}
+	defer func() { TargetContextSelector = nil }()
+	TargetContextSelector = labels.SelectorFromSet(vcluster)
+
+	chart := v1.NewHelmChart("tenants", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{TargetContext: "tenant-a"}})
+	gone, err := c.targetContextGone(chart)
+	assert.NoError(err)
+	assert.False(gone)
+
+	secrets.secrets = nil
+	gone, err = c.targetContextGone(chart)
+	assert.NoError(err)
+	assert.True(gone)
+
+	chart.Spec.TargetContext = ""
+	gone, err = c.targetContextGone(chart)
+	assert.NoError(err)
+	assert.False(gone)
+}
@@ -0,0 +1,103 @@
+package helm
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	helmcontroller "github.com/k3s-io/helm-controller/pkg/generated/controllers/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// transientRetryJitter is the fraction of the retry interval that is randomly added to each transient retry, so
+	// that charts that failed together during an apiserver outage are not all retried at once
+	transientRetryJitter = 0.5
+	// transientRetryMaxInterval caps the interval between transient retries of a chart
+	transientRetryMaxInterval = 5 * time.Minute
+)
+
+var (
+	transientLock sync.Mutex
+	// transientFailures holds the number of consecutive transient errors returned while handling each chart, keyed
+	// by namespace and name
+	transientFailures = map[string]int{}
+)
+
+// transientError returns true if err is caused by the apiserver, or an admission webhook called by it, being
+// briefly unavailable or overloaded, rather than by anything wrong with the chart.
+func transientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) {
+		return true
+	}
+	if utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// withErrorBudget wraps a chart handler so that transient errors are retried quietly, with jitter and an
+// increasing interval, until the TransientErrorBudget is used up. Up to then the error is only logged at debug
+// level and not returned, so that a short apiserver or webhook outage does not fill the log and the chart's events
+// with failures. Once the budget is used up a warning event is recorded and the error is returned as usual. Any
+// other result resets the budget.
+func (c *Controller) withErrorBudget(handler helmcontroller.HelmChartHandler) helmcontroller.HelmChartHandler {
+	return func(key string, chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+		updated, err := handler(key, chart)
+		if chart == nil {
+			return updated, err
+		}
+		if !transientError(err) {
+			forgetTransient(chart)
+			return updated, err
+		}
+
+		failures := countTransient(chart)
+		if failures > TransientErrorBudget {
+			if failures == TransientErrorBudget+1 {
+				c.recorder.Eventf(chart, core.EventTypeWarning, "APIUnavailable", "Failed to handle HelmChart after %d transient errors: %v", failures, err)
+			}
+			return updated, err
+		}
+
+		retry := TransientRetryInterval
+		for i := 1; i < failures && retry < transientRetryMaxInterval; i++ {
+			retry *= 2
+		}
+		if retry > transientRetryMaxInterval {
+			retry = transientRetryMaxInterval
+		}
+		retry = wait.Jitter(retry, transientRetryJitter)
+		logrus.Debugf("Retrying HelmChart %s in %s after transient error %d of %d: %v", key, retry.Round(time.Millisecond), failures, TransientErrorBudget, err)
+		c.helmController.EnqueueAfter(chart.Namespace, chart.Name, retry)
+		return chart, generic.ErrSkip
+	}
+}
+
+// countTransient records a transient error for the chart, returning the number of consecutive transient errors.
+func countTransient(chart *helmv1.HelmChart) int {
+	transientLock.Lock()
+	defer transientLock.Unlock()
+	key := chart.Namespace + "/" + chart.Name
+	transientFailures[key]++
+	return transientFailures[key]
+}
+
+// forgetTransient resets the transient error count of a chart that has been handled or deleted.
+func forgetTransient(chart *helmv1.HelmChart) {
+	transientLock.Lock()
+	defer transientLock.Unlock()
+	delete(transientFailures, chart.Namespace+"/"+chart.Name)
+}
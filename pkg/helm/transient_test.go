@@ -0,0 +1,83 @@
+package helm
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/rancher/wrangler/pkg/generic"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTransientError(t *testing.T) {
+	assert := assert.New(t)
+	resource := schema.GroupResource{Group: "helm.cattle.io", Resource: "helmcharts"}
+	assert.True(transientError(apierrors.NewTimeoutError("request timed out", 1)))
+	assert.True(transientError(apierrors.NewServerTimeout(resource, "update", 1)))
+	assert.True(transientError(apierrors.NewTooManyRequests("throttled", 1)))
+	assert.True(transientError(apierrors.NewServiceUnavailable("unavailable")))
+	assert.True(transientError(apierrors.NewInternalError(errors.New("failed calling webhook"))))
+	assert.True(transientError(&url.Error{Op: "Get", URL: "https://10.43.0.1:443/apis/batch/v1/namespaces/kube-system/jobs", Err: io.EOF}))
+
+	assert.False(transientError(nil))
+	assert.False(transientError(apierrors.NewNotFound(resource, "traefik")))
+	assert.False(transientError(apierrors.NewConflict(resource, "traefik", errors.New("modified"))))
+	assert.False(transientError(apierrors.NewForbidden(resource, "traefik", errors.New("denied"))))
+	assert.False(transientError(errors.New("invalid values")))
+}
+
+func TestErrorBudget(t *testing.T) {
+	assert := assert.New(t)
+	defer func(budget int, interval time.Duration) {
+		TransientErrorBudget, TransientRetryInterval = budget, interval
+	}(TransientErrorBudget, TransientRetryInterval)
+	TransientErrorBudget = 2
+	TransientRetryInterval = time.Second
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{Chart: "stable/traefik"}})
+	defer forgetTransient(chart)
+	helms := &helmController{}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{helmController: helms, recorder: recorder}
+
+	var err error
+	handler := c.withErrorBudget(func(key string, chart *v1.HelmChart) (*v1.HelmChart, error) {
+		return chart, err
+	})
+
+	err = apierrors.NewTooManyRequests("throttled", 1)
+	for i := 0; i < 2; i++ {
+		_, result := handler("kube-system/traefik", chart)
+		assert.Equal(generic.ErrSkip, result)
+	}
+	assert.Len(helms.enqueued, 2)
+	assert.Empty(recorder.Events)
+
+	// the budget is used up, so the error is returned and reported once
+	for i := 0; i < 2; i++ {
+		_, result := handler("kube-system/traefik", chart)
+		assert.Equal(err, result)
+	}
+	assert.Len(helms.enqueued, 2)
+	if assert.Len(recorder.Events, 1) {
+		assert.Contains(<-recorder.Events, "Warning APIUnavailable Failed to handle HelmChart after 3 transient errors")
+	}
+
+	// success resets the budget
+	err = nil
+	_, result := handler("kube-system/traefik", chart)
+	assert.NoError(result)
+	assert.NotContains(transientFailures, "kube-system/traefik")
+
+	// other errors are returned immediately
+	err = errors.New("invalid values")
+	_, result = handler("kube-system/traefik", chart)
+	assert.Equal(err, result)
+	assert.Len(helms.enqueued, 2)
+}
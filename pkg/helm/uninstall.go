@@ -0,0 +1,133 @@
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	uninstallLock sync.Mutex
+	// uninstallRemainingByKey holds the resources last reported as remaining for charts with UninstallWait set
+	// that are being uninstalled, so that the WaitingForRemoval event is only recorded when they change
+	uninstallRemainingByKey = map[string]string{}
+)
+
+// uninstallResource is a resource of a release being uninstalled, as recorded in the
+// UninstallResourcesAnnotation of the chart.
+type uninstallResource struct {
+	releaseResource
+	UID types.UID
+}
+
+// recordUninstallResources records the resources of the chart's release in the chart's
+// UninstallResourcesAnnotation while the release still exists, so that their removal can be verified once the
+// delete job has succeeded, even if the controller is restarted in between. Only resources that exist and are
+// owned by the release are recorded, along with their UID, so that resources re-created or adopted by another
+// release in the meantime are not waited for. Resources that helm keeps on uninstall are not included.
+func (c *Controller) recordUninstallResources(chart *helmv1.HelmChart) (*helmv1.HelmChart, error) {
+	if !chart.Spec.UninstallWait || !localRelease(chart) {
+		return chart, nil
+	}
+	if _, ok := chart.Annotations[UninstallResourcesAnnotation]; ok {
+		return chart, nil
+	}
+	namespace, name := render.TargetNamespace(chart), render.ReleaseName(chart)
+	current, _, err := c.releaseManifests(namespace, name)
+	if err != nil || current == "" {
+		return chart, err
+	}
+	resources, err := manifestResources(current, true)
+	if err != nil {
+		return chart, err
+	}
+
+	recorded := []uninstallResource{}
+	for _, resource := range resources {
+		object, err := c.getOrphan(namespace, resource)
+		if err != nil {
+			return chart, fmt.Errorf("failed to get %s of HelmChart %s/%s: %w", resource, chart.Namespace, chart.Name, err)
+		}
+		if object == nil || adoptedByOtherRelease(object, namespace, name) {
+			continue
+		}
+		recorded = append(recorded, uninstallResource{releaseResource: resource, UID: object.GetUID()})
+	}
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		return chart, err
+	}
+
+	chartCopy := chart.DeepCopy()
+	if chartCopy.Annotations == nil {
+		chartCopy.Annotations = map[string]string{}
+	}
+	chartCopy.Annotations[UninstallResourcesAnnotation] = string(data)
+	return c.helmController.Update(chartCopy)
+}
+
+// verifyUninstall returns an error, so that the chart's finalizer is kept, while any of the resources recorded
+// for the chart's release still exist after the delete job has succeeded. Workloads that are still terminating,
+// or are held by their own finalizers, would otherwise outlive the chart. A resource only counts as remaining
+// while it is still the object that was recorded, so that objects re-created under the same name do not hold
+// back the chart. If the resources were not recorded, such as when the release did not exist, they are not
+// checked.
+func (c *Controller) verifyUninstall(chart *helmv1.HelmChart) error {
+	if !chart.Spec.UninstallWait || !localRelease(chart) {
+		return nil
+	}
+
+	key := chart.Namespace + "/" + chart.Name
+	data, ok := chart.Annotations[UninstallResourcesAnnotation]
+	if !ok {
+		logrus.Warnf("Unable to verify removal of resources for HelmChart %s: release resources are not known", key)
+		return nil
+	}
+	var resources []uninstallResource
+	if err := json.Unmarshal([]byte(data), &resources); err != nil {
+		logrus.Warnf("Unable to verify removal of resources for HelmChart %s: invalid %s annotation: %v", key, UninstallResourcesAnnotation, err)
+		return nil
+	}
+
+	namespace := render.TargetNamespace(chart)
+	var remaining []string
+	for _, resource := range resources {
+		object, err := c.getOrphan(namespace, resource.releaseResource)
+		if err != nil {
+			return fmt.Errorf("failed to check for removal of %s from HelmChart %s: %w", resource, key, err)
+		}
+		if object != nil && object.GetUID() == resource.UID {
+			remaining = append(remaining, resource.String())
+		}
+	}
+
+	uninstallLock.Lock()
+	defer uninstallLock.Unlock()
+	if len(remaining) == 0 {
+		delete(uninstallRemainingByKey, key)
+		return nil
+	}
+
+	sort.Strings(remaining)
+	message := strings.Join(remaining, ", ")
+	if message != uninstallRemainingByKey[key] {
+		c.recorder.Eventf(chart, core.EventTypeNormal, "WaitingForRemoval", "Waiting for resources of release %s/%s to be removed: %s", namespace, render.ReleaseName(chart), message)
+		uninstallRemainingByKey[key] = message
+	}
+	return fmt.Errorf("waiting for removal of %d resources of HelmChart %s", len(remaining), key)
+}
+
+// forgetUninstall removes the remaining resources last reported for a chart that is no longer being uninstalled.
+func forgetUninstall(chart *helmv1.HelmChart) {
+	uninstallLock.Lock()
+	defer uninstallLock.Unlock()
+	delete(uninstallRemainingByKey, chart.Namespace+"/"+chart.Name)
+}
@@ -0,0 +1,85 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+)
+
+// dynamicClient serves Gets for the namespaced objects in existing, keyed by resource, namespace and name, with
+// the UID of each object.
+type dynamicClient struct {
+	dynamic.Interface
+	existing map[string]types.UID
+}
+
+func (c *dynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &dynamicResource{client: c, resource: resource}
+}
+
+type dynamicResource struct {
+	dynamic.NamespaceableResourceInterface
+	client    *dynamicClient
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+func (r *dynamicResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &dynamicResource{client: r.client, resource: r.resource, namespace: namespace}
+}
+
+func (r *dynamicResource) Get(ctx context.Context, name string, options meta.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if uid, ok := r.client.existing[r.resource.Resource+"/"+r.namespace+"/"+name]; ok {
+		object := &unstructured.Unstructured{}
+		object.SetUID(uid)
+		return object, nil
+	}
+	return nil, errors.NewNotFound(r.resource.GroupResource(), name)
+}
+
+func TestVerifyUninstall(t *testing.T) {
+	assert := assert.New(t)
+	restMapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "apps", Version: "v1"}, {Version: "v1"}})
+	restMapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, apimeta.RESTScopeNamespace)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, apimeta.RESTScopeNamespace)
+	client := &dynamicClient{existing: map[string]types.UID{
+		"deployments/traefik/traefik": "deployment-uid",
+		"services/traefik/traefik":    "new-service-uid",
+	}}
+	recorder := record.NewFakeRecorder(10)
+	c := &Controller{dynamic: client, restMapper: restMapper, recorder: recorder}
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{
+		Chart:           "stable/traefik",
+		TargetNamespace: "traefik",
+		UninstallWait:   true,
+	}})
+	defer forgetUninstall(chart)
+
+	// resources are not known if the release did not exist when the chart was deleted
+	assert.NoError(c.verifyUninstall(chart))
+
+	// the Service was re-created since the resources were recorded, so is not waited for
+	chart.Annotations = map[string]string{UninstallResourcesAnnotation: `[` +
+		`{"Group":"apps","Kind":"Deployment","Namespace":"","Name":"traefik","UID":"deployment-uid"},` +
+		`{"Group":"","Kind":"Service","Namespace":"","Name":"traefik","UID":"service-uid"}]`}
+	assert.EqualError(c.verifyUninstall(chart), "waiting for removal of 1 resources of HelmChart kube-system/traefik")
+	assert.EqualError(c.verifyUninstall(chart), "waiting for removal of 1 resources of HelmChart kube-system/traefik")
+	if assert.Len(recorder.Events, 1) {
+		assert.Equal("Normal WaitingForRemoval Waiting for resources of release traefik/traefik to be removed: Deployment.apps traefik", <-recorder.Events)
+	}
+
+	delete(client.existing, "deployments/traefik/traefik")
+	assert.NoError(c.verifyUninstall(chart))
+	assert.NotContains(uninstallRemainingByKey, "kube-system/traefik")
+}
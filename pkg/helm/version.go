@@ -0,0 +1,105 @@
+package helm
+
+import (
+	"fmt"
+	"strconv"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	core "k8s.io/api/core/v1"
+)
+
+// setReleaseStatus records the revision and chart version of the latest release in the chart's status. The
+// version that was installed may differ from the chart's Version, which may be a constraint, may be empty to
+// install the latest version, or may resolve to a pre-release version when Devel is set. The source of the
+// installed chart is recorded alongside, so that the exact chart a release came from can be audited.
+func (c *Controller) setReleaseStatus(chart *helmv1.HelmChart) error {
+	if !localRelease(chart) {
+		return nil
+	}
+	namespace, name := render.TargetNamespace(chart), render.ReleaseName(chart)
+	secrets, err := c.releaseSecrets(namespace, name)
+	if err != nil || len(secrets) == 0 {
+		return err
+	}
+	release, err := decodeRelease(secrets[0].Data["release"])
+	if err != nil {
+		return fmt.Errorf("failed to decode release Secret %s/%s: %w", namespace, secrets[0].Name, err)
+	}
+	chart.Status.ChartVersion = release.Chart.Metadata.Version
+	chart.Status.Source = releaseSource(chart, release)
+	chart.Status.ReleaseRevision, _ = strconv.Atoi(secrets[0].Labels["version"])
+	return nil
+}
+
+// verifyReleaseExists sets the ReleaseVerified condition of an installed chart, based on whether its release
+// still exists, is deployed, and is at the revision the controller last installed. This detects releases that
+// were uninstalled or changed outside of the controller, or whose release Secrets were lost. The chart is
+// requeued so that the release is checked again after ReleaseVerifyInterval.
+func (c *Controller) verifyReleaseExists(chart *helmv1.HelmChart) error {
+	if ReleaseVerifyInterval <= 0 || chart.DeletionTimestamp != nil || !ConditionReady.IsTrue(chart) || !localRelease(chart) {
+		return nil
+	}
+
+	// charts installed before the revision was recorded
+	if chart.Status.ReleaseRevision == 0 {
+		if err := c.setReleaseStatus(chart); err != nil {
+			return err
+		}
+	}
+
+	namespace, name := render.TargetNamespace(chart), render.ReleaseName(chart)
+	secrets, err := c.releaseSecrets(namespace, name)
+	if err != nil {
+		return err
+	}
+	c.helmController.EnqueueAfter(chart.Namespace, chart.Name, ReleaseVerifyInterval)
+
+	reason, message := releaseProblem(secrets, chart.Status.ReleaseRevision)
+	if reason == "" {
+		ConditionReleaseVerified.True(chart)
+		ConditionReleaseVerified.Reason(chart, "")
+		ConditionReleaseVerified.Message(chart, "")
+		return nil
+	}
+	if ConditionReleaseVerified.GetReason(chart) != reason {
+		c.recorder.Eventf(chart, core.EventTypeWarning, reason, "Release %s/%s %s", namespace, name, message)
+	}
+	ConditionReleaseVerified.False(chart)
+	ConditionReleaseVerified.Reason(chart, reason)
+	ConditionReleaseVerified.Message(chart, message)
+	return nil
+}
+
+// releaseProblem returns the reason and message for the ReleaseVerified condition if the latest release, from
+// the release Secrets sorted latest revision first, is missing, not deployed, or not at the expected revision.
+func releaseProblem(secrets []*core.Secret, revision int) (string, string) {
+	if len(secrets) == 0 {
+		return "ReleaseNotFound", "does not exist"
+	}
+	latest, _ := strconv.Atoi(secrets[0].Labels["version"])
+	if revision != 0 && latest != revision {
+		return "RevisionChanged", fmt.Sprintf("is at revision %d, but revision %d was installed", latest, revision)
+	}
+	if status := secrets[0].Labels["status"]; status != "deployed" {
+		return "ReleaseNotDeployed", fmt.Sprintf("revision %d has status %s", latest, status)
+	}
+	return "", ""
+}
+
+// releaseSource returns the repo and chart that the chart was installed from, after any repo mirror has been
+// applied, along with the version and digests of the chart in the release. Charts installed from ChartContent or
+// a ChartContentSecret have no repo, and are identified by the name of the chart in the release.
+func releaseSource(chart *helmv1.HelmChart, release *helmRelease) *helmv1.HelmChartSource {
+	repo, chartRef := render.RepoSource(chart, render.Options{RepoMirrors: RepoMirrors})
+	if chartRef == "" {
+		chartRef = release.Chart.Metadata.Name
+	}
+	return &helmv1.HelmChartSource{
+		Repo:          repo,
+		Chart:         chartRef,
+		Version:       release.Chart.Metadata.Version,
+		ArchiveDigest: render.ChartArchiveDigest(chart),
+		ChartDigest:   release.Chart.Digest,
+	}
+}
@@ -0,0 +1,61 @@
+package helm
+
+import (
+	"testing"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReleaseProblem(t *testing.T) {
+	assert := assert.New(t)
+	reason, _ := releaseProblem(nil, 2)
+	assert.Equal("ReleaseNotFound", reason)
+
+	secrets := []*core.Secret{{ObjectMeta: meta.ObjectMeta{Labels: map[string]string{"version": "2", "status": "deployed"}}}}
+	reason, _ = releaseProblem(secrets, 2)
+	assert.Empty(reason)
+	reason, _ = releaseProblem(secrets, 0)
+	assert.Empty(reason)
+
+	reason, message := releaseProblem(secrets, 1)
+	assert.Equal("RevisionChanged", reason)
+	assert.Equal("is at revision 2, but revision 1 was installed", message)
+
+	secrets[0].Labels["status"] = "failed"
+	reason, _ = releaseProblem(secrets, 2)
+	assert.Equal("ReleaseNotDeployed", reason)
+}
+
+func TestReleaseSource(t *testing.T) {
+	assert := assert.New(t)
+	release := &helmRelease{}
+	release.Chart.Metadata.Name = "traefik"
+	release.Chart.Metadata.Version = "10.3.0"
+	release.Chart.Digest = "sha256:abcd"
+
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{Spec: v1.HelmChartSpec{
+		Repo:          "https://helm.traefik.io/traefik",
+		Chart:         "traefik",
+		Version:       "~10.3",
+		ChartChecksum: "sha256:1234",
+	}})
+	defer func(mirrors map[string]string) { RepoMirrors = mirrors }(RepoMirrors)
+	RepoMirrors = map[string]string{"https://helm.traefik.io": "https://mirror.local"}
+	assert.Equal(&v1.HelmChartSource{
+		Repo:          "https://mirror.local/traefik",
+		Chart:         "traefik",
+		Version:       "10.3.0",
+		ArchiveDigest: "sha256:1234",
+		ChartDigest:   "sha256:abcd",
+	}, releaseSource(chart, release))
+
+	chart.Spec.ChartChecksum = ""
+	chart.Spec.ChartContent = "H4sIAAAAAAAA"
+	source := releaseSource(chart, release)
+	assert.Empty(source.Repo)
+	assert.Equal("traefik", source.Chart)
+	assert.NotEmpty(source.ArchiveDigest)
+}
@@ -0,0 +1,160 @@
+// Package metrics exposes controller metrics in the Prometheus text exposition format. Only labelled gauges
+// are needed, so they are implemented here rather than pulling in the Prometheus client library.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const Path = "/metrics"
+
+// ChartLabels are the labels of the per-chart metrics, identifying the chart and the helm chart it installs.
+var ChartLabels = []string{"namespace", "name", "chart", "version"}
+
+var (
+	// JobDuration is the time taken by the chart's last completed install job.
+	JobDuration = NewGaugeVec("helm_controller_chart_job_duration_seconds",
+		"Time taken by the last completed install job for the chart.", ChartLabels)
+	// LastSuccess is the time that the chart was last successfully installed. The time since the last successful
+	// reconcile is time() minus this metric.
+	LastSuccess = NewGaugeVec("helm_controller_chart_last_success_timestamp_seconds",
+		"Unix time at which the install job for the chart last succeeded.", ChartLabels)
+	// ConsecutiveFailures is the number of failed install attempts since the chart was last installed successfully.
+	ConsecutiveFailures = NewGaugeVec("helm_controller_chart_consecutive_failures",
+		"Number of failed attempts to install the chart since it was last installed successfully.", ChartLabels)
+
+	// JobSlow is 1 if the chart's current install job has been running for longer than expected, and 0 otherwise.
+	JobSlow = NewGaugeVec("helm_controller_chart_job_slow",
+		"Whether the current install job for the chart has been running for longer than expected.", ChartLabels)
+
+	// ConfigDrift is 1 if the chart's desired configuration has not been installed successfully for longer than the
+	// drift grace period, and 0 otherwise.
+	ConfigDrift = NewGaugeVec("helm_controller_chart_config_drift",
+		"Whether the desired configuration of the chart has differed from its last successfully installed configuration for longer than the grace period.", ChartLabels)
+
+	registry = []*GaugeVec{JobDuration, LastSuccess, ConsecutiveFailures, JobSlow, ConfigDrift}
+
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// Labels maps label names to values.
+type Labels map[string]string
+
+// GaugeVec is a set of gauges with the same name, partitioned by label values.
+type GaugeVec struct {
+	name   string
+	help   string
+	labels []string
+
+	lock   sync.Mutex
+	values map[string]float64
+}
+
+// NewGaugeVec returns a GaugeVec with the given name, help text, and label names.
+func NewGaugeVec(name, help string, labels []string) *GaugeVec {
+	return &GaugeVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: map[string]float64{},
+	}
+}
+
+// Set sets the value of the gauge with the given labels.
+func (g *GaugeVec) Set(labels Labels, value float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.values[g.series(labels)] = value
+}
+
+// Delete removes the gauge with the given labels.
+func (g *GaugeVec) Delete(labels Labels) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.values, g.series(labels))
+}
+
+// series formats the labels in the order they were declared, as they appear in the exposition format.
+func (g *GaugeVec) series(labels Labels) string {
+	pairs := make([]string, 0, len(g.labels))
+	for _, name := range g.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(labels[name])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (g *GaugeVec) write(w io.Writer) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name); err != nil {
+		return err
+	}
+	series := make([]string, 0, len(g.values))
+	for s := range g.values {
+		series = append(series, s)
+	}
+	sort.Strings(series)
+	for _, s := range series {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", g.name, s, strconv.FormatFloat(g.values[s], 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteChart removes the series for a chart, for example when the chart is deleted or the chart or version
+// it installs is changed.
+func DeleteChart(labels Labels) {
+	JobDuration.Delete(labels)
+	LastSuccess.Delete(labels)
+	ConsecutiveFailures.Delete(labels)
+	JobSlow.Delete(labels)
+	ConfigDrift.Delete(labels)
+}
+
+// Handler serves all metrics in the Prometheus text exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, g := range registry {
+			if err := g.write(rw); err != nil {
+				logrus.Debugf("Failed to write metrics: %v", err)
+				return
+			}
+		}
+	})
+}
+
+// ListenAndServe serves the metrics over HTTP on the given address until the context is cancelled.
+func ListenAndServe(ctx context.Context, address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			logrus.Errorf("Failed to shut down metrics server: %v", err)
+		}
+	}()
+
+	logrus.Infof("Starting helm-controller metrics server on %s", address)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
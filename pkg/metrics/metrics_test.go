@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	assert := assert.New(t)
+	labels := Labels{"namespace": "kube-system", "name": "traefik", "chart": `stable/"traefik"`, "version": "1.0.0"}
+	ConsecutiveFailures.Set(labels, 2)
+	defer DeleteChart(labels)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", Path, nil))
+	body := recorder.Body.String()
+	assert.Contains(body, "# TYPE helm_controller_chart_consecutive_failures gauge\n")
+	assert.Contains(body, `helm_controller_chart_consecutive_failures{namespace="kube-system",name="traefik",chart="stable/\"traefik\"",version="1.0.0"} 2`+"\n")
+
+	DeleteChart(labels)
+	recorder = httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", Path, nil))
+	assert.NotContains(recorder.Body.String(), "traefik")
+}
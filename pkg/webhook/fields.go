@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxSuggestionDistance is the largest edit distance between an unknown field and a known field for the known
+// field to be suggested in its place.
+const maxSuggestionDistance = 2
+
+// unknownFieldWarnings warns about fields set in the chart's spec that are not part of the HelmChart schema, and
+// so are silently ignored, such as a misspelt valuesContent. The API server prunes unknown fields before the
+// webhook is called, so the chart is also checked as last applied by kubectl. Only client-side kubectl apply
+// records the last applied chart, so charts created or applied any other way are not warned about.
+func unknownFieldWarnings(raw []byte) []string {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil
+	}
+	paths := unknownFields("spec", object["spec"], reflect.TypeOf(helmv1.HelmChartSpec{}))
+
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			if lastApplied, ok := annotations[corev1.LastAppliedConfigAnnotation].(string); ok {
+				applied := map[string]interface{}{}
+				if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil {
+					paths = append(paths, unknownFields("spec", applied["spec"], reflect.TypeOf(helmv1.HelmChartSpec{}))...)
+				}
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	var warnings []string
+	for i, path := range paths {
+		if i > 0 && paths[i-1] == path {
+			continue
+		}
+		warnings = append(warnings, path)
+	}
+	return warnings
+}
+
+// unknownFields returns a warning for each key of value, and of the objects nested within it, that does not
+// match a field of typ. Maps and fields that are not objects, such as durations, are not checked.
+func unknownFields(path string, value interface{}, typ reflect.Type) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+		fields := jsonFields(typ)
+		var warnings []string
+		for key, v := range value {
+			field, ok := fields[key]
+			if !ok {
+				warnings = append(warnings, unknownFieldWarning(path, key, fields))
+				continue
+			}
+			warnings = append(warnings, unknownFields(path+"."+key, v, field)...)
+		}
+		return warnings
+	case []interface{}:
+		if typ.Kind() != reflect.Slice {
+			return nil
+		}
+		var warnings []string
+		for i, v := range value {
+			warnings = append(warnings, unknownFields(fmt.Sprintf("%s[%d]", path, i), v, typ.Elem())...)
+		}
+		return warnings
+	}
+	return nil
+}
+
+func unknownFieldWarning(path, key string, fields map[string]reflect.Type) string {
+	warning := fmt.Sprintf("%s.%s is not a known field, and is ignored", path, key)
+	if suggestion := suggestField(key, fields); suggestion != "" {
+		warning += fmt.Sprintf("; did you mean %s.%s?", path, suggestion)
+	}
+	return warning
+}
+
+// jsonFields returns the types of the fields of a struct, keyed by their JSON names. Fields of inlined structs
+// are included as fields of the struct itself.
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" && field.Anonymous {
+			for k, v := range jsonFields(field.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// suggestField returns the known field closest to key, ignoring case, if it is close enough to be a likely typo.
+func suggestField(key string, fields map[string]reflect.Type) string {
+	var suggestion string
+	best := maxSuggestionDistance + 1
+	for name := range fields {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < best || (distance == best && name < suggestion) {
+			suggestion, best = name, distance
+		}
+	}
+	if best > maxSuggestionDistance {
+		return ""
+	}
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// Mutate writes the controller-level defaults into HelmChart resources, so that the effective
+// configuration is visible on the resource instead of being resolved by the controller at job creation.
+func Mutate(request *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	if request.Kind.Kind != "HelmChart" || request.Operation == admissionv1.Delete {
+		return patchResponse(nil)
+	}
+
+	chart := &helmv1.HelmChart{}
+	if err := json.Unmarshal(request.Object.Raw, chart); err != nil {
+		return nil, fmt.Errorf("failed to decode HelmChart: %w", err)
+	}
+
+	return patchResponse(defaults(chart))
+}
+
+// defaults returns patch operations that set any unset spec fields that the controller would otherwise default.
+// The job image is not written, as charts that set spec.jobImage keep it when the controller's default changes;
+// the image that the controller resolves is recorded in status.jobImage instead. The timeout is not written either,
+// as it would add an active deadline to the job of every chart that does not set one, re-running all of them.
+func defaults(chart *helmv1.HelmChart) []patchOperation {
+	var patch []patchOperation
+
+	if chart.Spec.Chart == "" && chart.Spec.ChartContent == "" && chart.Spec.ChartContentSecret == nil {
+		return patch
+	}
+	if _, ok := chart.Annotations[helm.Unmanaged]; ok {
+		return patch
+	}
+
+	if chart.Spec.FailurePolicy == "" {
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  "/spec/failurePolicy",
+			Value: helm.DefaultFailurePolicy,
+		})
+	}
+
+	return patch
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMutateDefaults(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart: "stable/traefik",
+		},
+	})
+
+	response, err := Mutate(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	var patch []patchOperation
+	assert.NoError(json.Unmarshal(response.Patch, &patch))
+	assert.Len(patch, 1)
+	assert.Equal("/spec/failurePolicy", patch[0].Path)
+	assert.Equal(helm.DefaultFailurePolicy, patch[0].Value)
+}
+
+func TestMutateNoDefaults(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:         "stable/traefik",
+			JobImage:      "custom/klipper-helm:latest",
+			FailurePolicy: helm.FailurePolicyAbort,
+			Timeout:       &meta.Duration{Duration: time.Minute},
+		},
+	})
+
+	response, err := Mutate(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Nil(response.Patch)
+}
+
+func request(chart *v1.HelmChart) *admissionv1.AdmissionRequest {
+	raw, _ := json.Marshal(chart)
+	return &admissionv1.AdmissionRequest{
+		Kind:      meta.GroupVersionKind{Group: "helm.cattle.io", Version: "v1", Kind: "HelmChart"},
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
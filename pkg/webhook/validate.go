@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	helmv1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm"
+	"github.com/k3s-io/helm-controller/pkg/helm/render"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// Validate returns an admission func that rejects updates to HelmChart resources that change fields the chart
+// does not allow to be changed, HelmChart resources with set values, field references, values content, chart
+// content, chart checksums, timeouts, helm timeouts, helm plugins, service accounts, restart policies, failure
+// policy retries, node names, target contexts or common labels that cannot be used, and HelmChart resources that
+// reference a HelmChartConfig in another namespace that the requesting user is not allowed to read.
+func Validate(accessReviews authorizationclient.SubjectAccessReviewInterface) admitFunc {
+	return func(request *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+		if request.Kind.Kind != "HelmChart" || (request.Operation != admissionv1.Create && request.Operation != admissionv1.Update) {
+			return &admissionv1.AdmissionResponse{Allowed: true}, nil
+		}
+
+		if err := validateTimeoutFormat(request.Object.Raw); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		chart := &helmv1.HelmChart{}
+		if err := json.Unmarshal(request.Object.Raw, chart); err != nil {
+			return nil, fmt.Errorf("failed to decode HelmChart: %w", err)
+		}
+		oldChart := &helmv1.HelmChart{}
+		if request.Operation == admissionv1.Update {
+			if err := json.Unmarshal(request.OldObject.Raw, oldChart); err != nil {
+				return nil, fmt.Errorf("failed to decode old HelmChart: %w", err)
+			}
+			if err := validateUpdate(oldChart, chart); err != nil {
+				return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+			}
+		}
+
+		if err := render.ValidateSet(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateSetFieldRefs(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateChartContent(chart, oldChart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateTimeout(chart, helm.MaxTimeout); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateValuesContent(chart, nil); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateHelmPlugins(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateChartChecksum(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateServiceAccount(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateRestartPolicy(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidatePodFailurePolicy(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateCredentialsMountMode(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateTargetNamespacePolicy(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateFailurePolicyRetries(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateNodeName(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateTargetContext(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := render.ValidateCommonMetadata(chart); err != nil {
+			return denyResponse(err, meta.StatusReasonInvalid, http.StatusUnprocessableEntity), nil
+		}
+		if err := validateConfigRef(accessReviews, request.UserInfo, oldChart, chart); err != nil {
+			return denyResponse(err, meta.StatusReasonForbidden, http.StatusForbidden), nil
+		}
+		warnings := append(sourceWarnings(chart), setOverrideWarnings(chart)...)
+		warnings = append(warnings, bootstrapWeightWarnings(chart)...)
+		warnings = append(warnings, unknownFieldWarnings(request.Object.Raw)...)
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}, nil
+	}
+}
+
+// validateTimeoutFormat checks that the chart's timeout, helm timeout and uninstall timeout are duration strings,
+// such as 5m or 1h30m. Charts with timeouts in any other format cannot be decoded, and would otherwise be rejected
+// with a less helpful error.
+func validateTimeoutFormat(raw []byte) error {
+	object := struct {
+		Spec struct {
+			Timeout          json.RawMessage `json:"timeout"`
+			HelmTimeout      json.RawMessage `json:"helmTimeout"`
+			UninstallTimeout json.RawMessage `json:"uninstallTimeout"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil
+	}
+	if err := validateDurationFormat("spec.timeout", object.Spec.Timeout); err != nil {
+		return err
+	}
+	if err := validateDurationFormat("spec.helmTimeout", object.Spec.HelmTimeout); err != nil {
+		return err
+	}
+	return validateDurationFormat("spec.uninstallTimeout", object.Spec.UninstallTimeout)
+}
+
+func validateDurationFormat(field string, raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var timeout string
+	if err := json.Unmarshal(raw, &timeout); err != nil {
+		return fmt.Errorf("%s must be a duration string, such as 5m or 1h30m, not %s", field, raw)
+	}
+	if _, err := time.ParseDuration(timeout); err != nil {
+		return fmt.Errorf("%s %q must be a duration string, such as 5m or 1h30m", field, timeout)
+	}
+	return nil
+}
+
+// sourceWarnings warns about fields that select where the chart is installed from, but are ignored because a
+// source with higher precedence is set. These charts are allowed, as existing charts may rely on the precedence.
+func sourceWarnings(chart *helmv1.HelmChart) []string {
+	var warnings []string
+	for _, field := range render.IgnoredSourceFields(chart) {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored, as the chart is installed from spec.%s", field, render.ChartSource(chart)))
+	}
+	return warnings
+}
+
+// setOverrideWarnings warns about keys set by both the set fields and the values content of the chart, as it is
+// often not clear which takes precedence. The values content of the chart's config is not known here.
+func setOverrideWarnings(chart *helmv1.HelmChart) []string {
+	var warnings []string
+	for _, override := range render.SetOverrides(chart, nil) {
+		warnings = append(warnings, fmt.Sprintf("%s overrides the same key in spec.valuesContent, as set values take precedence over values content", override))
+	}
+	return warnings
+}
+
+// bootstrapWeightWarnings warns about a bootstrap weight set on a chart that is not a bootstrap chart, as only
+// bootstrap charts are ordered by weight.
+func bootstrapWeightWarnings(chart *helmv1.HelmChart) []string {
+	if chart.Spec.BootstrapWeight == 0 || chart.Spec.Bootstrap {
+		return nil
+	}
+	return []string{"spec.bootstrapWeight is ignored, as spec.bootstrap is not set"}
+}
+
+// validateUpdate rejects changes to the target namespace and release name of charts whose TargetNamespacePolicy is
+// reject. The policy is read from the existing chart, so that it must be changed in an update of its own before the
+// target namespace or release name can be changed.
+func validateUpdate(oldChart, chart *helmv1.HelmChart) error {
+	if oldChart.Spec.TargetNamespacePolicy == helm.TargetNamespacePolicyReject && render.TargetNamespace(oldChart) != render.TargetNamespace(chart) {
+		return fmt.Errorf("spec.targetNamespace cannot be changed from %s to %s when spec.targetNamespacePolicy is %s",
+			render.TargetNamespace(oldChart), render.TargetNamespace(chart), helm.TargetNamespacePolicyReject)
+	}
+	if oldChart.Spec.TargetNamespacePolicy == helm.TargetNamespacePolicyReject && render.ReleaseName(oldChart) != render.ReleaseName(chart) {
+		return fmt.Errorf("spec.releaseName cannot be changed from %s to %s when spec.targetNamespacePolicy is %s",
+			render.ReleaseName(oldChart), render.ReleaseName(chart), helm.TargetNamespacePolicyReject)
+	}
+	return nil
+}
+
+// validateConfigRef checks that the user creating or updating a chart is allowed to read the HelmChartConfig
+// that the chart references, if it is in another namespace. Otherwise, any user allowed to create charts could
+// use a config from a namespace they have no access to. Unchanged references are not checked again.
+func validateConfigRef(accessReviews authorizationclient.SubjectAccessReviewInterface, user authenticationv1.UserInfo, oldChart, chart *helmv1.HelmChart) error {
+	namespace, name := helm.ConfigKey(chart)
+	if namespace == chart.Namespace {
+		return nil
+	}
+	if oldNamespace, oldName := helm.ConfigKey(oldChart); oldNamespace == namespace && oldName == name {
+		return nil
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := accessReviews.Create(context.TODO(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Group:     helmv1.SchemeGroupVersion.Group,
+				Resource:  "helmchartconfigs",
+				Name:      name,
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	}, meta.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to check access to HelmChartConfig %s/%s: %w", namespace, name, err)
+	}
+	if !review.Status.Allowed {
+		return fmt.Errorf("spec.helmChartConfigRef: user %s is not allowed to get HelmChartConfig %s/%s", user.Username, namespace, name)
+	}
+	return nil
+}
+
+func denyResponse(err error, reason meta.StatusReason, code int32) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Result: &meta.Status{
+			Status:  meta.StatusFailure,
+			Message: err.Error(),
+			Reason:  reason,
+			Code:    code,
+		},
+	}
+}
@@ -0,0 +1,400 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/k3s-io/helm-controller/pkg/apis/helm.cattle.io/v1"
+	"github.com/k3s-io/helm-controller/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateTargetNamespacePolicy(t *testing.T) {
+	assert := assert.New(t)
+	oldChart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:                 "stable/traefik",
+			TargetNamespacePolicy: helm.TargetNamespacePolicyReject,
+		},
+	})
+	chart := oldChart.DeepCopy()
+	chart.Spec.TargetNamespace = "traefik"
+
+	response, err := Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+
+	chart.Spec.TargetNamespace = "kube-system"
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	// the policy cannot be relaxed in the same update that changes the target namespace
+	chart.Spec.TargetNamespace = "traefik"
+	chart.Spec.TargetNamespacePolicy = helm.TargetNamespacePolicyMigrate
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+
+	chart.Spec.TargetNamespace = "kube-system"
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	oldChart = chart.DeepCopy()
+	chart.Spec.TargetNamespace = "traefik"
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+}
+
+func TestValidateReleaseName(t *testing.T) {
+	assert := assert.New(t)
+	oldChart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:                 "stable/traefik",
+			TargetNamespacePolicy: helm.TargetNamespacePolicyReject,
+		},
+	})
+	chart := oldChart.DeepCopy()
+	chart.Spec.ReleaseName = "ingress"
+
+	response, err := Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+
+	chart.Spec.ReleaseName = "traefik"
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	// the policy cannot be relaxed in the same update that changes the release name
+	chart.Spec.ReleaseName = "ingress"
+	chart.Spec.TargetNamespacePolicy = helm.TargetNamespacePolicyIgnore
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+}
+
+func TestValidateSet(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:   "stable/traefik",
+			SetJSON: map[string]string{"ports,web": `{"port": 8000}`},
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+
+	chart.Spec.SetJSON = map[string]string{"ports.web": `{"port": 8000}`}
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+}
+
+func TestValidateTimeout(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:   "stable/traefik",
+			Timeout: &meta.Duration{Duration: 10 * time.Minute},
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	defer func(max time.Duration) { helm.MaxTimeout = max }(helm.MaxTimeout)
+	helm.MaxTimeout = 5 * time.Minute
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+
+	for _, timeout := range []string{`"10 minutes"`, `600`} {
+		createRequest := request(chart)
+		createRequest.Object.Raw = []byte(strings.Replace(string(createRequest.Object.Raw), `"10m0s"`, timeout, 1))
+		response, err = Validate(&accessReviews{})(createRequest)
+		assert.NoError(err)
+		assert.False(response.Allowed)
+		assert.Contains(response.Result.Message, "duration string")
+	}
+}
+
+func TestValidateChartContent(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			ChartContent: "YXBpVmVyc2lvbjogdjI=",
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+
+	// charts that already have the content can still be updated, as long as the content is not changed
+	oldChart := chart.DeepCopy()
+	chart.Labels = map[string]string{"team": "platform"}
+	response, err = Validate(&accessReviews{})(updateRequest(oldChart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+}
+
+func TestValidateCredentialsMountMode(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:                "stable/traefik",
+			CredentialsMountMode: "file",
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	chart.Spec.CredentialsMountMode = "files"
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+	assert.Equal(`spec.credentialsMountMode must be env or file, not "files"`, response.Result.Message)
+}
+
+func TestValidateTargetNamespacePolicyValue(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:                 "stable/traefik",
+			TargetNamespacePolicy: "migrate",
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	chart.Spec.TargetNamespacePolicy = "rejct"
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+	assert.Equal(`spec.targetNamespacePolicy must be ignore, reject or migrate, not "rejct"`, response.Result.Message)
+}
+
+func TestValidatePodFailurePolicy(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:         "stable/traefik",
+			RestartPolicy: "Never",
+			PodFailurePolicy: &v1.HelmChartPodFailurePolicy{Rules: []v1.HelmChartPodFailurePolicyRule{{
+				Action:      "FailJob",
+				OnExitCodes: &v1.HelmChartPodFailurePolicyOnExitCodes{Operator: "In", Values: []int32{3}},
+			}}},
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	chart.Spec.RestartPolicy = ""
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+	assert.Equal("spec.podFailurePolicy can only be set when spec.restartPolicy is Never", response.Result.Message)
+}
+
+func TestValidateFailurePolicyRetries(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:                "stable/traefik",
+			FailurePolicyRetries: pointer.Int32Ptr(0),
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	chart.Spec.FailurePolicyRetries = pointer.Int32Ptr(-1)
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+	assert.Equal("spec.failurePolicyRetries must not be negative, not -1", response.Result.Message)
+}
+
+func TestValidateHelmPlugins(t *testing.T) {
+	assert := assert.New(t)
+	checksum := "sha256:" + strings.Repeat("0123456789abcdef", 4)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:       "stable/traefik",
+			HelmPlugins: []v1.HelmPlugin{{URL: "oci://registry.example.com/plugins/secrets:4.5.0", Checksum: checksum}},
+		},
+	})
+
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	chart.Spec.HelmPlugins[0].URL = "registry.example.com/plugins/secrets:4.5.0"
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(422), response.Result.Code)
+}
+
+func TestValidateConfigRef(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart: "stable/traefik",
+			HelmChartConfigRef: &v1.HelmChartConfigReference{
+				Namespace: "helm-admin",
+				Name:      "traefik-defaults",
+			},
+		},
+	})
+	createRequest := request(chart)
+	createRequest.UserInfo.Username = "developer"
+
+	reviews := &accessReviews{}
+	response, err := Validate(reviews)(createRequest)
+	assert.NoError(err)
+	assert.False(response.Allowed)
+	assert.Equal(int32(403), response.Result.Code)
+	assert.Len(reviews.reviews, 1)
+	assert.Equal("developer", reviews.reviews[0].Spec.User)
+	assert.Equal("helm-admin", reviews.reviews[0].Spec.ResourceAttributes.Namespace)
+	assert.Equal("traefik-defaults", reviews.reviews[0].Spec.ResourceAttributes.Name)
+
+	reviews = &accessReviews{allowed: true}
+	response, err = Validate(reviews)(createRequest)
+	assert.NoError(err)
+	assert.True(response.Allowed)
+
+	// unchanged references are not checked again
+	reviews = &accessReviews{}
+	response, err = Validate(reviews)(updateRequest(chart, chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Empty(reviews.reviews)
+
+	// references within the chart namespace are not checked
+	chart.Spec.HelmChartConfigRef.Namespace = ""
+	response, err = Validate(reviews)(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Empty(reviews.reviews)
+}
+
+func TestSourceWarnings(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:        "stable/traefik",
+			ChartContent: "H4sIAAAAAAAA",
+		},
+	})
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Equal([]string{"spec.chart is ignored, as the chart is installed from spec.chartContent"}, response.Warnings)
+}
+
+func TestSetOverrideWarnings(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart:         "stable/traefik",
+			ValuesContent: "image:\n  tag: v1\nports:\n- 80\n",
+			Set: map[string]intstr.IntOrString{
+				"image.tag":  intstr.FromString("v2"),
+				"image.pull": intstr.FromString("Always"),
+				"ports[0]":   intstr.FromInt(8080),
+			},
+		},
+	})
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Equal([]string{
+		"spec.set key \"image.tag\" overrides the same key in spec.valuesContent, as set values take precedence over values content",
+		"spec.set key \"ports[0]\" overrides the same key in spec.valuesContent, as set values take precedence over values content",
+	}, response.Warnings)
+}
+
+func TestBootstrapWeightWarnings(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{Chart: "stable/traefik", BootstrapWeight: 10},
+	})
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Equal([]string{"spec.bootstrapWeight is ignored, as spec.bootstrap is not set"}, response.Warnings)
+
+	chart.Spec.Bootstrap = true
+	response, err = Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.Empty(response.Warnings)
+}
+
+func TestUnknownFieldWarnings(t *testing.T) {
+	assert := assert.New(t)
+	chart := v1.NewHelmChart("kube-system", "traefik", v1.HelmChart{
+		Spec: v1.HelmChartSpec{
+			Chart: "stable/traefik",
+		},
+	})
+	chart.Annotations = map[string]string{
+		core.LastAppliedConfigAnnotation: `{"spec":{"chart":"stable/traefik","valueContent":"a: b","jobTolerations":[{"key":"x","efect":"NoSchedule"}],"set":{"foo":"bar"},"frobnicate":true}}`,
+	}
+	response, err := Validate(&accessReviews{})(request(chart))
+	assert.NoError(err)
+	assert.True(response.Allowed)
+	assert.Equal([]string{
+		"spec.frobnicate is not a known field, and is ignored",
+		"spec.jobTolerations[0].efect is not a known field, and is ignored; did you mean spec.jobTolerations[0].effect?",
+		"spec.valueContent is not a known field, and is ignored; did you mean spec.valuesContent?",
+	}, response.Warnings)
+}
+
+type accessReviews struct {
+	allowed bool
+	reviews []*authorizationv1.SubjectAccessReview
+}
+
+func (a *accessReviews) Create(_ context.Context, review *authorizationv1.SubjectAccessReview, _ meta.CreateOptions) (*authorizationv1.SubjectAccessReview, error) {
+	a.reviews = append(a.reviews, review)
+	review = review.DeepCopy()
+	review.Status.Allowed = a.allowed
+	return review, nil
+}
+
+func updateRequest(oldChart, chart *v1.HelmChart) *admissionv1.AdmissionRequest {
+	request := request(chart)
+	request.Operation = admissionv1.Update
+	request.OldObject.Raw, _ = json.Marshal(oldChart)
+	return request
+}
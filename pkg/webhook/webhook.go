@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	MutatePath   = "/v1/mutate"
+	ValidatePath = "/v1/validate"
+)
+
+type admitFunc func(request *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)
+
+// ListenAndServe serves the admission webhooks over TLS on the given address until the context is cancelled.
+func ListenAndServe(ctx context.Context, k8s kubernetes.Interface, address, certFile, keyFile string) error {
+	mux := http.NewServeMux()
+	mux.Handle(MutatePath, handler(Mutate))
+	mux.Handle(ValidatePath, handler(Validate(k8s.AuthorizationV1().SubjectAccessReviews())))
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			logrus.Errorf("Failed to shut down webhook server: %v", err)
+		}
+	}()
+
+	logrus.Infof("Starting helm-controller webhook on %s", address)
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func handler(admit admitFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(rw, "admission review contains no request", http.StatusBadRequest)
+			return
+		}
+
+		response, err := admit(review.Request)
+		if err != nil {
+			response = &admissionv1.AdmissionResponse{
+				Result: &meta.Status{
+					Status:  meta.StatusFailure,
+					Message: err.Error(),
+					Reason:  meta.StatusReasonBadRequest,
+					Code:    http.StatusBadRequest,
+				},
+			}
+		}
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(review); err != nil {
+			logrus.Errorf("Failed to encode admission response: %v", err)
+		}
+	}
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func patchResponse(patch []patchOperation) (*admissionv1.AdmissionResponse, error) {
+	response := &admissionv1.AdmissionResponse{
+		Allowed: true,
+	}
+	if len(patch) == 0 {
+		return response, nil
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	response.Patch = patchBytes
+	response.PatchType = &patchType
+	return response, nil
+}
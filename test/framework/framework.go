@@ -96,6 +96,7 @@ func getCRDs() ([]crd.CRD, error) {
 	for _, crdFn := range []func() (*crd.CRD, error){
 		ChartCRD,
 		ConfigCRD,
+		SummaryCRD,
 	} {
 		crdef, err := crdFn()
 		if err != nil {
@@ -135,6 +136,21 @@ func ConfigCRD() (*crd.CRD, error) {
 	}, nil
 }
 
+func SummaryCRD() (*crd.CRD, error) {
+	prototype := helmapiv1.NewHelmChartSummary("", "", helmapiv1.HelmChartSummary{})
+	schema, err := openapi.ToOpenAPIFromStruct(*prototype)
+	if err != nil {
+		return nil, err
+	}
+	return &crd.CRD{
+		GVK:          prototype.GroupVersionKind(),
+		PluralName:   helmapiv1.HelmChartSummaryResourceName,
+		NonNamespace: true,
+		Status:       true,
+		Schema:       schema,
+	}, nil
+}
+
 func (f *Framework) NewHelmChart(name, chart, version, helmVersion string, set map[string]intstr.IntOrString) *helmapiv1.HelmChart {
 	return &helmapiv1.HelmChart{
 		ObjectMeta: metav1.ObjectMeta{